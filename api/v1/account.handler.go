@@ -1,6 +1,9 @@
 package v1
 
 import (
+	"database/sql"
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
@@ -21,9 +24,10 @@ import (
 // @Router /accounts/create [post]
 func CreateAccount(c *fiber.Ctx) error {
 	type CreateAccountInput struct {
-		Name    string  `json:"name"`
-		Type    string  `json:"type"`
-		Balance float64 `json:"balance"`
+		Name     string       `json:"name"`
+		Type     string       `json:"type"`
+		Balance  models.Money `json:"balance"`
+		Currency string       `json:"currency"`
 	}
 
 	var input CreateAccountInput
@@ -39,8 +43,11 @@ func CreateAccount(c *fiber.Ctx) error {
 
 	db := database.DB
 
-	account, err := services.CreateAccount(userID, input.Name, models.AccountType(input.Type), input.Balance, db)
+	account, err := services.CreateAccount(userID, input.Name, models.AccountType(input.Type), input.Balance, models.NormalizeCurrency(input.Currency), db)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidCurrency) {
+			return utils.BadResponse(c, err, err.Error())
+		}
 		return utils.InternalServerError(c, err, "Failed to create account")
 	}
 
@@ -100,10 +107,18 @@ func UpdateAccount(c *fiber.Ctx) error {
 		return utils.BadResponse(c, err, "Invalid account ID")
 	}
 
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
 	db := database.DB
 
-	account, err := services.UpdateAccount(accountID, input.Name, models.AccountType(input.Type), input.IsActive, db)
+	account, err := services.UpdateAccount(accountID, userID, input.Name, models.AccountType(input.Type), input.IsActive, db)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.NotFound(c, err, "Account not found")
+		}
 		return utils.InternalServerError(c, err, "Failed to update account")
 	}
 
@@ -125,9 +140,17 @@ func DeleteAccount(c *fiber.Ctx) error {
 		return utils.BadResponse(c, err, "Invalid account ID")
 	}
 
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
 	db := database.DB
 
-	if err := services.DeleteAccount(accountID, db); err != nil {
+	if err := services.DeleteAccount(accountID, userID, db); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.NotFound(c, err, "Account not found")
+		}
 		return utils.InternalServerError(c, err, "Failed to delete account")
 	}
 
@@ -152,6 +175,9 @@ func GetTotalBalance(c *fiber.Ctx) error {
 
 	totalBalance, err := services.GetTotalBalance(userID, db)
 	if err != nil {
+		if errors.Is(err, services.ErrMissingExchangeRate) || errors.Is(err, models.ErrMoneyOverflow) {
+			return utils.BadResponse(c, err, err.Error())
+		}
 		return utils.InternalServerError(c, err, "Failed to get total balance")
 	}
 
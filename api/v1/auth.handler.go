@@ -1,76 +1,174 @@
 package v1
 
 import (
-	"context"
-	"encoding/json"
-	"io/ioutil"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/services"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
 )
 
+// authResponse is the body returned whenever a session is started.
+func authResponse(user *models.User, tokens *models.AuthTokens) fiber.Map {
+	return fiber.Map{
+		"user":                  user,
+		"sessionId":             tokens.SessionID,
+		"token":                 tokens.AccessToken,
+		"tokenExpiresAt":        tokens.AccessTokenExpiresAt,
+		"refreshToken":          tokens.RefreshToken,
+		"refreshTokenExpiresAt": tokens.RefreshTokenExpiresAt,
+	}
+}
+
+// loginResponse answers a login: with the session, or with the challenge token when the user has
+// to enter a two-factor code first.
+func loginResponse(c *fiber.Ctx, result *models.LoginResult) error {
+	if result.Tokens == nil {
+		return utils.OKResponse(c, "Two-factor authentication required", fiber.Map{
+			"twoFactorRequired":  true,
+			"challengeToken":     result.ChallengeToken,
+			"challengeExpiresAt": result.ChallengeExpiresAt,
+		})
+	}
+
+	return utils.OKResponse(c, "Login successful", authResponse(result.User, result.Tokens))
+}
+
+// tooManyAttempts answers a login that is held back after failed attempts with 429 and the
+// number of seconds to wait in Retry-After.
+func tooManyAttempts(c *fiber.Ctx, err *services.TooManyAttemptsError) error {
+	seconds := int(math.Ceil(err.RetryAfter.Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	return utils.TooManyRequests(c, fmt.Sprintf("Too many failed login attempts, try again in %d seconds", seconds))
+}
+
+// oauthError maps errors from the Google OAuth flow to a response.
+func oauthError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, utils.ErrInvalidOAuthState), errors.Is(err, services.ErrIncompleteProfile):
+		return utils.BadResponse(c, err, err.Error())
+	case errors.Is(err, services.ErrEmailNotVerified):
+		return utils.Forbidden(c, err, err.Error())
+	case errors.Is(err, services.ErrAccountExists), errors.Is(err, services.ErrIdentityInUse), errors.Is(err, services.ErrIdentityAlreadyLinked):
+		return utils.Conflict(c, err, err.Error())
+	}
+	return utils.InternalServerError(c, err, message)
+}
+
+const (
+	oauthStateCookie     = "oauth_state"
+	oauthStateCookiePath = "/api/v1/auth/google"
+)
+
+// setOAuthStateCookie hands the browser the binding of the OAuth flow it starts. The callback only
+// accepts a state whose binding comes back in this cookie.
+func setOAuthStateCookie(c *fiber.Ctx, redirect *models.OAuthRedirect) {
+	c.Cookie(&fiber.Cookie{
+		Name:     oauthStateCookie,
+		Value:    redirect.Binding,
+		Path:     oauthStateCookiePath,
+		Expires:  redirect.ExpiresAt,
+		Secure:   true,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
+
+// clearOAuthStateCookie removes the binding once the callback has used it.
+func clearOAuthStateCookie(c *fiber.Ctx) {
+	c.Cookie(&fiber.Cookie{
+		Name:     oauthStateCookie,
+		Path:     oauthStateCookiePath,
+		Expires:  time.Unix(0, 0),
+		Secure:   true,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
+
 // GoogleLogin godoc
 // @Summary Initiate Google OAuth login
-// @Description Redirects the user to the Google login page to initiate the OAuth 2.0 flow.
+// @Description Redirects the user to the Google login page to initiate the OAuth 2.0 flow. The redirect carries a signed, single-use state that expires after ten minutes and a PKCE code challenge, and sets the HttpOnly oauth_state cookie that binds the state to this browser.
 // @Tags auth
 // @Success 302 {string} string "Redirects to Google login page"
 // @Router /auth/google/login [get]
 func GoogleLogin(c *fiber.Ctx) error {
+	db := database.DB
 	cfg := c.Locals("cfg").(*config.Config)
-	url := cfg.GoogleOauthConfig.AuthCodeURL("state")
-	return c.Redirect(url)
+
+	redirect, err := services.StartGoogleOAuth(models.OAuthPurposeLogin, uuid.NullUUID{}, db, cfg)
+	if err != nil {
+		return utils.InternalServerError(c, err, "Failed to start Google login")
+	}
+
+	setOAuthStateCookie(c, redirect)
+
+	return c.Redirect(redirect.URL)
 }
 
 // GoogleCallback godoc
 // @Summary Callback for Google OAuth login
-// @Description Handles the callback from Google after the user has authenticated. It exchanges the authorization code for an access token, fetches the user's profile information, and then either creates a new user or logs in an existing user. Finally, it generates a JWT and returns it to the user.
+// @Description Handles the callback from Google after the user has authenticated. It verifies the state and that the oauth_state cookie of the browser that started the flow matches it, exchanges the authorization code with the PKCE verifier and fetches the user's profile, which must have a verified email. A login flow starts a session for the account linked to the Google account, registering a new one if the email is unknown. A linking flow links the Google account to the user that started it.
 // @Tags auth
 // @Param code query string true "Authorization code"
+// @Param state query string true "State returned by Google"
 // @Success 200 {object} map[string]interface{} "Returns a JWT token"
+// @Success 201 {object} map[string]interface{} "Google account linked successfully"
 // @Router /auth/google/callback [get]
 func GoogleCallback(c *fiber.Ctx) error {
-	code := c.Query("code")
+	if reason := c.Query("error"); reason != "" {
+		return utils.BadResponse(c, nil, "Google login failed: "+reason)
+	}
+
+	if c.Query("code") == "" {
+		return utils.BadResponse(c, nil, "code is required")
+	}
 
 	db := database.DB
 	cfg := c.Locals("cfg").(*config.Config)
 
-	token, err := cfg.GoogleOauthConfig.Exchange(context.Background(), code)
-	if err != nil {
-		return utils.InternalServerError(c, err, "Failed to exchange token")
-	}
+	binding := c.Cookies(oauthStateCookie)
+	clearOAuthStateCookie(c)
 
-	response, err := cfg.GoogleOauthConfig.Client(context.Background(), token).Get("https://www.googleapis.com/oauth2/v2/userinfo")
+	state, err := services.ConsumeGoogleOAuthState(c.Query("state"), binding, db, cfg)
 	if err != nil {
-		return utils.InternalServerError(c, err, "Failed to get user info")
+		return oauthError(c, err, "Failed to verify state")
 	}
 
-	defer response.Body.Close()
-
-	body, err := ioutil.ReadAll(response.Body)
+	profile, err := services.ExchangeGoogleCode(c.Query("code"), state, cfg)
 	if err != nil {
-		return utils.InternalServerError(c, err, "Failed to read user info")
+		return oauthError(c, err, "Failed to get user info")
 	}
 
-	var userInfo map[string]interface{}
-	if err := json.Unmarshal(body, &userInfo); err != nil {
-		return utils.InternalServerError(c, err, "Failed to parse user info")
+	if state.Purpose == models.OAuthPurposeLink {
+		identity, err := services.LinkGoogleIdentity(state.UserID.UUID, profile, db)
+		if err != nil {
+			return oauthError(c, err, "Failed to link Google account")
+		}
+
+		return utils.OKCreatedResponse(c, "Google account linked successfully", identity)
 	}
 
-	user, jwt, err := services.GoogleLogin(userInfo["email"].(string), userInfo["name"].(string), db, cfg)
+	result, err := services.GoogleLogin(profile, c.Get(fiber.HeaderUserAgent), c.IP(), db, cfg)
 	if err != nil {
-		return utils.InternalServerError(c, err, "Failed to login with Google")
+		return oauthError(c, err, "Failed to login with Google")
 	}
 
-	return utils.OKResponse(c, "Login successful", fiber.Map{"user": user, "token": jwt})
+	return loginResponse(c, result)
 }
 
 // Register godoc
 // @Summary Register a new user
-// @Description Registers a new user with the provided full name, email, and password.
+// @Description Registers a new user with the provided full name, email, and password and emails a link to verify the address. When email verification is required no session is started until the link is followed.
 // @Tags auth
 // @Accept  json
 // @Produce  json
@@ -93,17 +191,21 @@ func Register(c *fiber.Ctx) error {
 	db := database.DB
 	cfg := c.Locals("cfg").(*config.Config)
 
-	user, token, err := services.Register(input.Name, input.Email, input.Password, db, cfg)
+	user, tokens, err := services.Register(input.Name, input.Email, input.Password, c.Get(fiber.HeaderUserAgent), c.IP(), db, cfg)
 	if err != nil {
 		return utils.InternalServerError(c, err, "Failed to create user")
 	}
 
-	return utils.OKCreatedResponse(c, "User registered successfully", fiber.Map{"user": user, "token": token})
+	if tokens == nil {
+		return utils.OKCreatedResponse(c, "User registered successfully, check your email to verify your address", fiber.Map{"user": user})
+	}
+
+	return utils.OKCreatedResponse(c, "User registered successfully", authResponse(user, tokens))
 }
 
 // Login godoc
 // @Summary Log in a user
-// @Description Logs in a user with the provided email and password and returns a JWT token.
+// @Description Logs in a user with the provided email and password and starts a new session, returning a short-lived access token and a refresh token. Users with two-factor authentication get a challenge token instead, to be completed at /auth/2fa/verify. Repeated failures for an email or IP are delayed and then locked out with 429 and Retry-After.
 // @Tags auth
 // @Accept  json
 // @Produce  json
@@ -125,17 +227,38 @@ func Login(c *fiber.Ctx) error {
 	db := database.DB
 	cfg := c.Locals("cfg").(*config.Config)
 
-	user, token, err := services.Login(input.Email, input.Password, db, cfg)
+	result, err := services.Login(input.Email, input.Password, c.Get(fiber.HeaderUserAgent), c.IP(), db, cfg)
 	if err != nil {
+		var blocked *services.TooManyAttemptsError
+		if errors.As(err, &blocked) {
+			return tooManyAttempts(c, blocked)
+		}
+		if errors.Is(err, services.ErrEmailVerificationRequired) {
+			return utils.Forbidden(c, err, err.Error())
+		}
 		return utils.UnauthorizedAccess(c, err, "Invalid credentials")
 	}
 
-	return utils.OKResponse(c, "Login successful", fiber.Map{"user": user, "token": token})
+	return loginResponse(c, result)
+}
+
+// profileResponse is the body returned for the user's profile.
+func profileResponse(user *models.User, preferences *models.UserPreferences) fiber.Map {
+	return fiber.Map{
+		"personal": fiber.Map{
+			"name":                user.Name,
+			"email":               user.Email,
+			"emailVerified":       user.IsEmailVerified(),
+			"baseCurrency":        user.BaseCurrency,
+			"deletionScheduledAt": user.DeletionScheduledAt,
+		},
+		"preferences": preferences,
+	}
 }
 
 // GetProfile godoc
 // @Summary Get the authenticated user's profile
-// @Description Gets the profile information of the authenticated user.
+// @Description Gets the profile information and preferences of the authenticated user.
 // @Tags auth
 // @Security ApiKeyAuth
 // @Produce  json
@@ -150,16 +273,74 @@ func GetProfile(c *fiber.Ctx) error {
 	db := database.DB
 
 	user, err := services.GetProfile(userID, db)
-	if err != nil {
+	if err != nil || user == nil {
 		return utils.NotFound(c, err, "User not found")
 	}
 
-	return utils.OKResponse(c, "Profile retrieved successfully", fiber.Map{"personal": fiber.Map{"name": user.Name, "email": user.Email}})
+	preferences, err := services.GetUserPreferences(userID, db)
+	if err != nil {
+		return utils.InternalServerError(c, err, "Failed to get preferences")
+	}
+
+	return utils.OKResponse(c, "Profile retrieved successfully", profileResponse(user, preferences))
+}
+
+// UpdateProfile godoc
+// @Summary Update the authenticated user's profile
+// @Description Changes the name and/or email address. Changing the email requires the current password, marks the new address unverified and emails it a verification link, while the old address is notified. Omitted fields are left unchanged.
+// @Tags auth
+// @Security ApiKeyAuth
+// @Accept  json
+// @Produce  json
+// @Param input body UpdateProfileInput true "Update Profile Input"
+// @Success 200 {object} map[string]interface{} "Profile updated successfully"
+// @Router /auth/profile [patch]
+func UpdateProfile(c *fiber.Ctx) error {
+	type UpdateProfileInput struct {
+		Name     *string `json:"name"`
+		Email    *string `json:"email"`
+		Password string  `json:"password"`
+	}
+
+	var input UpdateProfileInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+	cfg := c.Locals("cfg").(*config.Config)
+
+	user, err := services.UpdateProfile(userID, input.Name, input.Email, input.Password, db, cfg)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return utils.NotFound(c, err, "User not found")
+		case errors.Is(err, services.ErrEmailInUse):
+			return utils.Conflict(c, err, err.Error())
+		case errors.Is(err, services.ErrInvalidName), errors.Is(err, services.ErrInvalidEmail),
+			errors.Is(err, services.ErrIncorrectPassword), errors.Is(err, services.ErrPasswordNotSet):
+			return utils.BadResponse(c, err, err.Error())
+		}
+		return utils.InternalServerError(c, err, "Failed to update profile")
+	}
+
+	preferences, err := services.GetUserPreferences(userID, db)
+	if err != nil {
+		return utils.InternalServerError(c, err, "Failed to get preferences")
+	}
+
+	return utils.OKResponse(c, "Profile updated successfully", profileResponse(user, preferences))
 }
 
 // ChangePassword godoc
 // @Summary Change the authenticated user's password
-// @Description Changes the password of the authenticated user.
+// @Description Changes the password of the authenticated user and signs out all of their other sessions.
 // @Tags auth
 // @Security ApiKeyAuth
 // @Accept  json
@@ -184,11 +365,58 @@ func ChangePassword(c *fiber.Ctx) error {
 		return utils.BadResponse(c, err, "Invalid user ID")
 	}
 
+	sessionID, err := uuid.Parse(c.Locals("session_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid session ID")
+	}
+
 	db := database.DB
 
-	if err := services.ChangePassword(userID, input.CurrentPassword, input.NewPassword, db); err != nil {
+	if err := services.ChangePassword(userID, sessionID, input.CurrentPassword, input.NewPassword, db); err != nil {
 		return utils.InternalServerError(c, err, "Failed to change password")
 	}
 
 	return utils.OKResponse(c, "Password changed successfully", nil)
 }
+
+// UpdateBaseCurrency godoc
+// @Summary Change the authenticated user's base currency
+// @Description Sets the currency that totals, dashboards and reports are converted into.
+// @Tags auth
+// @Security ApiKeyAuth
+// @Accept  json
+// @Produce  json
+// @Param input body UpdateBaseCurrencyInput true "Update Base Currency Input"
+// @Success 200 {object} map[string]interface{} "Base currency updated successfully"
+// @Router /auth/base-currency [patch]
+func UpdateBaseCurrency(c *fiber.Ctx) error {
+	type UpdateBaseCurrencyInput struct {
+		Currency string `json:"currency"`
+	}
+
+	var input UpdateBaseCurrencyInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	user, err := services.UpdateBaseCurrency(userID, models.NormalizeCurrency(input.Currency), db)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCurrency) {
+			return utils.BadResponse(c, err, err.Error())
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.NotFound(c, err, "User not found")
+		}
+		return utils.InternalServerError(c, err, "Failed to update base currency")
+	}
+
+	return utils.OKResponse(c, "Base currency updated successfully", fiber.Map{"baseCurrency": user.BaseCurrency})
+}
@@ -1,13 +1,36 @@
 package v1
 
 import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/services"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
 )
 
+// budgetError maps budget validation failures to client errors.
+func budgetError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return utils.NotFound(c, err, "Budget or category not found")
+	case errors.Is(err, services.ErrInvalidBudgetPeriod),
+		errors.Is(err, services.ErrInvalidBudgetLimit),
+		errors.Is(err, services.ErrInvalidBudgetDates),
+		errors.Is(err, services.ErrInvalidBudgetCategory),
+		errors.Is(err, services.ErrInvalidAlertThreshold),
+		errors.Is(err, services.ErrMissingExchangeRate):
+		return utils.BadResponse(c, err, err.Error())
+	default:
+		return utils.InternalServerError(c, err, message)
+	}
+}
+
 // CreateBudget godoc
 // @Summary Create a new budget
 // @Description Creates a new budget for the authenticated user.
@@ -20,8 +43,14 @@ import (
 // @Router /budgets/create [post]
 func CreateBudget(c *fiber.Ctx) error {
 	type CreateBudgetInput struct {
-		Name   string  `json:"name"`
-		Amount float64 `json:"amount"`
+		Name            string       `json:"name"`
+		Limit           models.Money `json:"limit"`
+		Period          string       `json:"period"`
+		StartDate       string       `json:"startDate"`
+		EndDate         string       `json:"endDate"`
+		CategoryID      string       `json:"categoryId"`
+		Rollover        bool         `json:"rollover"`
+		AlertThresholds []int64      `json:"alertThresholds"`
 	}
 
 	var input CreateBudgetInput
@@ -35,11 +64,42 @@ func CreateBudget(c *fiber.Ctx) error {
 		return utils.BadResponse(c, err, "Invalid user ID")
 	}
 
+	period := models.BudgetPeriod(input.Period)
+	if period == "" {
+		period = models.BudgetPeriodMonthly
+	}
+
+	startDate := services.UserToday(userID, database.DB)
+	if input.StartDate != "" {
+		startDate, err = time.Parse("2006-01-02", input.StartDate)
+		if err != nil {
+			return utils.BadResponse(c, err, "Invalid start date format")
+		}
+	}
+
+	var endDate *time.Time
+	if input.EndDate != "" {
+		parsedEndDate, err := time.Parse("2006-01-02", input.EndDate)
+		if err != nil {
+			return utils.BadResponse(c, err, "Invalid end date format")
+		}
+		endDate = &parsedEndDate
+	}
+
+	var categoryID uuid.NullUUID
+	if input.CategoryID != "" {
+		parsedCategoryID, err := uuid.Parse(input.CategoryID)
+		if err != nil {
+			return utils.BadResponse(c, err, "Invalid category ID")
+		}
+		categoryID = uuid.NullUUID{UUID: parsedCategoryID, Valid: true}
+	}
+
 	db := database.DB
 
-	budget, err := services.CreateBudget(userID, input.Name, input.Amount, db)
+	budget, err := services.CreateBudget(userID, input.Name, input.Limit, period, startDate, endDate, categoryID, input.Rollover, input.AlertThresholds, db)
 	if err != nil {
-		return utils.InternalServerError(c, err, "Failed to create budget")
+		return budgetError(c, err, "Failed to create budget")
 	}
 
 	return utils.OKCreatedResponse(c, "Budget created successfully", budget)
@@ -47,7 +107,7 @@ func CreateBudget(c *fiber.Ctx) error {
 
 // GetBudgets godoc
 // @Summary Get all budgets
-// @Description Gets all budgets for the authenticated user.
+// @Description Gets all budgets for the authenticated user together with the spent, remaining and percentage used for their current period.
 // @Tags budgets
 // @Security ApiKeyAuth
 // @Produce  json
@@ -63,12 +123,78 @@ func GetBudgets(c *fiber.Ctx) error {
 
 	budgets, err := services.GetBudgets(userID, db)
 	if err != nil {
-		return utils.InternalServerError(c, err, "Failed to get budgets")
+		return budgetError(c, err, "Failed to get budgets")
 	}
 
 	return utils.OKResponse(c, "Budgets retrieved successfully", budgets)
 }
 
+// GetBudget godoc
+// @Summary Get a budget
+// @Description Gets a budget for the authenticated user with the status of its current period.
+// @Tags budgets
+// @Security ApiKeyAuth
+// @Produce  json
+// @Param id path string true "Budget ID"
+// @Success 200 {object} map[string]interface{} "Budget retrieved successfully"
+// @Router /budgets/{id} [get]
+func GetBudget(c *fiber.Ctx) error {
+	budgetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid budget ID")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	budget, err := services.GetBudget(budgetID, userID, db)
+	if err != nil {
+		return budgetError(c, err, "Failed to get budget")
+	}
+
+	return utils.OKResponse(c, "Budget retrieved successfully", budget)
+}
+
+// GetBudgetHistory godoc
+// @Summary Get the history of a budget
+// @Description Gets the spent, remaining and percentage used for past periods of a budget, newest first.
+// @Tags budgets
+// @Security ApiKeyAuth
+// @Produce  json
+// @Param id path string true "Budget ID"
+// @Param limit query int false "Number of periods to return"
+// @Success 200 {object} map[string]interface{} "Budget history retrieved successfully"
+// @Router /budgets/{id}/history [get]
+func GetBudgetHistory(c *fiber.Ctx) error {
+	budgetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid budget ID")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "12"))
+	if limit < 1 {
+		limit = 12
+	}
+
+	db := database.DB
+
+	history, err := services.GetBudgetHistory(budgetID, userID, limit, db)
+	if err != nil {
+		return budgetError(c, err, "Failed to get budget history")
+	}
+
+	return utils.OKResponse(c, "Budget history retrieved successfully", history)
+}
+
 // UpdateBudget godoc
 // @Summary Update a budget
 // @Description Updates a budget for the authenticated user.
@@ -82,8 +208,14 @@ func GetBudgets(c *fiber.Ctx) error {
 // @Router /budgets/update/{id} [patch]
 func UpdateBudget(c *fiber.Ctx) error {
 	type UpdateBudgetInput struct {
-		Name   string  `json:"name"`
-		Amount float64 `json:"amount"`
+		Name            string       `json:"name"`
+		Limit           models.Money `json:"limit"`
+		Period          string       `json:"period"`
+		StartDate       string       `json:"startDate"`
+		EndDate         string       `json:"endDate"`
+		CategoryID      string       `json:"categoryId"`
+		Rollover        bool         `json:"rollover"`
+		AlertThresholds []int64      `json:"alertThresholds"`
 	}
 
 	var input UpdateBudgetInput
@@ -97,11 +229,47 @@ func UpdateBudget(c *fiber.Ctx) error {
 		return utils.BadResponse(c, err, "Invalid budget ID")
 	}
 
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	period := models.BudgetPeriod(input.Period)
+	if period == "" {
+		period = models.BudgetPeriodMonthly
+	}
+
+	startDate := services.UserToday(userID, database.DB)
+	if input.StartDate != "" {
+		startDate, err = time.Parse("2006-01-02", input.StartDate)
+		if err != nil {
+			return utils.BadResponse(c, err, "Invalid start date format")
+		}
+	}
+
+	var endDate *time.Time
+	if input.EndDate != "" {
+		parsedEndDate, err := time.Parse("2006-01-02", input.EndDate)
+		if err != nil {
+			return utils.BadResponse(c, err, "Invalid end date format")
+		}
+		endDate = &parsedEndDate
+	}
+
+	var categoryID uuid.NullUUID
+	if input.CategoryID != "" {
+		parsedCategoryID, err := uuid.Parse(input.CategoryID)
+		if err != nil {
+			return utils.BadResponse(c, err, "Invalid category ID")
+		}
+		categoryID = uuid.NullUUID{UUID: parsedCategoryID, Valid: true}
+	}
+
 	db := database.DB
 
-	budget, err := services.UpdateBudget(budgetID, input.Name, input.Amount, db)
+	budget, err := services.UpdateBudget(budgetID, userID, input.Name, input.Limit, period, startDate, endDate, categoryID, input.Rollover, input.AlertThresholds, db)
 	if err != nil {
-		return utils.InternalServerError(c, err, "Failed to update budget")
+		return budgetError(c, err, "Failed to update budget")
 	}
 
 	return utils.OKResponse(c, "Budget updated successfully", budget)
@@ -122,9 +290,17 @@ func DeleteBudget(c *fiber.Ctx) error {
 		return utils.BadResponse(c, err, "Invalid budget ID")
 	}
 
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
 	db := database.DB
 
-	if err := services.DeleteBudget(budgetID, db); err != nil {
+	if err := services.DeleteBudget(budgetID, userID, db); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.NotFound(c, err, "Budget not found")
+		}
 		return utils.InternalServerError(c, err, "Failed to delete budget")
 	}
 
@@ -1,6 +1,9 @@
 package v1
 
 import (
+	"database/sql"
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
@@ -11,7 +14,7 @@ import (
 
 // CreateCategory godoc
 // @Summary Create a new transaction category
-// @Description Creates a new transaction category.
+// @Description Creates a new custom transaction category owned by the authenticated user.
 // @Tags categories
 // @Security ApiKeyAuth
 // @Accept  json
@@ -40,6 +43,12 @@ func CreateCategory(c *fiber.Ctx) error {
 
 	category, err := services.CreateCategory(input.Name, models.TransactionType(input.Type), userID, db)
 	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCategoryType):
+			return utils.BadResponse(c, err, "Invalid category type")
+		case errors.Is(err, services.ErrCategoryExists):
+			return utils.Conflict(c, err, "Category already exists")
+		}
 		return utils.InternalServerError(c, err, "Failed to create category")
 	}
 
@@ -48,15 +57,20 @@ func CreateCategory(c *fiber.Ctx) error {
 
 // GetCategories godoc
 // @Summary Get all transaction categories
-// @Description Gets all transaction categories.
+// @Description Gets the shared system categories merged with the authenticated user's custom categories.
 // @Tags categories
 // @Security ApiKeyAuth
 // @Produce  json
 // @Success 200 {object} map[string]interface{} "Categories retrieved successfully"
 // @Router /categories [get]
 func GetCategories(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
 	db := database.DB
-	categories, err := services.GetCategories(db)
+	categories, err := services.GetCategories(userID, db)
 	if err != nil {
 		return utils.InternalServerError(c, err, "Failed to get categories")
 	}
@@ -66,7 +80,7 @@ func GetCategories(c *fiber.Ctx) error {
 
 // UpdateCategory godoc
 // @Summary Update a transaction category
-// @Description Updates a transaction category.
+// @Description Updates a custom transaction category owned by the authenticated user. System categories are read-only.
 // @Tags categories
 // @Security ApiKeyAuth
 // @Accept  json
@@ -101,6 +115,16 @@ func UpdateCategory(c *fiber.Ctx) error {
 
 	category, err := services.UpdateCategory(categoryID, input.Name, models.TransactionType(input.Type), userID, db)
 	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return utils.NotFound(c, err, "Category not found")
+		case errors.Is(err, services.ErrSystemCategory):
+			return utils.Forbidden(c, err, "System categories cannot be modified")
+		case errors.Is(err, services.ErrInvalidCategoryType):
+			return utils.BadResponse(c, err, "Invalid category type")
+		case errors.Is(err, services.ErrCategoryExists):
+			return utils.Conflict(c, err, "Category already exists")
+		}
 		return utils.InternalServerError(c, err, "Failed to update category")
 	}
 
@@ -109,7 +133,7 @@ func UpdateCategory(c *fiber.Ctx) error {
 
 // DeleteCategory godoc
 // @Summary Delete a transaction category
-// @Description Deletes a transaction category.
+// @Description Deletes a custom transaction category owned by the authenticated user. System categories are read-only.
 // @Tags categories
 // @Security ApiKeyAuth
 // @Produce  json
@@ -130,6 +154,12 @@ func DeleteCategory(c *fiber.Ctx) error {
 	db := database.DB
 
 	if err := services.DeleteCategory(categoryID, userID, db); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return utils.NotFound(c, err, "Category not found")
+		case errors.Is(err, services.ErrSystemCategory):
+			return utils.Forbidden(c, err, "System categories cannot be deleted")
+		}
 		return utils.InternalServerError(c, err, "Failed to delete category")
 	}
 
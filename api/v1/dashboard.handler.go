@@ -1,18 +1,20 @@
 package v1
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/services"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
 )
 
 // GetDashboardSummary godoc
 // @Summary Get a summary of the user's financial data for the dashboard
-// @Description Gets a summary of the user's financial data for the dashboard, including total balance, current month's income/expenses/savings, recent transactions, income vs. expense data for the last 12 months, and monthly spending by category.
+// @Description Gets a summary of the user's financial data for the dashboard, including total balance, current month's income/expenses/savings, recent transactions, monthly income vs. expense for the last 12 months in the user's time zone, and spending by category.
 // @Tags dashboard
 // @Security ApiKeyAuth
 // @Produce  json
@@ -37,6 +39,9 @@ func GetDashboardSummary(c *fiber.Ctx) error {
 
 	summary, err := services.GetDashboardSummary(userID, page, limit, description, categoryID, accountID, budgetID, startDate, endDate, db)
 	if err != nil {
+		if errors.Is(err, services.ErrMissingExchangeRate) || errors.Is(err, services.ErrInvalidDateFilter) || errors.Is(err, models.ErrMoneyOverflow) {
+			return utils.BadResponse(c, err, err.Error())
+		}
 		return utils.InternalServerError(c, err, "Failed to get dashboard summary")
 	}
 
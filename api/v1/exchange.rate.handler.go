@@ -0,0 +1,120 @@
+package v1
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/services"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+// GetExchangeRates godoc
+// @Summary Get exchange rates
+// @Description Gets the stored exchange rates, newest first, optionally filtered by currency pair.
+// @Tags exchange-rates
+// @Security ApiKeyAuth
+// @Produce  json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param base query string false "Filter by base currency"
+// @Param quote query string false "Filter by quote currency"
+// @Success 200 {object} map[string]interface{} "Exchange rates retrieved successfully"
+// @Router /exchange-rates [get]
+func GetExchangeRates(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	db := database.DB
+
+	rates, err := services.GetExchangeRates(c.Query("base"), c.Query("quote"), page, limit, db)
+	if err != nil {
+		return utils.InternalServerError(c, err, "Failed to get exchange rates")
+	}
+
+	return utils.OKResponse(c, "Exchange rates retrieved successfully", rates)
+}
+
+// CreateExchangeRate godoc
+// @Summary Create or replace an exchange rate
+// @Description Stores the rate converting one unit of the base currency into the quote currency on a given date. Requires the X-Admin-Key header.
+// @Tags exchange-rates
+// @Accept  json
+// @Produce  json
+// @Param input body CreateExchangeRateInput true "Create Exchange Rate Input"
+// @Success 201 {object} map[string]interface{} "Exchange rate saved successfully"
+// @Router /exchange-rates/create [post]
+func CreateExchangeRate(c *fiber.Ctx) error {
+	type CreateExchangeRateInput struct {
+		BaseCurrency  string      `json:"baseCurrency"`
+		QuoteCurrency string      `json:"quoteCurrency"`
+		Rate          models.Rate `json:"rate"`
+		Date          string      `json:"date"`
+	}
+
+	var input CreateExchangeRateInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	rateDate, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid date format")
+	}
+
+	db := database.DB
+
+	rate, err := services.CreateExchangeRate(models.NormalizeCurrency(input.BaseCurrency), models.NormalizeCurrency(input.QuoteCurrency), input.Rate, rateDate, db)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCurrency) || errors.Is(err, models.ErrInvalidRate) {
+			return utils.BadResponse(c, err, err.Error())
+		}
+		return utils.InternalServerError(c, err, "Failed to save exchange rate")
+	}
+
+	return utils.OKCreatedResponse(c, "Exchange rate saved successfully", rate)
+}
+
+// ImportExchangeRates godoc
+// @Summary Import exchange rates from CSV
+// @Description Imports a CSV file with the columns date, base, quote and rate. Existing rates for the same pair and date are replaced. Requires the X-Admin-Key header.
+// @Tags exchange-rates
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param file formData file true "CSV file"
+// @Success 200 {object} map[string]interface{} "Exchange rates imported successfully"
+// @Router /exchange-rates/import [post]
+func ImportExchangeRates(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.BadResponse(c, err, "A CSV file is required")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.BadResponse(c, err, "Failed to read the uploaded file")
+	}
+	defer file.Close()
+
+	db := database.DB
+
+	imported, err := services.ImportExchangeRates(file, db)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRatesFile) || errors.Is(err, services.ErrInvalidCurrency) || errors.Is(err, models.ErrInvalidRate) {
+			return utils.BadResponse(c, err, err.Error())
+		}
+		return utils.InternalServerError(c, err, "Failed to import exchange rates")
+	}
+
+	return utils.OKResponse(c, "Exchange rates imported successfully", fiber.Map{"imported": imported})
+}
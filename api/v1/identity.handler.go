@@ -0,0 +1,94 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/services"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+// GoogleLink godoc
+// @Summary Start linking a Google account
+// @Description Returns the Google authorization URL that links the Google account the user signs in with to the authenticated user, and sets the HttpOnly oauth_state cookie the callback requires. The URL only works in the browser that received the cookie, so request it with credentials and open it in the same browser. The flow finishes at the Google callback.
+// @Tags auth
+// @Security ApiKeyAuth
+// @Produce  json
+// @Success 200 {object} map[string]interface{} "Google link URL created successfully"
+// @Router /auth/google/link [get]
+func GoogleLink(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+	cfg := c.Locals("cfg").(*config.Config)
+
+	redirect, err := services.StartGoogleOAuth(models.OAuthPurposeLink, uuid.NullUUID{UUID: userID, Valid: true}, db, cfg)
+	if err != nil {
+		return utils.InternalServerError(c, err, "Failed to start Google linking")
+	}
+
+	setOAuthStateCookie(c, redirect)
+
+	return utils.OKResponse(c, "Google link URL created successfully", fiber.Map{"url": redirect.URL})
+}
+
+// GetIdentities godoc
+// @Summary List linked identities
+// @Description Lists the external accounts, such as Google, linked to the authenticated user.
+// @Tags auth
+// @Security ApiKeyAuth
+// @Produce  json
+// @Success 200 {object} map[string]interface{} "Identities retrieved successfully"
+// @Router /auth/identities [get]
+func GetIdentities(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	identities, err := services.GetUserIdentities(userID, db)
+	if err != nil {
+		return utils.InternalServerError(c, err, "Failed to get identities")
+	}
+
+	return utils.OKResponse(c, "Identities retrieved successfully", identities)
+}
+
+// UnlinkGoogle godoc
+// @Summary Unlink the Google account
+// @Description Removes the Google account linked to the authenticated user. Accounts without a password cannot unlink their only login method.
+// @Tags auth
+// @Security ApiKeyAuth
+// @Produce  json
+// @Success 200 {object} map[string]interface{} "Google account unlinked successfully"
+// @Router /auth/identities/google [delete]
+func UnlinkGoogle(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	if err := services.UnlinkGoogleIdentity(userID, db); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.NotFound(c, err, "No Google account is linked")
+		}
+		if errors.Is(err, services.ErrLastLoginMethod) {
+			return utils.BadResponse(c, err, err.Error())
+		}
+		return utils.InternalServerError(c, err, "Failed to unlink Google account")
+	}
+
+	return utils.OKResponse(c, "Google account unlinked successfully", nil)
+}
@@ -1,8 +1,8 @@
 package v1
 
 import (
+	"errors"
 	"strconv"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -13,14 +13,14 @@ import (
 
 // GetLogs godoc
 // @Summary Get user activity logs
-// @Description Retrieves a paginated list of activity logs for the authenticated user within a specified date range.
+// @Description Retrieves a paginated list of activity logs for the authenticated user within a specified date range. Dates are days in the user's time zone and default to the last month.
 // @Tags logs
 // @Security ApiKeyAuth
 // @Produce json
 // @Param page query int false "Page number for pagination" default(1)
 // @Param limit query int false "Number of items per page" default(10)
-// @Param start_date query string false "Start date for filtering logs (YYYY-MM-DD)"
-// @Param end_date query string false "End date for filtering logs (YYYY-MM-DD)"
+// @Param start_date query string false "Start date for filtering logs (YYYY-MM-DD or RFC 3339)"
+// @Param end_date query string false "End date for filtering logs (YYYY-MM-DD or RFC 3339)"
 // @Success 200 {object} map[string]interface{} "Activity logs retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid request or user ID"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
@@ -33,13 +33,16 @@ func GetLogs(c *fiber.Ctx) error {
 
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
-	startDateStr := c.Query("start_date", time.Now().AddDate(0, -1, 0).Format("2006-01-02"))
-	endDateStr := c.Query("end_date", time.Now().Format("2006-01-02"))
+	startDateStr := c.Query("start_date")
+	endDateStr := c.Query("end_date")
 
 	db := database.DB
 
 	logs, err := services.GetLogs(userID, startDateStr, endDateStr, page, limit, db)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidDateFilter) {
+			return utils.BadResponse(c, err, err.Error())
+		}
 		return utils.InternalServerError(c, err, "Failed to retrieve logs")
 	}
 
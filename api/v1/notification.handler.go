@@ -0,0 +1,106 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/services"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+// GetNotifications godoc
+// @Summary Get notifications
+// @Description Gets the authenticated user's notifications, newest first, together with the number of unread ones.
+// @Tags notifications
+// @Security ApiKeyAuth
+// @Produce  json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param unread query bool false "Only return unread notifications"
+// @Success 200 {object} map[string]interface{} "Notifications retrieved successfully"
+// @Router /notifications [get]
+func GetNotifications(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	unreadOnly, _ := strconv.ParseBool(c.Query("unread", "false"))
+
+	db := database.DB
+
+	notifications, err := services.GetNotifications(userID, unreadOnly, page, limit, db)
+	if err != nil {
+		return utils.InternalServerError(c, err, "Failed to get notifications")
+	}
+
+	return utils.OKResponse(c, "Notifications retrieved successfully", notifications)
+}
+
+// MarkNotificationRead godoc
+// @Summary Mark a notification as read
+// @Description Marks one of the authenticated user's notifications as read.
+// @Tags notifications
+// @Security ApiKeyAuth
+// @Produce  json
+// @Param id path string true "Notification ID"
+// @Success 200 {object} map[string]interface{} "Notification marked as read"
+// @Router /notifications/read/{id} [patch]
+func MarkNotificationRead(c *fiber.Ctx) error {
+	notificationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid notification ID")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	if err := services.MarkNotificationRead(notificationID, userID, db); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.NotFound(c, err, "Notification not found")
+		}
+		return utils.InternalServerError(c, err, "Failed to mark notification as read")
+	}
+
+	return utils.OKResponse(c, "Notification marked as read", nil)
+}
+
+// MarkAllNotificationsRead godoc
+// @Summary Mark all notifications as read
+// @Description Marks every unread notification of the authenticated user as read.
+// @Tags notifications
+// @Security ApiKeyAuth
+// @Produce  json
+// @Success 200 {object} map[string]interface{} "Notifications marked as read"
+// @Router /notifications/read-all [patch]
+func MarkAllNotificationsRead(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	updated, err := services.MarkAllNotificationsRead(userID, db)
+	if err != nil {
+		return utils.InternalServerError(c, err, "Failed to mark notifications as read")
+	}
+
+	return utils.OKResponse(c, "Notifications marked as read", fiber.Map{"updated": updated})
+}
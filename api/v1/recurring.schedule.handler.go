@@ -0,0 +1,242 @@
+package v1
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/services"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+// PauseRecurringTransaction godoc
+// @Summary Pause a recurring transaction
+// @Description Stops a recurring transaction from posting until it is resumed.
+// @Tags recurring-transactions
+// @Security ApiKeyAuth
+// @Produce  json
+// @Param id path string true "Recurring Transaction ID"
+// @Success 200 {object} map[string]interface{} "Recurring transaction paused successfully"
+// @Router /recurring-transactions/pause/{id} [patch]
+func PauseRecurringTransaction(c *fiber.Ctx) error {
+	recurringTransactionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid recurring transaction ID")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	recurringTransaction, err := services.PauseRecurringTransaction(recurringTransactionID, userID, db)
+	if err != nil {
+		return recurringTransactionError(c, err, "Recurring transaction not found", "Failed to pause recurring transaction")
+	}
+
+	return utils.OKResponse(c, "Recurring transaction paused successfully", recurringTransaction)
+}
+
+// ResumeRecurringTransaction godoc
+// @Summary Resume a recurring transaction
+// @Description Resumes a paused recurring transaction from today. Occurrences missed while paused are not posted.
+// @Tags recurring-transactions
+// @Security ApiKeyAuth
+// @Produce  json
+// @Param id path string true "Recurring Transaction ID"
+// @Success 200 {object} map[string]interface{} "Recurring transaction resumed successfully"
+// @Router /recurring-transactions/resume/{id} [patch]
+func ResumeRecurringTransaction(c *fiber.Ctx) error {
+	recurringTransactionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid recurring transaction ID")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	recurringTransaction, err := services.ResumeRecurringTransaction(recurringTransactionID, userID, db)
+	if err != nil {
+		return recurringTransactionError(c, err, "Recurring transaction not found", "Failed to resume recurring transaction")
+	}
+
+	return utils.OKResponse(c, "Recurring transaction resumed successfully", recurringTransaction)
+}
+
+// PreviewRecurringTransaction godoc
+// @Summary Preview upcoming occurrences
+// @Description Lists the next occurrences of a recurring transaction with their dates and amounts, including skipped and overridden ones.
+// @Tags recurring-transactions
+// @Security ApiKeyAuth
+// @Produce  json
+// @Param id path string true "Recurring Transaction ID"
+// @Param count query int false "Number of occurrences to return (max 100)"
+// @Success 200 {object} map[string]interface{} "Recurring transaction preview retrieved successfully"
+// @Router /recurring-transactions/{id}/preview [get]
+func PreviewRecurringTransaction(c *fiber.Ctx) error {
+	recurringTransactionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid recurring transaction ID")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	count, _ := strconv.Atoi(c.Query("count", "5"))
+	if count < 1 {
+		count = 5
+	}
+	if count > 100 {
+		count = 100
+	}
+
+	db := database.DB
+
+	preview, err := services.PreviewRecurringTransaction(recurringTransactionID, userID, count, db)
+	if err != nil {
+		return recurringTransactionError(c, err, "Recurring transaction not found", "Failed to preview recurring transaction")
+	}
+
+	return utils.OKResponse(c, "Recurring transaction preview retrieved successfully", preview)
+}
+
+// SkipRecurringOccurrence godoc
+// @Summary Skip an upcoming occurrence
+// @Description Stops a single upcoming occurrence of a recurring transaction from being posted.
+// @Tags recurring-transactions
+// @Security ApiKeyAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Recurring Transaction ID"
+// @Param input body SkipRecurringOccurrenceInput true "Skip Recurring Occurrence Input"
+// @Success 200 {object} map[string]interface{} "Occurrence skipped successfully"
+// @Router /recurring-transactions/{id}/skip [post]
+func SkipRecurringOccurrence(c *fiber.Ctx) error {
+	type SkipRecurringOccurrenceInput struct {
+		Date string `json:"date"`
+	}
+
+	var input SkipRecurringOccurrenceInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	recurringTransactionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid recurring transaction ID")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	occurrenceDate, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid date format")
+	}
+
+	db := database.DB
+
+	exception, err := services.SkipRecurringOccurrence(recurringTransactionID, userID, occurrenceDate, db)
+	if err != nil {
+		return recurringTransactionError(c, err, "Recurring transaction not found", "Failed to skip occurrence")
+	}
+
+	return utils.OKResponse(c, "Occurrence skipped successfully", exception)
+}
+
+// OverrideRecurringOccurrence godoc
+// @Summary Override the amount of an upcoming occurrence
+// @Description Posts a single upcoming occurrence of a recurring transaction with a different amount.
+// @Tags recurring-transactions
+// @Security ApiKeyAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Recurring Transaction ID"
+// @Param input body OverrideRecurringOccurrenceInput true "Override Recurring Occurrence Input"
+// @Success 200 {object} map[string]interface{} "Occurrence amount overridden successfully"
+// @Router /recurring-transactions/{id}/override [post]
+func OverrideRecurringOccurrence(c *fiber.Ctx) error {
+	type OverrideRecurringOccurrenceInput struct {
+		Date   string       `json:"date"`
+		Amount models.Money `json:"amount"`
+	}
+
+	var input OverrideRecurringOccurrenceInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	recurringTransactionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid recurring transaction ID")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	occurrenceDate, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid date format")
+	}
+
+	db := database.DB
+
+	exception, err := services.OverrideRecurringOccurrence(recurringTransactionID, userID, occurrenceDate, input.Amount, db)
+	if err != nil {
+		return recurringTransactionError(c, err, "Recurring transaction not found", "Failed to override occurrence")
+	}
+
+	return utils.OKResponse(c, "Occurrence amount overridden successfully", exception)
+}
+
+// RestoreRecurringOccurrence godoc
+// @Summary Restore an upcoming occurrence
+// @Description Removes the skip or amount override of a single upcoming occurrence.
+// @Tags recurring-transactions
+// @Security ApiKeyAuth
+// @Produce  json
+// @Param id path string true "Recurring Transaction ID"
+// @Param date path string true "Occurrence date (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{} "Occurrence restored successfully"
+// @Router /recurring-transactions/{id}/exceptions/{date} [delete]
+func RestoreRecurringOccurrence(c *fiber.Ctx) error {
+	recurringTransactionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid recurring transaction ID")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	occurrenceDate, err := time.Parse("2006-01-02", c.Params("date"))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid date format")
+	}
+
+	db := database.DB
+
+	if err := services.RestoreRecurringOccurrence(recurringTransactionID, userID, occurrenceDate, db); err != nil {
+		return recurringTransactionError(c, err, "Recurring transaction not found", "Failed to restore occurrence")
+	}
+
+	return utils.OKResponse(c, "Occurrence restored successfully", nil)
+}
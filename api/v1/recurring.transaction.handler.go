@@ -2,15 +2,79 @@ package v1
 
 import (
 	"database/sql"
+	"errors"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/pkg/recurrence"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/services"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
 )
 
+// recurrenceRule builds a recurrence rule from request fields. The interval defaults to 1 and the
+// start date to today, the current date in the user's time zone.
+func recurrenceRule(frequency models.RecurringFrequency, interval int, weekday *int, dayOfMonth int, lastDayOfMonth bool, startDate string, endDate string, maxOccurrences *int, today time.Time) (recurrence.Rule, error) {
+	rule := recurrence.Rule{
+		Frequency:      recurrence.Frequency(frequency),
+		Interval:       interval,
+		DayOfMonth:     dayOfMonth,
+		LastDayOfMonth: lastDayOfMonth,
+		StartDate:      today,
+		MaxOccurrences: maxOccurrences,
+	}
+
+	if rule.Interval == 0 {
+		rule.Interval = 1
+	}
+
+	if weekday != nil {
+		day := time.Weekday(*weekday)
+		rule.Weekday = &day
+	}
+
+	if startDate != "" {
+		start, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			return rule, err
+		}
+		rule.StartDate = start
+	}
+
+	if endDate != "" {
+		end, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			return rule, err
+		}
+		rule.EndDate = &end
+	}
+
+	return rule, nil
+}
+
+// recurringTransactionError maps recurrence rule and occurrence validation failures to client errors.
+func recurringTransactionError(c *fiber.Ctx, err error, notFound string, message string) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return utils.NotFound(c, err, notFound)
+	case errors.Is(err, recurrence.ErrInvalidFrequency),
+		errors.Is(err, recurrence.ErrInvalidInterval),
+		errors.Is(err, recurrence.ErrInvalidWeekday),
+		errors.Is(err, recurrence.ErrInvalidDayOfMonth),
+		errors.Is(err, recurrence.ErrInvalidEndDate),
+		errors.Is(err, recurrence.ErrInvalidMaxOccurrences),
+		errors.Is(err, services.ErrNotScheduledOccurrence),
+		errors.Is(err, services.ErrOccurrenceAlreadyProcessed),
+		errors.Is(err, services.ErrInvalidRecurringAmount),
+		errors.Is(err, services.ErrExceptionNotFound):
+		return utils.BadResponse(c, err, err.Error())
+	default:
+		return utils.InternalServerError(c, err, message)
+	}
+}
+
 // CreateRecurringTransaction godoc
 // @Summary Create a new recurring transaction
 // @Description Creates a new recurring transaction for the authenticated user.
@@ -27,10 +91,16 @@ func CreateRecurringTransaction(c *fiber.Ctx) error {
 		CategoryID         string                    `json:"categoryId"`
 		BudgetID           string                    `json:"budgetId"`
 		Description        string                    `json:"description"`
-		Amount             float64                   `json:"amount"`
+		Amount             models.Money              `json:"amount"`
 		Note               string                    `json:"note"`
 		RecurringFrequency models.RecurringFrequency `json:"recurringFrequency"`
 		RecurringDate      int                       `json:"recurringDate"`
+		Interval           int                       `json:"interval"`
+		Weekday            *int                      `json:"weekday"`
+		LastDayOfMonth     bool                      `json:"lastDayOfMonth"`
+		StartDate          string                    `json:"startDate"`
+		EndDate            string                    `json:"endDate"`
+		MaxOccurrences     *int                      `json:"maxOccurrences"`
 	}
 
 	var input CreateRecurringTransactionInput
@@ -63,11 +133,16 @@ func CreateRecurringTransaction(c *fiber.Ctx) error {
 		budgetID = uuid.NullUUID{UUID: parsedBudgetId, Valid: true}
 	}
 
+	rule, err := recurrenceRule(input.RecurringFrequency, input.Interval, input.Weekday, input.RecurringDate, input.LastDayOfMonth, input.StartDate, input.EndDate, input.MaxOccurrences, services.UserToday(userID, database.DB))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid date format")
+	}
+
 	db := database.DB
 
-	recurringTransaction, err := services.CreateRecurringTransaction(userID, accountID, categoryID, budgetID, input.Description, input.Amount, sql.NullString{String: input.Note, Valid: input.Note != ""}, input.RecurringFrequency, input.RecurringDate, db)
+	recurringTransaction, err := services.CreateRecurringTransaction(userID, accountID, categoryID, budgetID, input.Description, input.Amount, sql.NullString{String: input.Note, Valid: input.Note != ""}, rule, db)
 	if err != nil {
-		return utils.InternalServerError(c, err, "Failed to create recurring transaction")
+		return recurringTransactionError(c, err, "Account, category or budget not found", "Failed to create recurring transaction")
 	}
 
 	return utils.OKCreatedResponse(c, "Recurring transaction created successfully", recurringTransaction)
@@ -75,7 +150,7 @@ func CreateRecurringTransaction(c *fiber.Ctx) error {
 
 // GetRecurringTransactions godoc
 // @Summary Get all recurring transactions
-// @Description Gets all recurring transactions for the authenticated user.
+// @Description Gets all recurring transactions for the authenticated user, each with the computed date of its next run.
 // @Tags recurring-transactions
 // @Security ApiKeyAuth
 // @Produce  json
@@ -114,10 +189,16 @@ func UpdateRecurringTransaction(c *fiber.Ctx) error {
 		CategoryID         string                    `json:"categoryId"`
 		BudgetID           string                    `json:"budgetId"`
 		Description        string                    `json:"description"`
-		Amount             float64                   `json:"amount"`
+		Amount             models.Money              `json:"amount"`
 		Note               string                    `json:"note"`
 		RecurringFrequency models.RecurringFrequency `json:"recurringFrequency"`
 		RecurringDate      int                       `json:"recurringDate"`
+		Interval           int                       `json:"interval"`
+		Weekday            *int                      `json:"weekday"`
+		LastDayOfMonth     bool                      `json:"lastDayOfMonth"`
+		StartDate          string                    `json:"startDate"`
+		EndDate            string                    `json:"endDate"`
+		MaxOccurrences     *int                      `json:"maxOccurrences"`
 	}
 
 	var input UpdateRecurringTransactionInput
@@ -131,6 +212,11 @@ func UpdateRecurringTransaction(c *fiber.Ctx) error {
 		return utils.BadResponse(c, err, "Invalid recurring transaction ID")
 	}
 
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
 	accountID, err := uuid.Parse(input.AccountID)
 	if err != nil {
 		return utils.BadResponse(c, err, "Invalid account ID")
@@ -150,11 +236,16 @@ func UpdateRecurringTransaction(c *fiber.Ctx) error {
 		budgetID = uuid.NullUUID{UUID: parsedBudgetId, Valid: true}
 	}
 
+	rule, err := recurrenceRule(input.RecurringFrequency, input.Interval, input.Weekday, input.RecurringDate, input.LastDayOfMonth, input.StartDate, input.EndDate, input.MaxOccurrences, services.UserToday(userID, database.DB))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid date format")
+	}
+
 	db := database.DB
 
-	recurringTransaction, err := services.UpdateRecurringTransaction(recurringTransactionID, accountID, categoryID, budgetID, input.Description, input.Amount, sql.NullString{String: input.Note, Valid: input.Note != ""}, input.RecurringFrequency, input.RecurringDate, db)
+	recurringTransaction, err := services.UpdateRecurringTransaction(recurringTransactionID, userID, accountID, categoryID, budgetID, input.Description, input.Amount, sql.NullString{String: input.Note, Valid: input.Note != ""}, rule, db)
 	if err != nil {
-		return utils.InternalServerError(c, err, "Failed to update recurring transaction")
+		return recurringTransactionError(c, err, "Recurring transaction, account, category or budget not found", "Failed to update recurring transaction")
 	}
 
 	return utils.OKResponse(c, "Recurring transaction updated successfully", recurringTransaction)
@@ -175,9 +266,17 @@ func DeleteRecurringTransaction(c *fiber.Ctx) error {
 		return utils.BadResponse(c, err, "Invalid recurring transaction ID")
 	}
 
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
 	db := database.DB
 
-	if err := services.DeleteRecurringTransaction(recurringTransactionID, db); err != nil {
+	if err := services.DeleteRecurringTransaction(recurringTransactionID, userID, db); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.NotFound(c, err, "Recurring transaction not found")
+		}
 		return utils.InternalServerError(c, err, "Failed to delete recurring transaction")
 	}
 
@@ -1,9 +1,16 @@
 package v1
 
 import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/services"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
 )
@@ -14,8 +21,8 @@ import (
 // @Tags reports
 // @Security ApiKeyAuth
 // @Produce  json
-// @Param from query string false "Start date (YYYY-MM-DD)"
-// @Param to query string false "End date (YYYY-MM-DD)"
+// @Param from query string false "Start date (YYYY-MM-DD or RFC 3339, in the user's time zone)"
+// @Param to query string false "End date (YYYY-MM-DD or RFC 3339, in the user's time zone)"
 // @Success 200 {object} map[string]interface{} "Report generated successfully"
 // @Router /reports [get]
 func GenerateReport(c *fiber.Ctx) error {
@@ -30,21 +37,68 @@ func GenerateReport(c *fiber.Ctx) error {
 
 	report, err := services.GenerateReport(userID, from, to, db)
 	if err != nil {
+		if errors.Is(err, services.ErrMissingExchangeRate) || errors.Is(err, services.ErrInvalidDateFilter) {
+			return utils.BadResponse(c, err, err.Error())
+		}
 		return utils.InternalServerError(c, err, "Failed to generate report")
 	}
 
 	return utils.OKResponse(c, "Report generated successfully", report)
 }
 
+// GetIncomeVsExpense godoc
+// @Summary Get income vs expense over time
+// @Description Buckets the user's income, expense and net into days, weeks, months or years, in the user's base currency. Dates are read in the user's time zone and weeks begin on the user's first day of the week. Periods without transactions are zero. Without from the series covers the twelve periods up to to, which defaults to today. With groupBy the totals are also split into one series per account or category.
+// @Tags reports
+// @Security ApiKeyAuth
+// @Produce  json
+// @Param interval query string false "day, week, month (default) or year"
+// @Param from query string false "Start date (YYYY-MM-DD or RFC 3339, in the user's time zone)"
+// @Param to query string false "End date (YYYY-MM-DD or RFC 3339, in the user's time zone)"
+// @Param groupBy query string false "account or category"
+// @Success 200 {object} map[string]interface{} "Income vs expense retrieved successfully"
+// @Router /reports/income-vs-expense [get]
+func GetIncomeVsExpense(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+	interval := models.TimeSeriesInterval(c.Query("interval"))
+	groupBy := models.TimeSeriesGroupBy(c.Query("groupBy"))
+	from := c.Query("from")
+	to := c.Query("to")
+
+	db := database.DB
+
+	series, err := services.GetIncomeVsExpense(userID, interval, from, to, groupBy, db)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTimeSeries) || errors.Is(err, services.ErrMissingExchangeRate) || errors.Is(err, services.ErrInvalidDateFilter) || errors.Is(err, models.ErrMoneyOverflow) {
+			return utils.BadResponse(c, err, err.Error())
+		}
+		return utils.InternalServerError(c, err, "Failed to get income vs expense")
+	}
+
+	return utils.OKResponse(c, "Income vs expense retrieved successfully", series)
+}
+
 // ExportTransactions godoc
-// @Summary Export transaction data to a CSV file
-// @Description Exports transaction data for a custom date range to a CSV file.
+// @Summary Export transactions
+// @Description Streams the authenticated user's transactions as CSV, JSON Lines, XLSX or OFX, oldest first, with category and account names. Dates are days in the user's time zone. A transfer is one row with its destination account and amount, except in OFX where every account gets its own statement and a transfer shows up in both.
 // @Tags reports
 // @Security ApiKeyAuth
 // @Produce text/csv
-// @Param from query string false "Start date (YYYY-MM-DD)"
-// @Param to query string false "End date (YYYY-MM-DD)"
-// @Success 200 {file} file "CSV file with transaction data"
+// @Produce application/x-ndjson
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/x-ofx
+// @Param format query string false "csv (default), jsonl, xlsx or ofx"
+// @Param from query string false "Start date (YYYY-MM-DD or RFC 3339, in the user's time zone)"
+// @Param to query string false "End date (YYYY-MM-DD or RFC 3339, in the user's time zone)"
+// @Param account query string false "Only transactions from or to this account ID"
+// @Param category query string false "Only transactions in this category ID"
+// @Param type query string false "income, expense or transfer"
+// @Success 200 {file} file "Exported transactions"
+// @Failure 400 {object} map[string]interface{} "Invalid format or filter"
+// @Failure 404 {object} map[string]interface{} "Account or category not found"
 // @Router /reports/export [get]
 func ExportTransactions(c *fiber.Ctx) error {
 	userID, err := uuid.Parse(c.Locals("user_id").(string))
@@ -52,14 +106,32 @@ func ExportTransactions(c *fiber.Ctx) error {
 		return utils.BadResponse(c, err, "Invalid user ID")
 	}
 
-	db := database.DB
+	format := models.ExportFormat(c.Query("format", string(models.ExportFormatCSV)))
 
-	c.Set("Content-Type", "text/csv")
-	c.Set("Content-Disposition", "attachment; filename=transactions.csv")
+	db := database.DB
 
-	if err := services.ExportTransactions(userID, c.Response().BodyWriter(), db); err != nil {
+	filter, err := services.NewTransactionExportFilter(userID, c.Query("from"), c.Query("to"), c.Query("account"), c.Query("category"), c.Query("type"), format, db)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return utils.NotFound(c, err, "Account or category not found")
+		case errors.Is(err, services.ErrInvalidExportFormat), errors.Is(err, services.ErrInvalidExportFilter), errors.Is(err, services.ErrInvalidDateFilter):
+			return utils.BadResponse(c, err, err.Error())
+		}
 		return utils.InternalServerError(c, err, "Failed to export transactions")
 	}
 
+	filename := fmt.Sprintf("transactions-%s.%s", services.UserToday(userID, db).Format("2006-01-02"), format)
+	c.Set("Content-Type", format.ContentType())
+	c.Set("Content-Disposition", "attachment; filename="+filename)
+
+	// Rows are written while the response is sent, so a failure after this point can only cut
+	// the file short.
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := services.ExportTransactions(userID, filter, format, w, db); err != nil {
+			log.Printf("Error exporting transactions for user %s: %v", userID, err)
+		}
+	})
+
 	return nil
 }
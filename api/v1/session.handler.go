@@ -0,0 +1,168 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/services"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+// RefreshToken godoc
+// @Summary Refresh an access token
+// @Description Exchanges a refresh token for a new access token and a new refresh token. Each refresh token can be used once, reusing one revokes its session.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param input body RefreshTokenInput true "Refresh Token Input"
+// @Success 200 {object} map[string]interface{} "Token refreshed successfully"
+// @Router /auth/refresh [post]
+func RefreshToken(c *fiber.Ctx) error {
+	type RefreshTokenInput struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+
+	var input RefreshTokenInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	if input.RefreshToken == "" {
+		return utils.BadResponse(c, nil, "refreshToken is required")
+	}
+
+	db := database.DB
+	cfg := c.Locals("cfg").(*config.Config)
+
+	tokens, err := services.RefreshSession(input.RefreshToken, db, cfg)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) {
+			return utils.UnauthorizedAccess(c, err, err.Error())
+		}
+		return utils.InternalServerError(c, err, "Failed to refresh token")
+	}
+
+	return utils.OKResponse(c, "Token refreshed successfully", tokens)
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Revokes the current session. Its access and refresh tokens stop working immediately.
+// @Tags auth
+// @Security ApiKeyAuth
+// @Produce  json
+// @Success 200 {object} map[string]interface{} "Logged out successfully"
+// @Router /auth/logout [post]
+func Logout(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	sessionID, err := uuid.Parse(c.Locals("session_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid session ID")
+	}
+
+	db := database.DB
+
+	if err := services.Logout(sessionID, userID, db); err != nil {
+		return utils.InternalServerError(c, err, "Failed to log out")
+	}
+
+	return utils.OKResponse(c, "Logged out successfully", nil)
+}
+
+// GetSessions godoc
+// @Summary List active sessions
+// @Description Lists the authenticated user's active sessions, one per logged in device.
+// @Tags auth
+// @Security ApiKeyAuth
+// @Produce  json
+// @Success 200 {object} map[string]interface{} "Sessions retrieved successfully"
+// @Router /auth/sessions [get]
+func GetSessions(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	sessionID, err := uuid.Parse(c.Locals("session_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid session ID")
+	}
+
+	db := database.DB
+
+	sessions, err := services.GetSessions(userID, sessionID, db)
+	if err != nil {
+		return utils.InternalServerError(c, err, "Failed to get sessions")
+	}
+
+	return utils.OKResponse(c, "Sessions retrieved successfully", sessions)
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description Signs out one of the authenticated user's sessions.
+// @Tags auth
+// @Security ApiKeyAuth
+// @Produce  json
+// @Param id path string true "Session ID"
+// @Success 200 {object} map[string]interface{} "Session revoked successfully"
+// @Router /auth/sessions/{id} [delete]
+func RevokeSession(c *fiber.Ctx) error {
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid session ID")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	if err := services.RevokeSession(sessionID, userID, db); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.NotFound(c, err, "Session not found")
+		}
+		return utils.InternalServerError(c, err, "Failed to revoke session")
+	}
+
+	return utils.OKResponse(c, "Session revoked successfully", nil)
+}
+
+// RevokeOtherSessions godoc
+// @Summary Revoke all other sessions
+// @Description Signs out every session of the authenticated user except the current one.
+// @Tags auth
+// @Security ApiKeyAuth
+// @Produce  json
+// @Success 200 {object} map[string]interface{} "Other sessions revoked successfully"
+// @Router /auth/sessions [delete]
+func RevokeOtherSessions(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	sessionID, err := uuid.Parse(c.Locals("session_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid session ID")
+	}
+
+	db := database.DB
+
+	if err := services.RevokeOtherSessions(userID, sessionID, db); err != nil {
+		return utils.InternalServerError(c, err, "Failed to revoke sessions")
+	}
+
+	return utils.OKResponse(c, "Other sessions revoked successfully", nil)
+}
@@ -2,19 +2,21 @@ package v1
 
 import (
 	"database/sql"
+	"errors"
 	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/services"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
 )
 
 // CreateTransaction godoc
 // @Summary Create a new transaction
-// @Description Creates a new transaction for the authenticated user.
+// @Description Creates a new transaction for the authenticated user. Without an accountId the default account from the user's preferences is used. The user's transaction rules fill in the category, budget and note when they are left out.
 // @Tags transactions
 // @Security ApiKeyAuth
 // @Accept  json
@@ -24,13 +26,13 @@ import (
 // @Router /transactions/create [post]
 func CreateTransaction(c *fiber.Ctx) error {
 	type CreateTransactionInput struct {
-		AccountID   string  `json:"accountId"`
-		CategoryID  string  `json:"categoryId"`
-		BudgetID    string  `json:"budgetId"`
-		Description string  `json:"description"`
-		Amount      float64 `json:"amount"`
-		Date        string  `json:"date"`
-		Note        string  `json:"note"`
+		AccountID   string       `json:"accountId"`
+		CategoryID  string       `json:"categoryId"`
+		BudgetID    string       `json:"budgetId"`
+		Description string       `json:"description"`
+		Amount      models.Money `json:"amount"`
+		Date        string       `json:"date"`
+		Note        string       `json:"note"`
 	}
 
 	var input CreateTransactionInput
@@ -46,14 +48,31 @@ func CreateTransaction(c *fiber.Ctx) error {
 		return utils.BadResponse(c, err, "Invalid user ID")
 	}
 
-	accountID, err := uuid.Parse(input.AccountID)
-	if err != nil {
-		return utils.BadResponse(c, err, "Invalid account ID")
+	// Without an account the transaction goes to the user's default account.
+	var accountID uuid.UUID
+	if input.AccountID == "" {
+		accountID, err = services.GetDefaultAccountID(userID, db)
+		if errors.Is(err, services.ErrNoDefaultAccount) {
+			return utils.BadResponse(c, err, err.Error())
+		}
+		if err != nil {
+			return utils.InternalServerError(c, err, "Failed to get default account")
+		}
+	} else {
+		accountID, err = uuid.Parse(input.AccountID)
+		if err != nil {
+			return utils.BadResponse(c, err, "Invalid account ID")
+		}
 	}
 
-	categoryID, err := uuid.Parse(input.CategoryID)
-	if err != nil {
-		return utils.BadResponse(c, err, "Invalid category ID")
+	// Without a category the user's rules have to set one.
+	var categoryID uuid.NullUUID
+	if input.CategoryID != "" {
+		parsedCategoryID, err := uuid.Parse(input.CategoryID)
+		if err != nil {
+			return utils.BadResponse(c, err, "Invalid category ID")
+		}
+		categoryID = uuid.NullUUID{UUID: parsedCategoryID, Valid: true}
 	}
 
 	var budgetID uuid.NullUUID
@@ -72,6 +91,12 @@ func CreateTransaction(c *fiber.Ctx) error {
 
 	transaction, err := services.CreateTransaction(userID, accountID, categoryID, budgetID, input.Description, input.Amount, transactionDate, sql.NullString{String: input.Note, Valid: input.Note != ""}, db)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.NotFound(c, err, "Account, category or budget not found")
+		}
+		if errors.Is(err, services.ErrCategoryRequired) || errors.Is(err, models.ErrMoneyOverflow) {
+			return utils.BadResponse(c, err, err.Error())
+		}
 		return utils.InternalServerError(c, err, "Failed to create transaction")
 	}
 
@@ -89,8 +114,8 @@ func CreateTransaction(c *fiber.Ctx) error {
 // @Param description query string false "Filter by description"
 // @Param category query string false "Filter by category ID"
 // @Param account query string false "Filter by account ID"
-// @Param startDate query string false "Filter by start date (YYYY-MM-DD)"
-// @Param endDate query string false "Filter by end date (YYYY-MM-DD)"
+// @Param startDate query string false "Filter by start date (YYYY-MM-DD or RFC 3339, in the user's time zone)"
+// @Param endDate query string false "Filter by end date (YYYY-MM-DD or RFC 3339, in the user's time zone)"
 // @Param budget query string false "Filter by budget ID"
 // @Success 200 {object} map[string]interface{} "Transactions retrieved successfully"
 // @Router /transactions [get]
@@ -112,6 +137,9 @@ func GetTransactions(c *fiber.Ctx) error {
 
 	transactions, err := services.GetTransactions(userID, page, limit, description, categoryID, accountID, budgetID, startDate, endDate, db)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidDateFilter) {
+			return utils.BadResponse(c, err, err.Error())
+		}
 		return utils.InternalServerError(c, err, "Failed to get transactions")
 	}
 
@@ -131,13 +159,13 @@ func GetTransactions(c *fiber.Ctx) error {
 // @Router /transactions/update/{id} [patch]
 func UpdateTransaction(c *fiber.Ctx) error {
 	type UpdateTransactionInput struct {
-		AccountID   string  `json:"accountId"`
-		CategoryID  string  `json:"categoryId"`
-		BudgetID    string  `json:"budgetId"`
-		Description string  `json:"description"`
-		Amount      float64 `json:"amount"`
-		Date        string  `json:"date"`
-		Note        string  `json:"note"`
+		AccountID   string       `json:"accountId"`
+		CategoryID  string       `json:"categoryId"`
+		BudgetID    string       `json:"budgetId"`
+		Description string       `json:"description"`
+		Amount      models.Money `json:"amount"`
+		Date        string       `json:"date"`
+		Note        string       `json:"note"`
 	}
 
 	var input UpdateTransactionInput
@@ -153,6 +181,11 @@ func UpdateTransaction(c *fiber.Ctx) error {
 		return utils.BadResponse(c, err, "Invalid transaction ID")
 	}
 
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
 	accountID, err := uuid.Parse(input.AccountID)
 	if err != nil {
 		return utils.BadResponse(c, err, "Invalid account ID")
@@ -177,8 +210,14 @@ func UpdateTransaction(c *fiber.Ctx) error {
 		return utils.BadResponse(c, err, "Invalid date format")
 	}
 
-	transaction, err := services.UpdateTransaction(transactionID, accountID, categoryID, budgetID, input.Description, input.Amount, transactionDate, sql.NullString{String: input.Note, Valid: input.Note != ""}, db)
+	transaction, err := services.UpdateTransaction(transactionID, userID, accountID, categoryID, budgetID, input.Description, input.Amount, transactionDate, sql.NullString{String: input.Note, Valid: input.Note != ""}, db)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.NotFound(c, err, "Transaction, account, category or budget not found")
+		}
+		if errors.Is(err, services.ErrTransferTransaction) || errors.Is(err, models.ErrMoneyOverflow) {
+			return utils.BadResponse(c, err, err.Error())
+		}
 		return utils.InternalServerError(c, err, "Failed to update transaction")
 	}
 
@@ -200,9 +239,20 @@ func DeleteTransaction(c *fiber.Ctx) error {
 		return utils.BadResponse(c, err, "Invalid transaction ID")
 	}
 
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
 	db := database.DB
 
-	if err := services.DeleteTransaction(transactionID, db); err != nil {
+	if err := services.DeleteTransaction(transactionID, userID, db); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.NotFound(c, err, "Transaction not found")
+		}
+		if errors.Is(err, models.ErrMoneyOverflow) {
+			return utils.BadResponse(c, err, err.Error())
+		}
 		return utils.InternalServerError(c, err, "Failed to delete transaction")
 	}
 
@@ -215,8 +265,8 @@ func DeleteTransaction(c *fiber.Ctx) error {
 // @Tags transactions
 // @Security ApiKeyAuth
 // @Produce  json
-// @Param startDate query string false "Start date (YYYY-MM-DD)"
-// @Param endDate query string false "End date (YYYY-MM-DD)"
+// @Param startDate query string false "Start date (YYYY-MM-DD or RFC 3339, in the user's time zone)"
+// @Param endDate query string false "End date (YYYY-MM-DD or RFC 3339, in the user's time zone)"
 // @Success 200 {object} map[string]interface{} "Aggregate data retrieved successfully"
 // @Router /transactions/aggregate [get]
 func GetAggregateData(c *fiber.Ctx) error {
@@ -231,6 +281,9 @@ func GetAggregateData(c *fiber.Ctx) error {
 
 	data, err := services.GetAggregateData(userID, startDate, endDate, db)
 	if err != nil {
+		if errors.Is(err, services.ErrMissingExchangeRate) || errors.Is(err, services.ErrInvalidDateFilter) {
+			return utils.BadResponse(c, err, err.Error())
+		}
 		return utils.InternalServerError(c, err, "Failed to get aggregate data")
 	}
 
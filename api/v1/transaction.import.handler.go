@@ -0,0 +1,342 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/services"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+// maxStatementFiles is how many statement files one request can import.
+const maxStatementFiles = 10
+
+// importProfileError maps import profile validation failures to client errors.
+func importProfileError(c *fiber.Ctx, err error, notFound string, message string) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return utils.NotFound(c, err, notFound)
+	case errors.Is(err, services.ErrInvalidImportProfile):
+		return utils.BadResponse(c, err, err.Error())
+	case errors.Is(err, services.ErrImportProfileExists):
+		return utils.Conflict(c, err, "Import profile already exists")
+	default:
+		return utils.InternalServerError(c, err, message)
+	}
+}
+
+// ImportTransactions godoc
+// @Summary Import transactions from CSV
+// @Description Reads a bank's CSV file into an account using one of the user's import profiles. With dryRun the parsed rows are returned with their validation errors and duplicates flagged, without storing anything. Otherwise all rows are stored in one database transaction, skipping duplicates of existing transactions, and nothing is stored when any row has errors. Without an accountId the default account from the user's preferences is used.
+// @Tags transactions
+// @Security ApiKeyAuth
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param file formData file true "CSV file"
+// @Param profileId formData string true "Import profile ID"
+// @Param accountId formData string false "Account ID"
+// @Param dryRun formData bool false "Only preview the import"
+// @Success 200 {object} map[string]interface{} "Transactions imported successfully"
+// @Router /transactions/import [post]
+func ImportTransactions(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	profileID, err := uuid.Parse(c.FormValue("profileId"))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid import profile ID")
+	}
+
+	// Without an account the transactions go to the user's default account.
+	var accountID uuid.UUID
+	if c.FormValue("accountId") == "" {
+		accountID, err = services.GetDefaultAccountID(userID, db)
+		if errors.Is(err, services.ErrNoDefaultAccount) {
+			return utils.BadResponse(c, err, err.Error())
+		}
+		if err != nil {
+			return utils.InternalServerError(c, err, "Failed to get default account")
+		}
+	} else {
+		accountID, err = uuid.Parse(c.FormValue("accountId"))
+		if err != nil {
+			return utils.BadResponse(c, err, "Invalid account ID")
+		}
+	}
+
+	dryRun := false
+	if value := c.FormValue("dryRun"); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			return utils.BadResponse(c, err, "Invalid dryRun value")
+		}
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.BadResponse(c, err, "A CSV file is required")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.BadResponse(c, err, "Failed to read the uploaded file")
+	}
+	defer file.Close()
+
+	result, err := services.ImportTransactions(userID, accountID, profileID, file, dryRun, db)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return utils.NotFound(c, err, "Account or import profile not found")
+		case errors.Is(err, services.ErrInvalidImportFile), errors.Is(err, services.ErrImportHasInvalidRows), errors.Is(err, models.ErrMoneyOverflow):
+			return utils.BadResponse(c, err, err.Error())
+		}
+		return utils.InternalServerError(c, err, "Failed to import transactions")
+	}
+
+	if dryRun {
+		return utils.OKResponse(c, "Import preview generated successfully", result)
+	}
+
+	return utils.OKResponse(c, "Transactions imported successfully", result)
+}
+
+// CreateImportProfile godoc
+// @Summary Create an import profile
+// @Description Saves a CSV column mapping for transaction imports. Columns are named as in the file's header row. Amounts come either from a signed amountColumn, read with amountSign, or from debitColumn and creditColumn.
+// @Tags transactions
+// @Security ApiKeyAuth
+// @Accept  json
+// @Produce  json
+// @Param input body CreateImportProfileInput true "Create Import Profile Input"
+// @Success 201 {object} map[string]interface{} "Import profile created successfully"
+// @Router /transactions/import/profiles/create [post]
+func CreateImportProfile(c *fiber.Ctx) error {
+	type CreateImportProfileInput struct {
+		Name string `json:"name"`
+		models.ImportMapping
+	}
+
+	var input CreateImportProfileInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	profile, err := services.CreateImportProfile(userID, input.Name, input.ImportMapping, db)
+	if err != nil {
+		return importProfileError(c, err, "Category not found", "Failed to create import profile")
+	}
+
+	return utils.OKCreatedResponse(c, "Import profile created successfully", profile)
+}
+
+// GetImportProfiles godoc
+// @Summary Get all import profiles
+// @Description Gets the authenticated user's saved CSV column mappings.
+// @Tags transactions
+// @Security ApiKeyAuth
+// @Produce  json
+// @Success 200 {object} map[string]interface{} "Import profiles retrieved successfully"
+// @Router /transactions/import/profiles [get]
+func GetImportProfiles(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	profiles, err := services.GetImportProfiles(userID, db)
+	if err != nil {
+		return utils.InternalServerError(c, err, "Failed to get import profiles")
+	}
+
+	return utils.OKResponse(c, "Import profiles retrieved successfully", profiles)
+}
+
+// UpdateImportProfile godoc
+// @Summary Update an import profile
+// @Description Replaces the name and column mapping of one of the authenticated user's import profiles.
+// @Tags transactions
+// @Security ApiKeyAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Import Profile ID"
+// @Param input body UpdateImportProfileInput true "Update Import Profile Input"
+// @Success 200 {object} map[string]interface{} "Import profile updated successfully"
+// @Router /transactions/import/profiles/update/{id} [patch]
+func UpdateImportProfile(c *fiber.Ctx) error {
+	type UpdateImportProfileInput struct {
+		Name string `json:"name"`
+		models.ImportMapping
+	}
+
+	var input UpdateImportProfileInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	profileID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid import profile ID")
+	}
+
+	db := database.DB
+
+	profile, err := services.UpdateImportProfile(profileID, userID, input.Name, input.ImportMapping, db)
+	if err != nil {
+		return importProfileError(c, err, "Import profile or category not found", "Failed to update import profile")
+	}
+
+	return utils.OKResponse(c, "Import profile updated successfully", profile)
+}
+
+// DeleteImportProfile godoc
+// @Summary Delete an import profile
+// @Description Deletes one of the authenticated user's import profiles.
+// @Tags transactions
+// @Security ApiKeyAuth
+// @Produce  json
+// @Param id path string true "Import Profile ID"
+// @Success 200 {object} map[string]interface{} "Import profile deleted successfully"
+// @Router /transactions/import/profiles/delete/{id} [delete]
+func DeleteImportProfile(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	profileID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid import profile ID")
+	}
+
+	db := database.DB
+
+	if err := services.DeleteImportProfile(profileID, userID, db); err != nil {
+		return importProfileError(c, err, "Import profile not found", "Failed to delete import profile")
+	}
+
+	return utils.OKResponse(c, "Import profile deleted successfully", nil)
+}
+
+// ImportStatements godoc
+// @Summary Import OFX, QFX or QIF statements
+// @Description Imports one or more bank statement files into an account, reporting for each file how many rows were imported, skipped and failed. Rows whose bank transaction ID (FITID) is already stored on the account are skipped, so overlapping downloads can be imported again. QIF files carry no IDs and their rows are skipped when they match a stored transaction by date, amount and description. Rows get the category named in a QIF file when the user has one, and otherwise the given default category or Other Income and Other Expense. Without an accountId the default account from the user's preferences is used.
+// @Tags transactions
+// @Security ApiKeyAuth
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param files formData file true "OFX, QFX or QIF files"
+// @Param accountId formData string false "Account ID"
+// @Param incomeCategoryId formData string false "Category for income without a category"
+// @Param expenseCategoryId formData string false "Category for expenses without a category"
+// @Param dateFormat formData string false "Order of day and month in QIF dates, e.g. DD/MM/YYYY (default MM/DD/YYYY)"
+// @Success 200 {object} map[string]interface{} "Statements imported successfully"
+// @Router /transactions/import/statements [post]
+func ImportStatements(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	// Without an account the transactions go to the user's default account.
+	var accountID uuid.UUID
+	if c.FormValue("accountId") == "" {
+		accountID, err = services.GetDefaultAccountID(userID, db)
+		if errors.Is(err, services.ErrNoDefaultAccount) {
+			return utils.BadResponse(c, err, err.Error())
+		}
+		if err != nil {
+			return utils.InternalServerError(c, err, "Failed to get default account")
+		}
+	} else {
+		accountID, err = uuid.Parse(c.FormValue("accountId"))
+		if err != nil {
+			return utils.BadResponse(c, err, "Invalid account ID")
+		}
+	}
+
+	var categoryIDs [2]uuid.NullUUID
+	for i, field := range []string{"incomeCategoryId", "expenseCategoryId"} {
+		if value := c.FormValue(field); value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				return utils.BadResponse(c, err, "Invalid category ID")
+			}
+			categoryIDs[i] = uuid.NullUUID{UUID: id, Valid: true}
+		}
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["files"]) == 0 {
+		return utils.BadResponse(c, err, "At least one statement file is required")
+	}
+
+	fileHeaders := form.File["files"]
+	if len(fileHeaders) > maxStatementFiles {
+		return utils.BadResponse(c, nil, fmt.Sprintf("At most %d files can be imported at once", maxStatementFiles))
+	}
+
+	options, err := services.NewStatementImportOptions(userID, categoryIDs[0], categoryIDs[1], models.DateFormat(c.FormValue("dateFormat")), db)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return utils.NotFound(c, err, "Category not found")
+		case errors.Is(err, services.ErrInvalidDateFormat), errors.Is(err, services.ErrInvalidStatementCategory):
+			return utils.BadResponse(c, err, err.Error())
+		}
+		return utils.InternalServerError(c, err, "Failed to import statements")
+	}
+
+	results := make([]*models.StatementImportResult, 0, len(fileHeaders))
+	for _, fileHeader := range fileHeaders {
+		file, err := fileHeader.Open()
+		if err != nil {
+			results = append(results, &models.StatementImportResult{File: fileHeader.Filename, Error: "failed to read the uploaded file"})
+			continue
+		}
+
+		result, err := services.ImportStatement(userID, accountID, fileHeader.Filename, file, options, db)
+		file.Close()
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return utils.NotFound(c, err, "Account not found")
+			}
+			if errors.Is(err, models.ErrMoneyOverflow) {
+				return utils.BadResponse(c, err, err.Error())
+			}
+			return utils.InternalServerError(c, err, "Failed to import statements")
+		}
+
+		results = append(results, result)
+	}
+
+	return utils.OKResponse(c, "Statements imported successfully", results)
+}
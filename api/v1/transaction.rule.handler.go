@@ -0,0 +1,264 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/services"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+// transactionRuleError maps transaction rule validation failures to client errors.
+func transactionRuleError(c *fiber.Ctx, err error, notFound string, message string) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return utils.NotFound(c, err, notFound)
+	case errors.Is(err, services.ErrInvalidTransactionRule):
+		return utils.BadResponse(c, err, err.Error())
+	default:
+		return utils.InternalServerError(c, err, message)
+	}
+}
+
+// CreateTransactionRule godoc
+// @Summary Create a transaction rule
+// @Description Saves a rule that sets the category, budget or note of new and imported transactions matching its conditions: descriptionContains, descriptionPattern (a regular expression), minAmount and maxAmount, accountId and noteContains. Text conditions ignore case. Rules are tried by descending priority and the first match is applied.
+// @Tags rules
+// @Security ApiKeyAuth
+// @Accept  json
+// @Produce  json
+// @Param input body CreateTransactionRuleInput true "Create Transaction Rule Input"
+// @Success 201 {object} map[string]interface{} "Transaction rule created successfully"
+// @Router /rules/create [post]
+func CreateTransactionRule(c *fiber.Ctx) error {
+	type CreateTransactionRuleInput struct {
+		Name     string `json:"name"`
+		Priority int    `json:"priority"`
+		IsActive *bool  `json:"isActive"`
+		models.RuleConditions
+		models.RuleActions
+	}
+
+	var input CreateTransactionRuleInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	rule, err := services.CreateTransactionRule(userID, input.Name, input.Priority, input.IsActive, input.RuleConditions, input.RuleActions, db)
+	if err != nil {
+		return transactionRuleError(c, err, "Account, category or budget not found", "Failed to create transaction rule")
+	}
+
+	return utils.OKCreatedResponse(c, "Transaction rule created successfully", rule)
+}
+
+// GetTransactionRules godoc
+// @Summary Get all transaction rules
+// @Description Gets the authenticated user's transaction rules in the order they are tried.
+// @Tags rules
+// @Security ApiKeyAuth
+// @Produce  json
+// @Success 200 {object} map[string]interface{} "Transaction rules retrieved successfully"
+// @Router /rules [get]
+func GetTransactionRules(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	rules, err := services.GetTransactionRules(userID, db)
+	if err != nil {
+		return utils.InternalServerError(c, err, "Failed to get transaction rules")
+	}
+
+	return utils.OKResponse(c, "Transaction rules retrieved successfully", rules)
+}
+
+// UpdateTransactionRule godoc
+// @Summary Update a transaction rule
+// @Description Replaces the conditions and actions of one of the user's transaction rules. The rule stays active or inactive when isActive is left out.
+// @Tags rules
+// @Security ApiKeyAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Transaction Rule ID"
+// @Param input body UpdateTransactionRuleInput true "Update Transaction Rule Input"
+// @Success 200 {object} map[string]interface{} "Transaction rule updated successfully"
+// @Router /rules/update/{id} [patch]
+func UpdateTransactionRule(c *fiber.Ctx) error {
+	type UpdateTransactionRuleInput struct {
+		Name     string `json:"name"`
+		Priority int    `json:"priority"`
+		IsActive *bool  `json:"isActive"`
+		models.RuleConditions
+		models.RuleActions
+	}
+
+	var input UpdateTransactionRuleInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid transaction rule ID")
+	}
+
+	db := database.DB
+
+	rule, err := services.UpdateTransactionRule(id, userID, input.Name, input.Priority, input.IsActive, input.RuleConditions, input.RuleActions, db)
+	if err != nil {
+		return transactionRuleError(c, err, "Transaction rule, account, category or budget not found", "Failed to update transaction rule")
+	}
+
+	return utils.OKResponse(c, "Transaction rule updated successfully", rule)
+}
+
+// DeleteTransactionRule godoc
+// @Summary Delete a transaction rule
+// @Description Deletes one of the user's transaction rules. Transactions it categorised keep their values.
+// @Tags rules
+// @Security ApiKeyAuth
+// @Produce  json
+// @Param id path string true "Transaction Rule ID"
+// @Success 200 {object} map[string]interface{} "Transaction rule deleted successfully"
+// @Router /rules/delete/{id} [delete]
+func DeleteTransactionRule(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid transaction rule ID")
+	}
+
+	db := database.DB
+
+	if err := services.DeleteTransactionRule(id, userID, db); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.NotFound(c, err, "Transaction rule not found")
+		}
+		return utils.InternalServerError(c, err, "Failed to delete transaction rule")
+	}
+
+	return utils.OKResponse(c, "Transaction rule deleted successfully", nil)
+}
+
+// ApplyTransactionRules godoc
+// @Summary Re-run the transaction rules
+// @Description Runs the active rules over the user's income and expenses dated between startDate and endDate, inclusive and in the user's time zone. A matching rule's category and budget replace the stored ones and its note fills in empty notes. With dryRun the changes are only listed.
+// @Tags rules
+// @Security ApiKeyAuth
+// @Accept  json
+// @Produce  json
+// @Param input body ApplyTransactionRulesInput true "Apply Transaction Rules Input"
+// @Success 200 {object} map[string]interface{} "Transaction rules applied successfully"
+// @Router /rules/apply [post]
+func ApplyTransactionRules(c *fiber.Ctx) error {
+	type ApplyTransactionRulesInput struct {
+		StartDate string `json:"startDate"`
+		EndDate   string `json:"endDate"`
+		DryRun    bool   `json:"dryRun"`
+	}
+
+	var input ApplyTransactionRulesInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	result, err := services.ApplyTransactionRules(userID, input.StartDate, input.EndDate, input.DryRun, db)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRuleDateRange) || errors.Is(err, services.ErrInvalidDateFilter) {
+			return utils.BadResponse(c, err, err.Error())
+		}
+		return utils.InternalServerError(c, err, "Failed to apply transaction rules")
+	}
+
+	if result.DryRun {
+		return utils.OKResponse(c, "Transaction rule preview generated successfully", result)
+	}
+
+	return utils.OKResponse(c, "Transaction rules applied successfully", result)
+}
+
+// TestTransactionRules godoc
+// @Summary Test the transaction rules
+// @Description Shows which active rule would match a transaction with the given details and what it would set. Without a type, rules of either type can match, as when creating a transaction without a category.
+// @Tags rules
+// @Security ApiKeyAuth
+// @Accept  json
+// @Produce  json
+// @Param input body TestTransactionRulesInput true "Test Transaction Rules Input"
+// @Success 200 {object} map[string]interface{} "Transaction rules tested successfully"
+// @Router /rules/test [post]
+func TestTransactionRules(c *fiber.Ctx) error {
+	type TestTransactionRulesInput struct {
+		AccountID   string                 `json:"accountId"`
+		Type        models.TransactionType `json:"type"`
+		Description string                 `json:"description"`
+		Amount      models.Money           `json:"amount"`
+		Note        string                 `json:"note"`
+	}
+
+	var input TestTransactionRulesInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	var accountID uuid.UUID
+	if input.AccountID != "" {
+		accountID, err = uuid.Parse(input.AccountID)
+		if err != nil {
+			return utils.BadResponse(c, err, "Invalid account ID")
+		}
+	}
+
+	if input.Type != "" && input.Type != models.TransactionTypeIncome && input.Type != models.TransactionTypeExpense {
+		return utils.BadResponse(c, nil, "Type must be income or expense")
+	}
+
+	db := database.DB
+
+	match, err := services.MatchTransactionRule(userID, accountID, input.Type, input.Description, input.Amount, input.Note, db)
+	if err != nil {
+		return utils.InternalServerError(c, err, "Failed to test transaction rules")
+	}
+
+	return utils.OKResponse(c, "Transaction rules tested successfully", match)
+}
@@ -0,0 +1,150 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/services"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+// transferError maps transfer validation failures to client errors.
+func transferError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return utils.NotFound(c, err, "Transfer or account not found")
+	case errors.Is(err, services.ErrSameAccountTransfer),
+		errors.Is(err, services.ErrInvalidTransferAmount),
+		errors.Is(err, services.ErrDestinationAmountRequired),
+		errors.Is(err, services.ErrNotTransfer),
+		errors.Is(err, models.ErrMoneyOverflow):
+		return utils.BadResponse(c, err, err.Error())
+	default:
+		return utils.InternalServerError(c, err, message)
+	}
+}
+
+// CreateTransfer godoc
+// @Summary Transfer money between two accounts
+// @Description Moves money from one of the authenticated user's accounts to another. Both balances are updated atomically and the transfer is excluded from income and expense totals.
+// @Tags transactions
+// @Security ApiKeyAuth
+// @Accept  json
+// @Produce  json
+// @Param input body CreateTransferInput true "Create Transfer Input"
+// @Success 201 {object} map[string]interface{} "Transfer created successfully"
+// @Router /transactions/transfers/create [post]
+func CreateTransfer(c *fiber.Ctx) error {
+	type CreateTransferInput struct {
+		FromAccountID     string        `json:"fromAccountId"`
+		ToAccountID       string        `json:"toAccountId"`
+		Amount            models.Money  `json:"amount"`
+		DestinationAmount *models.Money `json:"destinationAmount"`
+		Description       string        `json:"description"`
+		Date              string        `json:"date"`
+		Note              string        `json:"note"`
+	}
+
+	var input CreateTransferInput
+
+	db := database.DB
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	fromAccountID, err := uuid.Parse(input.FromAccountID)
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid source account ID")
+	}
+
+	toAccountID, err := uuid.Parse(input.ToAccountID)
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid destination account ID")
+	}
+
+	transactionDate, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid date format")
+	}
+
+	transfer, err := services.CreateTransfer(userID, fromAccountID, toAccountID, input.Amount, input.DestinationAmount, input.Description, transactionDate, sql.NullString{String: input.Note, Valid: input.Note != ""}, db)
+	if err != nil {
+		return transferError(c, err, "Failed to create transfer")
+	}
+
+	return utils.OKCreatedResponse(c, "Transfer created successfully", transfer)
+}
+
+// UpdateTransfer godoc
+// @Summary Update a transfer
+// @Description Updates a transfer, reversing both legs of the original before applying the new accounts and amounts.
+// @Tags transactions
+// @Security ApiKeyAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Transaction ID"
+// @Param input body UpdateTransferInput true "Update Transfer Input"
+// @Success 200 {object} map[string]interface{} "Transfer updated successfully"
+// @Router /transactions/transfers/update/{id} [patch]
+func UpdateTransfer(c *fiber.Ctx) error {
+	type UpdateTransferInput struct {
+		FromAccountID     string        `json:"fromAccountId"`
+		ToAccountID       string        `json:"toAccountId"`
+		Amount            models.Money  `json:"amount"`
+		DestinationAmount *models.Money `json:"destinationAmount"`
+		Description       string        `json:"description"`
+		Date              string        `json:"date"`
+		Note              string        `json:"note"`
+	}
+
+	var input UpdateTransferInput
+
+	db := database.DB
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	transactionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid transaction ID")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	fromAccountID, err := uuid.Parse(input.FromAccountID)
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid source account ID")
+	}
+
+	toAccountID, err := uuid.Parse(input.ToAccountID)
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid destination account ID")
+	}
+
+	transactionDate, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid date format")
+	}
+
+	transfer, err := services.UpdateTransfer(transactionID, userID, fromAccountID, toAccountID, input.Amount, input.DestinationAmount, input.Description, transactionDate, sql.NullString{String: input.Note, Valid: input.Note != ""}, db)
+	if err != nil {
+		return transferError(c, err, "Failed to update transfer")
+	}
+
+	return utils.OKResponse(c, "Transfer updated successfully", transfer)
+}
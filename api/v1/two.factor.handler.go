@@ -0,0 +1,229 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/services"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+// twoFactorError maps errors from two-factor authentication to a response.
+func twoFactorError(c *fiber.Ctx, err error, message string) error {
+	var blocked *services.TooManyAttemptsError
+	if errors.As(err, &blocked) {
+		return tooManyAttempts(c, blocked)
+	}
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return utils.NotFound(c, err, "User not found")
+	case errors.Is(err, services.ErrTwoFactorAlreadyEnabled):
+		return utils.Conflict(c, err, err.Error())
+	case errors.Is(err, services.ErrInvalidChallenge), errors.Is(err, services.ErrInvalidTwoFactorCode):
+		return utils.UnauthorizedAccess(c, err, err.Error())
+	case errors.Is(err, services.ErrTwoFactorNotEnabled), errors.Is(err, services.ErrTwoFactorNotSetUp),
+		errors.Is(err, services.ErrIncorrectPassword), errors.Is(err, services.ErrPasswordNotSet):
+		return utils.BadResponse(c, err, err.Error())
+	}
+	return utils.InternalServerError(c, err, message)
+}
+
+// GetTwoFactorStatus godoc
+// @Summary Get two-factor authentication status
+// @Description Shows whether two-factor authentication is enabled and how many recovery codes are left.
+// @Tags auth
+// @Security ApiKeyAuth
+// @Produce  json
+// @Success 200 {object} map[string]interface{} "Two-factor status retrieved successfully"
+// @Router /auth/2fa [get]
+func GetTwoFactorStatus(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	status, err := services.GetTwoFactorStatus(userID, db)
+	if err != nil {
+		return utils.InternalServerError(c, err, "Failed to get two-factor status")
+	}
+
+	return utils.OKResponse(c, "Two-factor status retrieved successfully", status)
+}
+
+// SetupTwoFactor godoc
+// @Summary Start two-factor enrollment
+// @Description Generates a TOTP secret and its otpauth:// provisioning URI to show as a QR code. Two-factor authentication is enabled once a code is confirmed at /auth/2fa/enable.
+// @Tags auth
+// @Security ApiKeyAuth
+// @Produce  json
+// @Success 200 {object} map[string]interface{} "Two-factor setup started"
+// @Router /auth/2fa/setup [post]
+func SetupTwoFactor(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+	cfg := c.Locals("cfg").(*config.Config)
+
+	setup, err := services.SetupTwoFactor(userID, db, cfg)
+	if err != nil {
+		return twoFactorError(c, err, "Failed to start two-factor setup")
+	}
+
+	return utils.OKResponse(c, "Two-factor setup started", setup)
+}
+
+// EnableTwoFactor godoc
+// @Summary Enable two-factor authentication
+// @Description Confirms enrollment with a code from the authenticator app and returns one-time recovery codes. The codes are not shown again.
+// @Tags auth
+// @Security ApiKeyAuth
+// @Accept  json
+// @Produce  json
+// @Param input body TwoFactorCodeInput true "Two-Factor Code Input"
+// @Success 200 {object} map[string]interface{} "Two-factor authentication enabled"
+// @Router /auth/2fa/enable [post]
+func EnableTwoFactor(c *fiber.Ctx) error {
+	type TwoFactorCodeInput struct {
+		Code string `json:"code"`
+	}
+
+	var input TwoFactorCodeInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+	cfg := c.Locals("cfg").(*config.Config)
+
+	codes, err := services.EnableTwoFactor(userID, input.Code, db, cfg)
+	if err != nil {
+		return twoFactorError(c, err, "Failed to enable two-factor authentication")
+	}
+
+	return utils.OKResponse(c, "Two-factor authentication enabled", fiber.Map{"recoveryCodes": codes})
+}
+
+// DisableTwoFactor godoc
+// @Summary Disable two-factor authentication
+// @Description Turns two-factor authentication off after the password is re-entered, deleting the secret and recovery codes.
+// @Tags auth
+// @Security ApiKeyAuth
+// @Accept  json
+// @Produce  json
+// @Param input body DisableTwoFactorInput true "Disable Two-Factor Input"
+// @Success 200 {object} map[string]interface{} "Two-factor authentication disabled"
+// @Router /auth/2fa/disable [post]
+func DisableTwoFactor(c *fiber.Ctx) error {
+	type DisableTwoFactorInput struct {
+		Password string `json:"password"`
+	}
+
+	var input DisableTwoFactorInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	if err := services.DisableTwoFactor(userID, input.Password, db); err != nil {
+		return twoFactorError(c, err, "Failed to disable two-factor authentication")
+	}
+
+	return utils.OKResponse(c, "Two-factor authentication disabled", nil)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replaces all recovery codes after a current code from the authenticator app is entered.
+// @Tags auth
+// @Security ApiKeyAuth
+// @Accept  json
+// @Produce  json
+// @Param input body TwoFactorCodeInput true "Two-Factor Code Input"
+// @Success 200 {object} map[string]interface{} "Recovery codes regenerated"
+// @Router /auth/2fa/recovery-codes [post]
+func RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	type TwoFactorCodeInput struct {
+		Code string `json:"code"`
+	}
+
+	var input TwoFactorCodeInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+	cfg := c.Locals("cfg").(*config.Config)
+
+	codes, err := services.RegenerateRecoveryCodes(userID, input.Code, db, cfg)
+	if err != nil {
+		return twoFactorError(c, err, "Failed to regenerate recovery codes")
+	}
+
+	return utils.OKResponse(c, "Recovery codes regenerated", fiber.Map{"recoveryCodes": codes})
+}
+
+// VerifyTwoFactorLogin godoc
+// @Summary Complete a two-factor login
+// @Description Exchanges the challenge token from /auth/login and a code from the authenticator app, or a recovery code, for a session. A challenge expires after five minutes and allows five attempts.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param input body VerifyTwoFactorInput true "Verify Two-Factor Input"
+// @Success 200 {object} map[string]interface{} "Login successful"
+// @Router /auth/2fa/verify [post]
+func VerifyTwoFactorLogin(c *fiber.Ctx) error {
+	type VerifyTwoFactorInput struct {
+		ChallengeToken string `json:"challengeToken"`
+		Code           string `json:"code"`
+		RecoveryCode   string `json:"recoveryCode"`
+	}
+
+	var input VerifyTwoFactorInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	if input.ChallengeToken == "" || (input.Code == "" && input.RecoveryCode == "") {
+		return utils.BadResponse(c, nil, "challengeToken and either code or recoveryCode are required")
+	}
+
+	db := database.DB
+	cfg := c.Locals("cfg").(*config.Config)
+
+	result, err := services.VerifyTwoFactorLogin(input.ChallengeToken, input.Code, input.RecoveryCode, c.Get(fiber.HeaderUserAgent), c.IP(), db, cfg)
+	if err != nil {
+		return twoFactorError(c, err, "Failed to verify two-factor code")
+	}
+
+	return loginResponse(c, result)
+}
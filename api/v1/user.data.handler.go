@@ -0,0 +1,139 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/services"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+// ExportUserData godoc
+// @Summary Export personal data
+// @Description Downloads everything stored about the authenticated user: profile, preferences, linked identities, accounts, categories, transactions, budgets, recurring transactions and activity logs. The default is a ZIP archive with one JSON file per kind of data, format=json returns a single JSON document.
+// @Tags auth
+// @Security ApiKeyAuth
+// @Produce  application/zip
+// @Produce  json
+// @Param format query string false "zip (default) or json"
+// @Success 200 {file} file "Personal data export"
+// @Router /auth/me/export [get]
+func ExportUserData(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	format := c.Query("format", "zip")
+	if format != "zip" && format != "json" {
+		return utils.BadResponse(c, nil, "Format must be zip or json")
+	}
+
+	db := database.DB
+
+	export, err := services.ExportUserData(userID, db)
+	if errors.Is(err, sql.ErrNoRows) {
+		return utils.NotFound(c, err, "User not found")
+	}
+	if err != nil {
+		return utils.InternalServerError(c, err, "Failed to export personal data")
+	}
+
+	filename := fmt.Sprintf("finance-tracker-export-%s.%s", export.ExportedAt.Format("2006-01-02"), format)
+	c.Set("Content-Disposition", "attachment; filename="+filename)
+
+	if format == "json" {
+		return c.JSON(export)
+	}
+
+	c.Set("Content-Type", "application/zip")
+
+	if err := services.WriteUserDataZip(export, c.Response().BodyWriter()); err != nil {
+		return utils.InternalServerError(c, err, "Failed to export personal data")
+	}
+
+	return nil
+}
+
+// DeleteUserAccount godoc
+// @Summary Delete the authenticated user's account
+// @Description Confirms the password and schedules the account and all of its data for permanent deletion after the grace period (ACCOUNT_DELETION_GRACE_PERIOD). Every session is signed out. Signing in again before the deletion date allows the account to be restored. Accounts without a password have to set one with a password reset first.
+// @Tags auth
+// @Security ApiKeyAuth
+// @Accept  json
+// @Produce  json
+// @Param input body DeleteUserAccountInput true "Delete Account Input"
+// @Success 200 {object} map[string]interface{} "Account scheduled for deletion"
+// @Router /auth/me [delete]
+func DeleteUserAccount(c *fiber.Ctx) error {
+	type DeleteUserAccountInput struct {
+		Password string `json:"password"`
+	}
+
+	var input DeleteUserAccountInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+	cfg := c.Locals("cfg").(*config.Config)
+
+	deletionAt, err := services.ScheduleUserDeletion(userID, input.Password, db, cfg)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return utils.NotFound(c, err, "User not found")
+		case errors.Is(err, services.ErrDeletionAlreadyScheduled):
+			return utils.Conflict(c, err, err.Error())
+		case errors.Is(err, services.ErrIncorrectPassword), errors.Is(err, services.ErrPasswordNotSet):
+			return utils.BadResponse(c, err, err.Error())
+		}
+		return utils.InternalServerError(c, err, "Failed to delete account")
+	}
+
+	if deletionAt == nil {
+		return utils.OKResponse(c, "Account deleted", nil)
+	}
+
+	return utils.OKResponse(c, "Account scheduled for deletion", fiber.Map{"deletionScheduledAt": deletionAt})
+}
+
+// RestoreUserAccount godoc
+// @Summary Restore an account scheduled for deletion
+// @Description Cancels the pending deletion of the authenticated user's account.
+// @Tags auth
+// @Security ApiKeyAuth
+// @Produce  json
+// @Success 200 {object} map[string]interface{} "Account restored"
+// @Router /auth/me/restore [post]
+func RestoreUserAccount(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	if err := services.CancelUserDeletion(userID, db); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return utils.NotFound(c, err, "User not found")
+		case errors.Is(err, services.ErrDeletionNotScheduled):
+			return utils.BadResponse(c, err, err.Error())
+		}
+		return utils.InternalServerError(c, err, "Failed to restore account")
+	}
+
+	return utils.OKResponse(c, "Account restored", nil)
+}
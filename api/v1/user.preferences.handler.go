@@ -0,0 +1,117 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/services"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+// GetPreferences godoc
+// @Summary Get the authenticated user's preferences
+// @Description Gets the timezone, base currency, locale, date format, first day of the week and default account. Users that never changed them get the server defaults.
+// @Tags auth
+// @Security ApiKeyAuth
+// @Produce  json
+// @Success 200 {object} map[string]interface{} "Preferences retrieved successfully"
+// @Router /auth/preferences [get]
+func GetPreferences(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	db := database.DB
+
+	preferences, err := services.GetUserPreferences(userID, db)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.NotFound(c, err, "User not found")
+		}
+		return utils.InternalServerError(c, err, "Failed to get preferences")
+	}
+
+	return utils.OKResponse(c, "Preferences retrieved successfully", preferences)
+}
+
+// UpdatePreferences godoc
+// @Summary Update the authenticated user's preferences
+// @Description Changes any of timezone (IANA name), baseCurrency, locale (language tag), dateFormat, weekStart (0 is Sunday) and defaultAccountId. Omitted fields are left unchanged and an empty defaultAccountId clears the default account.
+// @Tags auth
+// @Security ApiKeyAuth
+// @Accept  json
+// @Produce  json
+// @Param input body UpdatePreferencesInput true "Update Preferences Input"
+// @Success 200 {object} map[string]interface{} "Preferences updated successfully"
+// @Router /auth/preferences [patch]
+func UpdatePreferences(c *fiber.Ctx) error {
+	type UpdatePreferencesInput struct {
+		Timezone         *string `json:"timezone"`
+		BaseCurrency     *string `json:"baseCurrency"`
+		Locale           *string `json:"locale"`
+		DateFormat       *string `json:"dateFormat"`
+		WeekStart        *int    `json:"weekStart"`
+		DefaultAccountID *string `json:"defaultAccountId"`
+	}
+
+	var input UpdatePreferencesInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.BadResponse(c, err, "Invalid user ID")
+	}
+
+	update := models.UserPreferencesUpdate{
+		Timezone:  input.Timezone,
+		Locale:    input.Locale,
+		WeekStart: input.WeekStart,
+	}
+
+	if input.BaseCurrency != nil {
+		currency := models.NormalizeCurrency(*input.BaseCurrency)
+		update.BaseCurrency = &currency
+	}
+
+	if input.DateFormat != nil {
+		dateFormat := models.DateFormat(*input.DateFormat)
+		update.DateFormat = &dateFormat
+	}
+
+	if input.DefaultAccountID != nil {
+		var defaultAccountID uuid.NullUUID
+		if *input.DefaultAccountID != "" {
+			accountID, err := uuid.Parse(*input.DefaultAccountID)
+			if err != nil {
+				return utils.BadResponse(c, err, "Invalid account ID")
+			}
+			defaultAccountID = uuid.NullUUID{UUID: accountID, Valid: true}
+		}
+		update.DefaultAccountID = &defaultAccountID
+	}
+
+	db := database.DB
+
+	preferences, err := services.UpdateUserPreferences(userID, update, db)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return utils.NotFound(c, err, "User or account not found")
+		case errors.Is(err, services.ErrInvalidTimezone), errors.Is(err, services.ErrInvalidCurrency),
+			errors.Is(err, services.ErrInvalidLocale), errors.Is(err, services.ErrInvalidDateFormat),
+			errors.Is(err, services.ErrInvalidWeekStart):
+			return utils.BadResponse(c, err, err.Error())
+		}
+		return utils.InternalServerError(c, err, "Failed to update preferences")
+	}
+
+	return utils.OKResponse(c, "Preferences updated successfully", preferences)
+}
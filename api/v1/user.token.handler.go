@@ -0,0 +1,142 @@
+package v1
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/services"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+// VerifyEmail godoc
+// @Summary Verify an email address
+// @Description Verifies the user's email address with the token from the verification email. Each token works once and expires after 48 hours.
+// @Tags auth
+// @Produce  json
+// @Param token query string true "Verification token"
+// @Success 200 {object} map[string]interface{} "Email verified successfully"
+// @Router /auth/verify-email [get]
+func VerifyEmail(c *fiber.Ctx) error {
+	token := c.Query("token")
+	if token == "" {
+		return utils.BadResponse(c, nil, "token is required")
+	}
+
+	db := database.DB
+
+	if err := services.VerifyEmail(token, db); err != nil {
+		if errors.Is(err, services.ErrInvalidUserToken) {
+			return utils.BadResponse(c, err, err.Error())
+		}
+		return utils.InternalServerError(c, err, "Failed to verify email")
+	}
+
+	return utils.OKResponse(c, "Email verified successfully", nil)
+}
+
+// ResendVerificationEmail godoc
+// @Summary Resend the verification email
+// @Description Sends a new verification link and invalidates earlier ones. The response is the same whether or not the email is registered.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param input body ResendVerificationInput true "Resend Verification Input"
+// @Success 200 {object} map[string]interface{} "Verification email sent"
+// @Router /auth/verify-email/resend [post]
+func ResendVerificationEmail(c *fiber.Ctx) error {
+	type ResendVerificationInput struct {
+		Email string `json:"email"`
+	}
+
+	var input ResendVerificationInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	if input.Email == "" {
+		return utils.BadResponse(c, nil, "email is required")
+	}
+
+	db := database.DB
+	cfg := c.Locals("cfg").(*config.Config)
+
+	if err := services.ResendVerificationEmail(input.Email, db, cfg); err != nil {
+		return utils.InternalServerError(c, err, "Failed to send verification email")
+	}
+
+	return utils.OKResponse(c, "If the email is registered and not yet verified, a verification link has been sent", nil)
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Emails a password reset link that expires after one hour. The response is the same whether or not the email is registered.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param input body ForgotPasswordInput true "Forgot Password Input"
+// @Success 200 {object} map[string]interface{} "Password reset email sent"
+// @Router /auth/forgot-password [post]
+func ForgotPassword(c *fiber.Ctx) error {
+	type ForgotPasswordInput struct {
+		Email string `json:"email"`
+	}
+
+	var input ForgotPasswordInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	if input.Email == "" {
+		return utils.BadResponse(c, nil, "email is required")
+	}
+
+	db := database.DB
+	cfg := c.Locals("cfg").(*config.Config)
+
+	if err := services.ForgotPassword(input.Email, db, cfg); err != nil {
+		return utils.InternalServerError(c, err, "Failed to send password reset email")
+	}
+
+	return utils.OKResponse(c, "If the email is registered, a password reset link has been sent", nil)
+}
+
+// ResetPassword godoc
+// @Summary Reset a forgotten password
+// @Description Sets a new password with the token from the password reset email and signs out every session of the user.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param input body ResetPasswordInput true "Reset Password Input"
+// @Success 200 {object} map[string]interface{} "Password reset successfully"
+// @Router /auth/reset-password [post]
+func ResetPassword(c *fiber.Ctx) error {
+	type ResetPasswordInput struct {
+		Token       string `json:"token"`
+		NewPassword string `json:"newPassword"`
+	}
+
+	var input ResetPasswordInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return utils.BadResponse(c, err, "Invalid request")
+	}
+
+	if input.Token == "" || input.NewPassword == "" {
+		return utils.BadResponse(c, nil, "token and newPassword are required")
+	}
+
+	db := database.DB
+
+	if err := services.ResetPassword(input.Token, input.NewPassword, db); err != nil {
+		if errors.Is(err, services.ErrInvalidUserToken) {
+			return utils.BadResponse(c, err, err.Error())
+		}
+		return utils.InternalServerError(c, err, "Failed to reset password")
+	}
+
+	return utils.OKResponse(c, "Password reset successfully", nil)
+}
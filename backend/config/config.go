@@ -2,7 +2,10 @@ package config
 
 import (
 	"log"
+	netmail "net/mail"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 	"golang.org/x/oauth2"
@@ -24,15 +27,73 @@ type database struct {
 }
 
 type jwt struct {
-	JWTSecret    string
-	JWTExpiresIn string
+	JWTSecret           string
+	JWTExpiresIn        string
+	JWTRefreshExpiresIn string
+}
+
+type security struct {
+	EncryptionKey string
+}
+
+type loginProtection struct {
+	MaxEmailFailures int
+	MaxIPFailures    int
+	BackoffAfter     int
+	BackoffBase      time.Duration
+	LockoutDuration  time.Duration
+	FailureWindow    time.Duration
+}
+
+type rateLimit struct {
+	Store        string
+	Max          int
+	Duration     time.Duration
+	AuthMax      int
+	AuthDuration time.Duration
+}
+
+type admin struct {
+	APIKey string
+}
+
+type app struct {
+	Environment                string
+	PublicURL                  string
+	ClientURL                  string
+	RequireVerifiedEmail       bool
+	AccountDeletionGracePeriod time.Duration
+	Timezone                   string
+}
+
+type mail struct {
+	Driver       string
+	From         string
+	FromAddress  string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	OutboxDir    string
+}
+
+type oauth struct {
+	UserInfoURL string
+	StubAddress string
 }
 
 type Config struct {
 	ServerConfig      serverConfig
+	App               app
+	Mail              mail
 	GoogleOauthConfig *oauth2.Config
+	OAuth             oauth
 	Database          database
 	JWT               jwt
+	Security          security
+	LoginProtection   loginProtection
+	RateLimit         rateLimit
+	Admin             admin
 }
 
 func parseEnv(key string, defaultValue string) string {
@@ -48,24 +109,116 @@ func parseEnv(key string, defaultValue string) string {
 	return envValue
 }
 
+// useOAuthStub points the Google OAuth config at the local stub provider so the login flow can run
+// without reaching Google.
+func (c *Config) useOAuthStub() {
+	if c.OAuth.StubAddress == "" {
+		return
+	}
+
+	baseURL := "http://" + c.OAuth.StubAddress
+	c.GoogleOauthConfig.Endpoint = oauth2.Endpoint{
+		AuthURL:   baseURL + "/authorize",
+		TokenURL:  baseURL + "/token",
+		AuthStyle: oauth2.AuthStyleInParams,
+	}
+	c.OAuth.UserInfoURL = baseURL + "/userinfo"
+
+	log.Printf("Google OAuth is using the stub provider at %s", baseURL)
+}
+
+// defaultMailDriver only logs emails during development. Everywhere else the log would hold live
+// verification and password reset tokens.
+func defaultMailDriver(environment string) string {
+	if environment == "development" {
+		return "log"
+	}
+	return "smtp"
+}
+
+// checkMail stops the server when emails could not be delivered, instead of failing on every send
+// later. MAIL_FROM is parsed once here; its bare address is the SMTP envelope sender.
+func (c *Config) checkMail() {
+	switch c.Mail.Driver {
+	case "smtp":
+		if c.Mail.SMTPHost == "" {
+			log.Fatal("SMTP_HOST is required when MAIL_DRIVER is smtp")
+		}
+	case "file":
+	case "log":
+		if c.App.Environment != "development" {
+			log.Fatal("MAIL_DRIVER=log writes tokens to the application log and is only allowed when APP_ENV is development")
+		}
+	default:
+		log.Fatalf("MAIL_DRIVER %q is not one of smtp, file or log", c.Mail.Driver)
+	}
+
+	from, err := netmail.ParseAddress(c.Mail.From)
+	if err != nil {
+		log.Fatalf("MAIL_FROM %q is not a valid address: %v", c.Mail.From, err)
+	}
+	c.Mail.FromAddress = from.Address
+}
+
+func parseIntEnv(key string, defaultValue int) int {
+	value, err := strconv.Atoi(parseEnv(key, strconv.Itoa(defaultValue)))
+	if err != nil {
+		log.Printf("%s is not a number, default value is set.", key)
+		return defaultValue
+	}
+	return value
+}
+
+func parseDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(parseEnv(key, defaultValue.String()))
+	if err != nil {
+		log.Printf("%s is not a duration, default value is set.", key)
+		return defaultValue
+	}
+	return value
+}
+
 func LoadConfig() *Config {
 	err := godotenv.Load(".env")
 	if err != nil {
 		log.Fatal("Error loading .env file")
 	}
 
-	return &Config{
+	environment := parseEnv("APP_ENV", "production")
+
+	cfg := &Config{
 		ServerConfig: serverConfig{
 			Host: parseEnv("HOST", "localhost"),
 			Port: parseEnv("PORT", "8000"),
 		},
+		App: app{
+			Environment:                environment,
+			PublicURL:                  parseEnv("APP_URL", "http://localhost:8000"),
+			ClientURL:                  parseEnv("CLIENT_ORIGIN", "http://localhost:3000"),
+			RequireVerifiedEmail:       parseEnv("REQUIRE_EMAIL_VERIFICATION", "false") == "true",
+			AccountDeletionGracePeriod: parseDurationEnv("ACCOUNT_DELETION_GRACE_PERIOD", 30*24*time.Hour),
+			Timezone:                   parseEnv("APP_TIMEZONE", "Asia/Kolkata"),
+		},
+		Mail: mail{
+			Driver:       parseEnv("MAIL_DRIVER", defaultMailDriver(environment)),
+			From:         parseEnv("MAIL_FROM", "Finance Tracker <no-reply@localhost>"),
+			SMTPHost:     os.Getenv("SMTP_HOST"),
+			SMTPPort:     parseEnv("SMTP_PORT", "587"),
+			SMTPUsername: os.Getenv("SMTP_USERNAME"),
+			SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+			OutboxDir:    parseEnv("MAIL_OUTBOX_DIR", "tmp/mail"),
+		},
 		GoogleOauthConfig: &oauth2.Config{
 			RedirectURL:  parseEnv("GOOGLE_OAUTH_REDIRECT_URL", ""),
 			ClientID:     parseEnv("GOOGLE_CLIENT_ID", ""),
 			ClientSecret: parseEnv("GOOGLE_CLIENT_SECRET", ""),
-			Scopes:       []string{"https://www.googleapis.com/auth/userinfo.email", "https://www.googleapis.com/auth/userinfo.profile"},
+			Scopes:       []string{"openid", "email", "profile"},
 			Endpoint:     google.Endpoint,
 		},
+		OAuth: oauth{
+			UserInfoURL: parseEnv("GOOGLE_USERINFO_URL", "https://openidconnect.googleapis.com/v1/userinfo"),
+			StubAddress: os.Getenv("OAUTH_STUB_ADDR"),
+		},
 		Database: database{
 			DBHost:     parseEnv("DB_HOST", "localhost"),
 			DBUser:     parseEnv("DB_USER", "postgres"),
@@ -75,8 +228,35 @@ func LoadConfig() *Config {
 			DBSSMode:   parseEnv("DB_SSL_MODE", "disable"),
 		},
 		JWT: jwt{
-			JWTSecret:    parseEnv("JWT_SECRET", "secret"),
-			JWTExpiresIn: parseEnv("JWT_EXPIRES_IN", "1h"),
+			JWTSecret:           parseEnv("JWT_SECRET", "secret"),
+			JWTExpiresIn:        parseEnv("JWT_EXPIRES_IN", "15m"),
+			JWTRefreshExpiresIn: parseEnv("JWT_REFRESH_EXPIRES_IN", "720h"),
+		},
+		Security: security{
+			EncryptionKey: os.Getenv("ENCRYPTION_KEY"),
+		},
+		LoginProtection: loginProtection{
+			MaxEmailFailures: parseIntEnv("LOGIN_MAX_FAILURES", 5),
+			MaxIPFailures:    parseIntEnv("LOGIN_MAX_IP_FAILURES", 20),
+			BackoffAfter:     parseIntEnv("LOGIN_BACKOFF_AFTER", 3),
+			BackoffBase:      parseDurationEnv("LOGIN_BACKOFF_BASE", time.Second),
+			LockoutDuration:  parseDurationEnv("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+			FailureWindow:    parseDurationEnv("LOGIN_FAILURE_WINDOW", time.Hour),
+		},
+		RateLimit: rateLimit{
+			Store:        parseEnv("RATE_LIMITER_STORE", "memory"),
+			Max:          parseIntEnv("RATE_LIMITER_MAX", 100),
+			Duration:     time.Duration(parseIntEnv("RATE_LIMITER_DURATION_MINUTES", 1)) * time.Minute,
+			AuthMax:      parseIntEnv("AUTH_RATE_LIMITER_MAX", 10),
+			AuthDuration: time.Duration(parseIntEnv("AUTH_RATE_LIMITER_DURATION_MINUTES", 1)) * time.Minute,
+		},
+		Admin: admin{
+			APIKey: parseEnv("ADMIN_API_KEY", ""),
 		},
 	}
+
+	cfg.checkMail()
+	cfg.useOAuthStub()
+
+	return cfg
 }
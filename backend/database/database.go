@@ -13,13 +13,14 @@ import (
 var DB *sql.DB
 
 func Connect(cfg *config.Config) *sql.DB {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=Asia/Kolkata",
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s",
 		cfg.Database.DBHost,
 		cfg.Database.DBUser,
 		cfg.Database.DBPassword,
 		cfg.Database.DBName,
 		cfg.Database.DBPort,
 		cfg.Database.DBSSMode,
+		cfg.App.Timezone,
 	)
 
 	var err error
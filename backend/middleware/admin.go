@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"crypto/subtle"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+// RequireAdminKey guards operator-only endpoints with the X-Admin-Key header.
+// When ADMIN_API_KEY is not configured every request is rejected.
+func RequireAdminKey(c *fiber.Ctx) error {
+	cfg := c.Locals("cfg").(*config.Config)
+
+	key := c.Get("X-Admin-Key")
+	if cfg.Admin.APIKey == "" || key == "" || subtle.ConstantTimeCompare([]byte(key), []byte(cfg.Admin.APIKey)) != 1 {
+		return utils.Forbidden(c, nil, "Admin access required")
+	}
+
+	return c.Next()
+}
@@ -1,11 +1,13 @@
 package middleware
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
@@ -39,29 +41,29 @@ func DeserializeUser(c *fiber.Ctx) error {
 		return utils.UnauthorizedAccess(c, err, "Invalid token")
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userID := claims["user_id"].(string)
-
-		db := database.DB
-
-		jwtToken, err := repository.GetJwtTokenByToken(db, tokenString)
-		if err != nil {
-			return utils.UnauthorizedAccess(c, err, "Invalid token")
-		}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return utils.UnauthorizedAccess(c, err, "Invalid token")
+	}
 
-		if jwtToken == nil {
-			return utils.UnauthorizedAccess(c, err, "Invalid token")
-		} else if jwtToken.ExpiresAt.Before(time.Now().In(utils.LOC)) {
-			err := repository.DeleteJwtToken(db, tokenString)
-			if err != nil {
-				return utils.UnauthorizedAccess(c, err, "Invalid token")
-			}
-		}
+	// Challenge tokens from a two-factor login are signed with the same key but are not access tokens.
+	if claims["typ"] != utils.AccessTokenType {
+		return utils.UnauthorizedAccess(c, nil, "Invalid token")
+	}
 
-		c.Locals("user_id", userID)
-		return c.Next()
+	userID, _ := claims["user_id"].(string)
+	sessionID, err := uuid.Parse(fmt.Sprint(claims["session_id"]))
+	if userID == "" || err != nil {
+		return utils.UnauthorizedAccess(c, err, "Invalid token")
 	}
 
-	return utils.UnauthorizedAccess(c, err, "Invalid token")
+	// Access tokens stop working as soon as their session is revoked or expires.
+	session, err := repository.GetSessionByID(sessionID, database.DB)
+	if err != nil || session == nil || session.UserID.String() != userID || !session.IsActive(time.Now().In(utils.LOC)) {
+		return utils.UnauthorizedAccess(c, err, "Invalid token")
+	}
 
+	c.Locals("user_id", userID)
+	c.Locals("session_id", sessionID.String())
+	return c.Next()
 }
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"database/sql"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/pkg/ratelimit"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+// NewRateLimitStore returns the bucket store selected by RATE_LIMITER_STORE. The Postgres store
+// shares limits between server instances.
+func NewRateLimitStore(cfg *config.Config, db *sql.DB) ratelimit.Store {
+	switch cfg.RateLimit.Store {
+	case "postgres":
+		return ratelimit.NewPostgresStore(db)
+	case "memory":
+		return ratelimit.NewMemoryStore()
+	default:
+		log.Printf("Unknown rate limiter store %q, using memory.", cfg.RateLimit.Store)
+		return ratelimit.NewMemoryStore()
+	}
+}
+
+// RateLimiter limits requests with a token bucket per user, or per client IP when no user has
+// been authenticated yet, so it must come after DeserializeUser to count by user. Routes sharing
+// a name share buckets. A limit of zero disables the limiter.
+func RateLimiter(name string, limit ratelimit.Limit, store ratelimit.Store) fiber.Handler {
+	if limit.Max <= 0 || limit.Period <= 0 {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		key := name + ":ip:" + c.IP()
+		if userID, ok := c.Locals("user_id").(string); ok && userID != "" {
+			key = name + ":user:" + userID
+		}
+
+		result, err := store.Take(key, limit, time.Now())
+		if err != nil {
+			// A broken store should not take the API down with it.
+			log.Printf("Rate limiter error: %v", err)
+			return c.Next()
+		}
+
+		c.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			c.Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			return utils.TooManyRequests(c, "Too many requests, please try again later")
+		}
+
+		return c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -25,10 +25,11 @@ type Account struct {
 	UserID    uuid.UUID   `json:"userId"`
 	Name      string      `json:"name"`
 	Type      AccountType `json:"type"`
-	Balance   float64     `json:"balance"` // See note on NUMERIC type above
+	Balance   Money       `json:"balance"`
 	IsActive  bool        `json:"isActive"`
 	CreatedAt time.Time   `json:"createdAt"`
 	UpdatedAt time.Time   `json:"updatedAt"`
+	Currency  Currency    `json:"currency"`
 }
 
-var AccountColumns = "id, user_id, name, type, balance, is_active, created_at, updated_at"
+var AccountColumns = "id, user_id, name, type, balance, is_active, created_at, updated_at, currency"
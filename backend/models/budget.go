@@ -6,14 +6,137 @@ import (
 	"github.com/google/uuid"
 )
 
-// Budget corresponds to the `budgets` table.
+// BudgetPeriod defines how often a budget's limit resets.
+type BudgetPeriod string
+
+const (
+	BudgetPeriodWeekly    BudgetPeriod = "weekly"
+	BudgetPeriodMonthly   BudgetPeriod = "monthly"
+	BudgetPeriodQuarterly BudgetPeriod = "quarterly"
+	BudgetPeriodYearly    BudgetPeriod = "yearly"
+	BudgetPeriodCustom    BudgetPeriod = "custom"
+)
+
+func (p BudgetPeriod) IsValid() bool {
+	switch p {
+	case BudgetPeriodWeekly, BudgetPeriodMonthly, BudgetPeriodQuarterly, BudgetPeriodYearly, BudgetPeriodCustom:
+		return true
+	}
+	return false
+}
+
+// Budget corresponds to the `budgets` table. Limit is stored in the `amount` column and is
+// never modified by transactions, spending is always derived from the transactions table.
+// AlertThresholds are percentages of a period's available amount that raise a notification.
 type Budget struct {
-	ID        uuid.UUID `json:"id"`
-	UserID    uuid.UUID `json:"userId"`
-	Name      string    `json:"name"`
-	Amount    float64   `json:"amount"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID              uuid.UUID     `json:"id"`
+	UserID          uuid.UUID     `json:"userId"`
+	Name            string        `json:"name"`
+	Limit           Money         `json:"limit"`
+	CreatedAt       time.Time     `json:"createdAt"`
+	UpdatedAt       time.Time     `json:"updatedAt"`
+	Period          BudgetPeriod  `json:"period"`
+	StartDate       time.Time     `json:"startDate"`
+	EndDate         *time.Time    `json:"endDate,omitempty"`
+	CategoryID      uuid.NullUUID `json:"categoryId"`
+	Rollover        bool          `json:"rollover"`
+	AlertThresholds []int64       `json:"alertThresholds"`
+}
+
+// DefaultBudgetAlertThresholds is used when a budget is created without explicit thresholds.
+var DefaultBudgetAlertThresholds = []int64{50, 80, 100}
+
+var BudgetColumns = "id, user_id, name, amount, created_at, updated_at, period, start_date, end_date, category_id, rollover, alert_thresholds"
+
+// BudgetPeriodStatus is the computed state of one budget period.
+type BudgetPeriodStatus struct {
+	PeriodStart time.Time `json:"periodStart"`
+	PeriodEnd   time.Time `json:"periodEnd"`
+	Limit       Money     `json:"limit"`
+	CarriedOver Money     `json:"carriedOver"`
+	Spent       Money     `json:"spent"`
+	Remaining   Money     `json:"remaining"`
+	PercentUsed float64   `json:"percentUsed"`
+}
+
+// BudgetWithStatus is a budget together with the status of its current period.
+type BudgetWithStatus struct {
+	Budget
+	Current BudgetPeriodStatus `json:"current"`
+}
+
+// periodMonths returns the length of a calendar based period, or 0 for weekly and custom budgets.
+func (p BudgetPeriod) periodMonths() int {
+	switch p {
+	case BudgetPeriodMonthly:
+		return 1
+	case BudgetPeriodQuarterly:
+		return 3
+	case BudgetPeriodYearly:
+		return 12
+	}
+	return 0
+}
+
+// PeriodBounds returns the first and last day (inclusive) of the period with the given index,
+// counting from the budget's start date. Custom budgets only have period 0.
+func (b Budget) PeriodBounds(index int) (time.Time, time.Time) {
+	start := b.StartDate
+	switch {
+	case b.Period == BudgetPeriodCustom:
+		if b.EndDate != nil {
+			return start, *b.EndDate
+		}
+		return start, start
+	case b.Period == BudgetPeriodWeekly:
+		periodStart := start.AddDate(0, 0, 7*index)
+		return periodStart, periodStart.AddDate(0, 0, 6)
+	default:
+		months := b.Period.periodMonths()
+		return AddMonthsClamped(start, months*index), AddMonthsClamped(start, months*(index+1)).AddDate(0, 0, -1)
+	}
 }
 
-var BudgetColumns = "id, user_id, name, amount, created_at, updated_at"
+// PeriodIndexAt returns the index of the period containing date, or -1 when date is before the
+// budget starts. Custom budgets stay in period 0 once started.
+func (b Budget) PeriodIndexAt(date time.Time) int {
+	if date.Before(b.StartDate) {
+		return -1
+	}
+
+	switch b.Period {
+	case BudgetPeriodCustom:
+		return 0
+	case BudgetPeriodWeekly:
+		return int(date.Sub(b.StartDate).Hours()/24) / 7
+	}
+
+	months := b.Period.periodMonths()
+	elapsed := (date.Year()-b.StartDate.Year())*12 + int(date.Month()) - int(b.StartDate.Month())
+	index := elapsed / months
+	for index > 0 {
+		if periodStart, _ := b.PeriodBounds(index); !periodStart.After(date) {
+			break
+		}
+		index--
+	}
+	for {
+		if nextStart, _ := b.PeriodBounds(index + 1); nextStart.After(date) {
+			break
+		}
+		index++
+	}
+	return index
+}
+
+// AddMonthsClamped adds months to t, clamping the day to the end of the target month
+// so that e.g. 31 January plus one month is 28 or 29 February rather than early March.
+func AddMonthsClamped(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location()).AddDate(0, months, 0)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return first.AddDate(0, 0, day-1)
+}
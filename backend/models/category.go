@@ -2,10 +2,14 @@ package models
 
 import "github.com/google/uuid"
 
+// Category corresponds to the `categories` table.
+// Categories without a UserID are seeded system defaults shared by every user and are read-only.
 type Category struct {
-	ID   uuid.UUID       `json:"id"`
-	Name string          `json:"name"`
-	Type TransactionType `json:"type"`
+	ID       uuid.UUID       `json:"id"`
+	Name     string          `json:"name"`
+	Type     TransactionType `json:"type"`
+	UserID   uuid.NullUUID   `json:"userId"`
+	IsSystem bool            `json:"isSystem"`
 }
 
-var CategoryColumns = "id, name, type"
+var CategoryColumns = "id, name, type, user_id"
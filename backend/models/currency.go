@@ -0,0 +1,23 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Currency is an ISO 4217 three-letter currency code such as INR, USD or EUR.
+type Currency string
+
+// DefaultCurrency is used for users and accounts created without an explicit currency.
+const DefaultCurrency Currency = "INR"
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// NormalizeCurrency trims and upper-cases a currency code supplied by a client.
+func NormalizeCurrency(code string) Currency {
+	return Currency(strings.ToUpper(strings.TrimSpace(code)))
+}
+
+func (c Currency) IsValid() bool {
+	return currencyPattern.MatchString(string(c))
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	errInvalidDecimal    = errors.New("invalid decimal")
+	errDecimalOutOfRange = errors.New("decimal out of range")
+)
+
+// parseFixed parses a plain decimal string into an integer scaled by 10^scale.
+// Digits beyond the requested scale are rounded half away from zero.
+func parseFixed(value string, scale int) (int64, error) {
+	s := strings.TrimSpace(value)
+	if s == "" {
+		return 0, errInvalidDecimal
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return 0, errInvalidDecimal
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+
+	for _, part := range []string{intPart, fracPart} {
+		for _, r := range part {
+			if r < '0' || r > '9' {
+				return 0, errInvalidDecimal
+			}
+		}
+	}
+
+	units, err := strconv.ParseInt(intPart, 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, errDecimalOutOfRange
+	}
+	if err != nil {
+		return 0, errInvalidDecimal
+	}
+
+	roundUp := false
+	if len(fracPart) > scale {
+		roundUp = fracPart[scale] >= '5'
+		fracPart = fracPart[:scale]
+	}
+	fracPart += strings.Repeat("0", scale-len(fracPart))
+
+	var frac int64
+	if scale > 0 {
+		frac, err = strconv.ParseInt(fracPart, 10, 64)
+		if err != nil {
+			return 0, errInvalidDecimal
+		}
+	}
+
+	factor := pow10(scale)
+	if units > (math.MaxInt64-frac)/factor {
+		return 0, errDecimalOutOfRange
+	}
+
+	amount := units*factor + frac
+	if roundUp {
+		if amount == math.MaxInt64 {
+			return 0, errDecimalOutOfRange
+		}
+		amount++
+	}
+
+	if negative {
+		amount = -amount
+	}
+
+	return amount, nil
+}
+
+// formatFixed renders an integer scaled by 10^scale as a decimal string, trimming
+// trailing zeros but always keeping at least minDecimals fractional digits.
+func formatFixed(value int64, scale int, minDecimals int) string {
+	sign := ""
+	abs := uint64(value)
+	if value < 0 {
+		sign = "-"
+		abs = uint64(-(value + 1)) + 1
+	}
+
+	factor := uint64(pow10(scale))
+	units := abs / factor
+	if scale == 0 {
+		return fmt.Sprintf("%s%d", sign, units)
+	}
+
+	frac := fmt.Sprintf("%0*d", scale, abs%factor)
+	frac = strings.TrimRight(frac, "0")
+	for len(frac) < minDecimals {
+		frac += "0"
+	}
+
+	if frac == "" {
+		return fmt.Sprintf("%s%d", sign, units)
+	}
+	return fmt.Sprintf("%s%d.%s", sign, units, frac)
+}
+
+// roundQuotient divides n by d rounding half away from zero.
+func roundQuotient(n *big.Int, d *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(n, d, new(big.Int))
+	twice := new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2))
+	if twice.Cmp(new(big.Int).Abs(d)) >= 0 {
+		if n.Sign()*d.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient
+}
+
+func pow10(n int) int64 {
+	result := int64(1)
+	for i := 0; i < n; i++ {
+		result *= 10
+	}
+	return result
+}
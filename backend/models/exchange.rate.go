@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExchangeRate corresponds to the `exchange_rates` table.
+// One unit of BaseCurrency is worth Rate units of QuoteCurrency on RateDate.
+type ExchangeRate struct {
+	ID            uuid.UUID `json:"id"`
+	BaseCurrency  Currency  `json:"baseCurrency"`
+	QuoteCurrency Currency  `json:"quoteCurrency"`
+	Rate          Rate      `json:"rate"`
+	RateDate      time.Time `json:"rateDate"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+var ExchangeRateColumns = "id, base_currency, quote_currency, rate, rate_date, created_at, updated_at"
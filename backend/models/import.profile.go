@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AmountSign tells how a single amount column marks expenses.
+type AmountSign string
+
+const (
+	AmountSignExpenseNegative AmountSign = "expense_negative"
+	AmountSignExpensePositive AmountSign = "expense_positive"
+)
+
+func (s AmountSign) IsValid() bool {
+	return s == AmountSignExpenseNegative || s == AmountSignExpensePositive
+}
+
+// ImportMapping describes how the columns of a bank's CSV file map onto transactions. Columns are
+// named as in the file's header row. Amounts come either from one signed AmountColumn or from
+// separate DebitColumn and CreditColumn columns holding expenses and income.
+type ImportMapping struct {
+	Delimiter         string        `json:"delimiter"`
+	SkipRows          int           `json:"skipRows"`
+	DateColumn        string        `json:"dateColumn"`
+	DateFormat        DateFormat    `json:"dateFormat"`
+	DescriptionColumn string        `json:"descriptionColumn"`
+	AmountColumn      string        `json:"amountColumn"`
+	DebitColumn       string        `json:"debitColumn"`
+	CreditColumn      string        `json:"creditColumn"`
+	AmountSign        AmountSign    `json:"amountSign"`
+	DecimalSeparator  string        `json:"decimalSeparator"`
+	NoteColumn        string        `json:"noteColumn"`
+	CategoryColumn    string        `json:"categoryColumn"`
+	IncomeCategoryID  uuid.NullUUID `json:"incomeCategoryId"`
+	ExpenseCategoryID uuid.NullUUID `json:"expenseCategoryId"`
+}
+
+// ImportProfile corresponds to the `import_profiles` table, a column mapping saved by a user.
+type ImportProfile struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"userId"`
+	Name   string    `json:"name"`
+	ImportMapping
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+var ImportProfileColumns = "id, user_id, name, delimiter, skip_rows, date_column, date_format, description_column, amount_column, debit_column, credit_column, amount_sign, decimal_separator, note_column, category_column, income_category_id, expense_category_id, created_at, updated_at"
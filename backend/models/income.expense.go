@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TimeSeriesInterval is the length of the periods a time series is bucketed into.
+type TimeSeriesInterval string
+
+const (
+	TimeSeriesDay   TimeSeriesInterval = "day"
+	TimeSeriesWeek  TimeSeriesInterval = "week"
+	TimeSeriesMonth TimeSeriesInterval = "month"
+	TimeSeriesYear  TimeSeriesInterval = "year"
+)
+
+func (i TimeSeriesInterval) IsValid() bool {
+	switch i {
+	case TimeSeriesDay, TimeSeriesWeek, TimeSeriesMonth, TimeSeriesYear:
+		return true
+	}
+	return false
+}
+
+// PeriodStart returns the first day of the period containing date. Weeks begin on weekStart.
+func (i TimeSeriesInterval) PeriodStart(date time.Time, weekStart time.Weekday) time.Time {
+	year, month, day := date.Date()
+	switch i {
+	case TimeSeriesWeek:
+		offset := (int(date.Weekday()) - int(weekStart) + 7) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, date.Location())
+	case TimeSeriesMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, date.Location())
+	case TimeSeriesYear:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, date.Location())
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, date.Location())
+	}
+}
+
+// AddPeriods moves a period start by count periods.
+func (i TimeSeriesInterval) AddPeriods(start time.Time, count int) time.Time {
+	switch i {
+	case TimeSeriesWeek:
+		return start.AddDate(0, 0, 7*count)
+	case TimeSeriesMonth:
+		return start.AddDate(0, count, 0)
+	case TimeSeriesYear:
+		return start.AddDate(count, 0, 0)
+	default:
+		return start.AddDate(0, 0, count)
+	}
+}
+
+// TimeSeriesGroupBy splits a time series into one series per account or category.
+type TimeSeriesGroupBy string
+
+const (
+	TimeSeriesByAccount  TimeSeriesGroupBy = "account"
+	TimeSeriesByCategory TimeSeriesGroupBy = "category"
+)
+
+func (g TimeSeriesGroupBy) IsValid() bool {
+	return g == TimeSeriesByAccount || g == TimeSeriesByCategory
+}
+
+// DailyIncomeExpense is the income and expense of one day, and of one account or category when
+// the totals are grouped.
+type DailyIncomeExpense struct {
+	Date      time.Time
+	GroupID   uuid.NullUUID
+	GroupName string
+	Income    Money
+	Expense   Money
+}
+
+// IncomeExpensePoint is the income, expense and net of one period, from PeriodStart to
+// PeriodEnd inclusive.
+type IncomeExpensePoint struct {
+	PeriodStart time.Time `json:"periodStart"`
+	PeriodEnd   time.Time `json:"periodEnd"`
+	Income      Money     `json:"income"`
+	Expense     Money     `json:"expense"`
+	Net         Money     `json:"net"`
+}
+
+// IncomeExpenseSeries is the time series of one account or category.
+type IncomeExpenseSeries struct {
+	ID     uuid.NullUUID        `json:"id"`
+	Name   string               `json:"name"`
+	Points []IncomeExpensePoint `json:"points"`
+}
+
+// IncomeExpenseTimeSeries is income against expense per period in the user's base currency.
+// Points holds the totals, Series the split by account or category when one was asked for.
+type IncomeExpenseTimeSeries struct {
+	Currency  Currency              `json:"currency"`
+	Interval  TimeSeriesInterval    `json:"interval"`
+	GroupBy   TimeSeriesGroupBy     `json:"groupBy,omitempty"`
+	StartDate time.Time             `json:"startDate"`
+	EndDate   time.Time             `json:"endDate"`
+	Points    []IncomeExpensePoint  `json:"points"`
+	Series    []IncomeExpenseSeries `json:"series,omitempty"`
+}
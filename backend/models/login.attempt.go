@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// LoginAttemptScope defines what failed logins are counted against.
+type LoginAttemptScope string
+
+const (
+	LoginAttemptScopeEmail LoginAttemptScope = "email"
+	LoginAttemptScopeIP    LoginAttemptScope = "ip"
+)
+
+// LoginAttempt corresponds to the `login_attempts` table. It counts recent failed logins for an
+// email address or a client IP. BlockedUntil is set by backoff after repeated failures and by the
+// lockout once the failure limit is reached.
+type LoginAttempt struct {
+	Scope         LoginAttemptScope `json:"scope"`
+	Key           string            `json:"key"`
+	Failures      int               `json:"failures"`
+	LastFailureAt time.Time         `json:"lastFailureAt"`
+	BlockedUntil  *time.Time        `json:"blockedUntil"`
+}
+
+var LoginAttemptColumns = "scope, key, failures, last_failure_at, blocked_until"
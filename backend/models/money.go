@@ -0,0 +1,199 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact fixed-point monetary amount with four decimal places,
+// matching the NUMERIC(19, 4) columns it is stored in. The value is kept as an
+// integer count of ten-thousandths so that repeated additions and subtractions
+// never drift the way float64 arithmetic does. Amounts range from -MaxMoney to
+// MaxMoney, so negating one never overflows.
+type Money int64
+
+// MoneyScale is the number of decimal places kept by Money.
+const MoneyScale = 4
+
+const moneyFactor = 10000
+
+// MaxMoney is the largest amount Money holds, 922337203685477.5807.
+const MaxMoney Money = math.MaxInt64
+
+var (
+	ErrInvalidMoney  = errors.New("invalid monetary amount")
+	ErrMoneyOverflow = errors.New("monetary amount out of range")
+)
+
+// NewMoney builds a Money value from a whole number of currency units.
+func NewMoney(units int64) Money {
+	return Money(units * moneyFactor)
+}
+
+// ParseMoney parses a decimal string such as "1250", "-12.5" or "0.0001".
+// Digits beyond the fourth decimal place are rounded half away from zero.
+// Amounts beyond MaxMoney fail with ErrMoneyOverflow.
+func ParseMoney(value string) (Money, error) {
+	amount, err := parseFixed(value, MoneyScale)
+	if errors.Is(err, errDecimalOutOfRange) {
+		return 0, ErrMoneyOverflow
+	}
+	if err != nil {
+		return 0, ErrInvalidMoney
+	}
+	return Money(amount), nil
+}
+
+// Add returns m + other. It wraps around beyond MaxMoney, so account balances
+// use AddChecked.
+func (m Money) Add(other Money) Money {
+	return m + other
+}
+
+// Sub returns m - other. It wraps around beyond MaxMoney, so account balances
+// use SubChecked.
+func (m Money) Sub(other Money) Money {
+	return m - other
+}
+
+// AddChecked returns m + other, or ErrMoneyOverflow when the sum is beyond MaxMoney.
+func (m Money) AddChecked(other Money) (Money, error) {
+	if (other > 0 && m > MaxMoney-other) || (other < 0 && m < -MaxMoney-other) {
+		return 0, ErrMoneyOverflow
+	}
+	return m + other, nil
+}
+
+// SubChecked returns m - other, or ErrMoneyOverflow when the difference is beyond MaxMoney.
+func (m Money) SubChecked(other Money) (Money, error) {
+	if (other < 0 && m > MaxMoney+other) || (other > 0 && m < -MaxMoney+other) {
+		return 0, ErrMoneyOverflow
+	}
+	return m - other, nil
+}
+
+func (m Money) Neg() Money {
+	return -m
+}
+
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// Cmp returns -1, 0 or 1 depending on whether m is less than, equal to or greater than other.
+func (m Money) Cmp(other Money) int {
+	switch {
+	case m < other:
+		return -1
+	case m > other:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (m Money) IsZero() bool {
+	return m == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m < 0
+}
+
+func (m Money) IsPositive() bool {
+	return m > 0
+}
+
+// String formats the amount with at least two and at most four decimal places, e.g. "1000.00" or "12.3456".
+func (m Money) String() string {
+	return formatFixed(int64(m), MoneyScale, 2)
+}
+
+// Convert multiplies the amount by an exchange rate, rounding half away from zero to four decimal
+// places. It fails with ErrMoneyOverflow when the converted amount is beyond MaxMoney.
+func (m Money) Convert(rate Rate) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(int64(rate)))
+	converted := roundQuotient(product, big.NewInt(rateFactor))
+	if converted.CmpAbs(big.NewInt(int64(MaxMoney))) > 0 {
+		return 0, ErrMoneyOverflow
+	}
+	return Money(converted.Int64()), nil
+}
+
+// MarshalJSON encodes the amount as a JSON number with an exact decimal representation.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts either a JSON number or a JSON string holding a decimal amount.
+// The digits are parsed directly so no precision is lost through float64.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	raw := strings.TrimSpace(string(data))
+	if raw == "null" {
+		*m = 0
+		return nil
+	}
+
+	if unquoted, err := strconv.Unquote(raw); err == nil {
+		raw = unquoted
+	}
+
+	if strings.ContainsAny(raw, "eE") {
+		return fmt.Errorf("%w: exponent notation is not supported", ErrInvalidMoney)
+	}
+
+	parsed, err := ParseMoney(raw)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+// Scan implements sql.Scanner for NUMERIC columns.
+func (m *Money) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case []byte:
+		parsed, err := ParseMoney(string(value))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case string:
+		parsed, err := ParseMoney(value)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case int64:
+		if value > int64(MaxMoney)/moneyFactor || value < -int64(MaxMoney)/moneyFactor {
+			return ErrMoneyOverflow
+		}
+		*m = NewMoney(value)
+	case float64:
+		parsed, err := ParseMoney(strconv.FormatFloat(value, 'f', -1, 64))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case nil:
+		return fmt.Errorf("%w: cannot scan NULL into Money", ErrInvalidMoney)
+	default:
+		return fmt.Errorf("%w: cannot scan %T into Money", ErrInvalidMoney, src)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer so the amount is sent to PostgreSQL as an exact decimal string.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
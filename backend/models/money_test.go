@@ -0,0 +1,224 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Money
+		err   error
+	}{
+		{"0", 0, nil},
+		{"1250", 12500000, nil},
+		{"-12.5", -125000, nil},
+		{"+12.5", 125000, nil},
+		{"0.0001", 1, nil},
+		{".5", 5000, nil},
+		{"5.", 50000, nil},
+		{" 42.10 ", 421000, nil},
+		{"0.00005", 1, nil},
+		{"0.00004", 0, nil},
+		{"-0.00005", -1, nil},
+		{"1.99995", 20000, nil},
+		{"922337203685477.5807", MaxMoney, nil},
+		{"-922337203685477.5807", -MaxMoney, nil},
+		{"922337203685477.58065", MaxMoney, nil},
+		{"922337203685477.5808", 0, ErrMoneyOverflow},
+		{"922337203685477.58075", 0, ErrMoneyOverflow},
+		{"-922337203685477.5808", 0, ErrMoneyOverflow},
+		{"922337203685478", 0, ErrMoneyOverflow},
+		{"99999999999999999999", 0, ErrMoneyOverflow},
+		{"", 0, ErrInvalidMoney},
+		{"-", 0, ErrInvalidMoney},
+		{".", 0, ErrInvalidMoney},
+		{"1,5", 0, ErrInvalidMoney},
+		{"1.2.3", 0, ErrInvalidMoney},
+		{"--1", 0, ErrInvalidMoney},
+		{"1e3", 0, ErrInvalidMoney},
+		{"abc", 0, ErrInvalidMoney},
+	}
+
+	for _, test := range tests {
+		got, err := ParseMoney(test.input)
+		if !errors.Is(err, test.err) || (test.err == nil && err != nil) {
+			t.Errorf("ParseMoney(%q) error = %v, want %v", test.input, err, test.err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseMoney(%q) = %d, want %d", test.input, got, test.want)
+		}
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		amount Money
+		want   string
+	}{
+		{0, "0.00"},
+		{1, "0.0001"},
+		{-1, "-0.0001"},
+		{5000, "0.50"},
+		{12500000, "1250.00"},
+		{123456, "12.3456"},
+		{-125000, "-12.50"},
+		{120, "0.012"},
+		{MaxMoney, "922337203685477.5807"},
+		{-MaxMoney, "-922337203685477.5807"},
+	}
+
+	for _, test := range tests {
+		if got := test.amount.String(); got != test.want {
+			t.Errorf("Money(%d).String() = %q, want %q", test.amount, got, test.want)
+		}
+	}
+}
+
+func TestMoneyRoundTrip(t *testing.T) {
+	amounts := []Money{0, 1, -1, 99, 10000, -10000, 123456789, -987654321, MaxMoney, -MaxMoney}
+
+	for _, amount := range amounts {
+		parsed, err := ParseMoney(amount.String())
+		if err != nil || parsed != amount {
+			t.Errorf("ParseMoney(%q) = %d, %v, want %d", amount.String(), parsed, err, amount)
+		}
+
+		data, err := json.Marshal(amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var decoded Money
+		if err := json.Unmarshal(data, &decoded); err != nil || decoded != amount {
+			t.Errorf("json round trip of %s = %d, %v", data, decoded, err)
+		}
+	}
+}
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Money
+		err   error
+	}{
+		{`12.5`, 125000, nil},
+		{`"12.5"`, 125000, nil},
+		{`null`, 0, nil},
+		{`1e3`, 0, ErrInvalidMoney},
+		{`"x"`, 0, ErrInvalidMoney},
+		{`1000000000000000`, 0, ErrMoneyOverflow},
+	}
+
+	for _, test := range tests {
+		var got Money
+		err := json.Unmarshal([]byte(test.input), &got)
+		if !errors.Is(err, test.err) || (test.err == nil && err != nil) {
+			t.Errorf("Unmarshal(%s) error = %v, want %v", test.input, err, test.err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Unmarshal(%s) = %d, want %d", test.input, got, test.want)
+		}
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	tests := []struct {
+		src  interface{}
+		want Money
+		err  error
+	}{
+		{[]byte("12.3400"), 123400, nil},
+		{"-0.0001", -1, nil},
+		{int64(7), 70000, nil},
+		{float64(1.25), 12500, nil},
+		{[]byte("999999999999999.9999"), 0, ErrMoneyOverflow},
+		{int64(922337203685478), 0, ErrMoneyOverflow},
+		{nil, 0, ErrInvalidMoney},
+		{true, 0, ErrInvalidMoney},
+	}
+
+	for _, test := range tests {
+		var got Money
+		err := got.Scan(test.src)
+		if !errors.Is(err, test.err) || (test.err == nil && err != nil) {
+			t.Errorf("Scan(%#v) error = %v, want %v", test.src, err, test.err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Scan(%#v) = %d, want %d", test.src, got, test.want)
+		}
+	}
+}
+
+func TestMoneyAddSubChecked(t *testing.T) {
+	tests := []struct {
+		a, b    Money
+		sum     Money
+		sumErr  error
+		diff    Money
+		diffErr error
+	}{
+		{10000, 5000, 15000, nil, 5000, nil},
+		{-10000, 5000, -5000, nil, -15000, nil},
+		{MaxMoney, 0, MaxMoney, nil, MaxMoney, nil},
+		{MaxMoney, 1, 0, ErrMoneyOverflow, MaxMoney - 1, nil},
+		{MaxMoney, -1, MaxMoney - 1, nil, 0, ErrMoneyOverflow},
+		{-MaxMoney, -1, 0, ErrMoneyOverflow, -MaxMoney + 1, nil},
+		{-MaxMoney, 1, -MaxMoney + 1, nil, 0, ErrMoneyOverflow},
+		{MaxMoney, MaxMoney, 0, ErrMoneyOverflow, 0, nil},
+		{-MaxMoney, MaxMoney, 0, nil, 0, ErrMoneyOverflow},
+		{MaxMoney, -MaxMoney, 0, nil, 0, ErrMoneyOverflow},
+	}
+
+	for _, test := range tests {
+		sum, err := test.a.AddChecked(test.b)
+		if !errors.Is(err, test.sumErr) || (test.sumErr == nil && (err != nil || sum != test.sum)) {
+			t.Errorf("%d.AddChecked(%d) = %d, %v, want %d, %v", test.a, test.b, sum, err, test.sum, test.sumErr)
+		}
+
+		diff, err := test.a.SubChecked(test.b)
+		if !errors.Is(err, test.diffErr) || (test.diffErr == nil && (err != nil || diff != test.diff)) {
+			t.Errorf("%d.SubChecked(%d) = %d, %v, want %d, %v", test.a, test.b, diff, err, test.diff, test.diffErr)
+		}
+	}
+}
+
+func TestMoneyConvert(t *testing.T) {
+	tests := []struct {
+		amount Money
+		rate   string
+		want   Money
+		err    error
+	}{
+		{NewMoney(100), "83.2145", 83214500, nil},
+		{NewMoney(1), "0.012", 120, nil},
+		{1, "0.5", 1, nil},
+		{-1, "0.5", -1, nil},
+		{1, "0.49999999", 0, nil},
+		{MaxMoney, "1", MaxMoney, nil},
+		{-MaxMoney, "1", -MaxMoney, nil},
+		{MaxMoney, "1.00000001", 0, ErrMoneyOverflow},
+		{-MaxMoney, "2", 0, ErrMoneyOverflow},
+	}
+
+	for _, test := range tests {
+		rate, err := ParseRate(test.rate)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := test.amount.Convert(rate)
+		if !errors.Is(err, test.err) || (test.err == nil && err != nil) {
+			t.Errorf("%d.Convert(%s) error = %v, want %v", test.amount, test.rate, err, test.err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%d.Convert(%s) = %d, want %d", test.amount, test.rate, got, test.want)
+		}
+	}
+}
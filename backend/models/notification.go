@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationType identifies what raised a notification.
+type NotificationType string
+
+const (
+	NotificationTypeBudgetAlert NotificationType = "budget_alert"
+)
+
+// Notification corresponds to the `notifications` table. ReferenceID points at the record
+// the notification is about, e.g. the budget for budget alerts.
+type Notification struct {
+	ID          uuid.UUID        `json:"id"`
+	UserID      uuid.UUID        `json:"userId"`
+	Type        NotificationType `json:"type"`
+	Title       string           `json:"title"`
+	Message     string           `json:"message"`
+	ReferenceID uuid.NullUUID    `json:"referenceId"`
+	IsRead      bool             `json:"isRead"`
+	CreatedAt   time.Time        `json:"createdAt"`
+	ReadAt      *time.Time       `json:"readAt,omitempty"`
+}
+
+var NotificationColumns = "id, user_id, type, title, message, reference_id, is_read, created_at, read_at"
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Rate is an exact exchange rate with eight decimal places, matching the
+// NUMERIC(19, 8) column it is stored in.
+type Rate int64
+
+// RateScale is the number of decimal places kept by Rate.
+const RateScale = 8
+
+const rateFactor = 100000000
+
+var ErrInvalidRate = errors.New("invalid exchange rate")
+
+// OneRate is the identity rate used when no conversion is needed.
+const OneRate Rate = rateFactor
+
+// ParseRate parses a decimal string such as "83.2145" into a Rate.
+func ParseRate(value string) (Rate, error) {
+	rate, err := parseFixed(value, RateScale)
+	if err != nil {
+		return 0, ErrInvalidRate
+	}
+	return Rate(rate), nil
+}
+
+// Inverse returns 1/r rounded to eight decimal places.
+func (r Rate) Inverse() Rate {
+	if r == 0 {
+		return 0
+	}
+	numerator := new(big.Int).Mul(big.NewInt(rateFactor), big.NewInt(rateFactor))
+	return Rate(roundQuotient(numerator, big.NewInt(int64(r))).Int64())
+}
+
+func (r Rate) IsPositive() bool {
+	return r > 0
+}
+
+func (r Rate) String() string {
+	return formatFixed(int64(r), RateScale, 1)
+}
+
+// MarshalJSON encodes the rate as a JSON number with an exact decimal representation.
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON accepts either a JSON number or a JSON string holding a decimal rate.
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	raw := strings.TrimSpace(string(data))
+	if unquoted, err := strconv.Unquote(raw); err == nil {
+		raw = unquoted
+	}
+
+	parsed, err := ParseRate(raw)
+	if err != nil {
+		return err
+	}
+
+	*r = parsed
+	return nil
+}
+
+// Scan implements sql.Scanner for NUMERIC columns.
+func (r *Rate) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case []byte:
+		parsed, err := ParseRate(string(value))
+		if err != nil {
+			return err
+		}
+		*r = parsed
+	case string:
+		parsed, err := ParseRate(value)
+		if err != nil {
+			return err
+		}
+		*r = parsed
+	default:
+		return fmt.Errorf("%w: cannot scan %T into Rate", ErrInvalidRate, src)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer so the rate is sent to PostgreSQL as an exact decimal string.
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecurringTransactionException corresponds to the `recurring_transaction_exceptions` table. It
+// changes a single occurrence of a recurring transaction: Skip drops it, otherwise Amount replaces
+// the rule's amount for that occurrence.
+type RecurringTransactionException struct {
+	RecurringTransactionID uuid.UUID `json:"recurringTransactionId"`
+	OccurrenceDate         time.Time `json:"occurrenceDate"`
+	Skip                   bool      `json:"skip"`
+	Amount                 *Money    `json:"amount,omitempty"`
+	CreatedAt              time.Time `json:"createdAt"`
+	UpdatedAt              time.Time `json:"updatedAt"`
+}
+
+var RecurringTransactionExceptionColumns = "recurring_transaction_id, occurrence_date, skip, amount, created_at, updated_at"
+
+// RecurringOccurrencePreview is one upcoming occurrence as the processor will post it.
+type RecurringOccurrencePreview struct {
+	Index      int       `json:"index"`
+	Date       time.Time `json:"date"`
+	Amount     Money     `json:"amount"`
+	Skipped    bool      `json:"skipped"`
+	Overridden bool      `json:"overridden"`
+}
@@ -5,16 +5,26 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/pkg/recurrence"
 )
 
 // RecurringTransaction corresponds to the `recurring_transactions` table.
 type RecurringFrequency string
 
 const (
-	Monthly RecurringFrequency = "monthly"
-	Yearly  RecurringFrequency = "yearly"
+	Daily     RecurringFrequency = "daily"
+	Weekly    RecurringFrequency = "weekly"
+	Biweekly  RecurringFrequency = "biweekly"
+	Monthly   RecurringFrequency = "monthly"
+	Quarterly RecurringFrequency = "quarterly"
+	Yearly    RecurringFrequency = "yearly"
 )
 
+// RecurringTransaction repeats every Interval units of RecurringFrequency starting at StartDate.
+// RecurringDate is the day of month for monthly, quarterly and yearly rules and Weekday (0 is
+// Sunday) the day of week for weekly and biweekly rules. LastRunAt is the last day the processor
+// has handled and NextRunAt, the next day it will post a transaction for, is computed, not stored.
+// Paused rules post nothing and have no NextRunAt.
 type RecurringTransaction struct {
 	ID                 uuid.UUID          `json:"id"`
 	UserID             uuid.UUID          `json:"userId"`
@@ -22,13 +32,42 @@ type RecurringTransaction struct {
 	CategoryID         uuid.UUID          `json:"categoryId"`
 	BudgetID           uuid.NullUUID      `json:"budgetId,omitempty"`
 	Description        string             `json:"description"`
-	Amount             float64            `json:"amount"`
+	Amount             Money              `json:"amount"`
 	Type               TransactionType    `json:"type"`
 	Note               sql.NullString     `json:"note,omitempty"`
 	RecurringFrequency RecurringFrequency `json:"recurringFrequency"`
 	RecurringDate      int                `json:"recurringDate"`
 	CreatedAt          time.Time          `json:"createdAt"`
 	UpdatedAt          time.Time          `json:"updatedAt"`
+	Interval           int                `json:"interval"`
+	Weekday            *int               `json:"weekday,omitempty"`
+	LastDayOfMonth     bool               `json:"lastDayOfMonth"`
+	StartDate          time.Time          `json:"startDate"`
+	EndDate            *time.Time         `json:"endDate,omitempty"`
+	MaxOccurrences     *int               `json:"maxOccurrences,omitempty"`
+	LastRunAt          *time.Time         `json:"lastRunAt,omitempty"`
+	IsPaused           bool               `json:"isPaused"`
+	NextRunAt          *time.Time         `json:"nextRunAt"`
 }
 
-var RecurringTransactionColumns = "id, user_id, account_id, category_id, budget_id, description, amount, type, note, recurring_frequency, recurring_date, created_at, updated_at"
+var RecurringTransactionColumns = "id, user_id, account_id, category_id, budget_id, description, amount, type, note, recurring_frequency, recurring_date, created_at, updated_at, recurrence_interval, weekday, last_day_of_month, start_date, end_date, max_occurrences, last_run_at, is_paused"
+
+// Rule returns the recurrence rule described by the transaction's schedule columns.
+func (rt RecurringTransaction) Rule() recurrence.Rule {
+	rule := recurrence.Rule{
+		Frequency:      recurrence.Frequency(rt.RecurringFrequency),
+		Interval:       rt.Interval,
+		DayOfMonth:     rt.RecurringDate,
+		LastDayOfMonth: rt.LastDayOfMonth,
+		StartDate:      rt.StartDate,
+		EndDate:        rt.EndDate,
+		MaxOccurrences: rt.MaxOccurrences,
+	}
+
+	if rt.Weekday != nil {
+		weekday := time.Weekday(*rt.Weekday)
+		rule.Weekday = &weekday
+	}
+
+	return rule
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecurringTransactionRun corresponds to the `recurring_transaction_runs` table, the ledger of
+// occurrences already posted for a recurring transaction. Each occurrence date is posted at most once.
+type RecurringTransactionRun struct {
+	RecurringTransactionID uuid.UUID     `json:"recurringTransactionId"`
+	OccurrenceDate         time.Time     `json:"occurrenceDate"`
+	OccurrenceIndex        int           `json:"occurrenceIndex"`
+	TransactionID          uuid.NullUUID `json:"transactionId"`
+	CreatedAt              time.Time     `json:"createdAt"`
+}
+
+var RecurringTransactionRunColumns = "recurring_transaction_id, occurrence_date, occurrence_index, transaction_id, created_at"
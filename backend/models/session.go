@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session corresponds to the `sessions` table. Every login creates one session per device, and
+// all refresh tokens issued for it form a single rotation family. Current is computed per request.
+type Session struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"userId"`
+	UserAgent  string     `json:"userAgent"`
+	IPAddress  string     `json:"ipAddress"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt time.Time  `json:"lastUsedAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	Current    bool       `json:"current"`
+}
+
+var SessionColumns = "id, user_id, user_agent, ip_address, created_at, last_used_at, expires_at, revoked_at"
+
+// IsActive reports whether the session can still be used at the given time.
+func (s Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && s.ExpiresAt.After(now)
+}
+
+// RefreshToken corresponds to the `refresh_tokens` table. Only a SHA-256 hash of the token is
+// stored. A token is used once: refreshing marks it used and issues its successor.
+type RefreshToken struct {
+	ID        uuid.UUID  `json:"id"`
+	SessionID uuid.UUID  `json:"sessionId"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+var RefreshTokenColumns = "id, session_id, token_hash, expires_at, used_at, created_at"
+
+// AuthTokens is the pair of tokens handed to a client when it logs in or refreshes.
+type AuthTokens struct {
+	SessionID             uuid.UUID `json:"sessionId"`
+	AccessToken           string    `json:"token"`
+	AccessTokenExpiresAt  time.Time `json:"tokenExpiresAt"`
+	RefreshToken          string    `json:"refreshToken"`
+	RefreshTokenExpiresAt time.Time `json:"refreshTokenExpiresAt"`
+}
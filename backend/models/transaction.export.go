@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExportFormat is a file format transactions can be exported in.
+type ExportFormat string
+
+const (
+	ExportFormatCSV   ExportFormat = "csv"
+	ExportFormatJSONL ExportFormat = "jsonl"
+	ExportFormatXLSX  ExportFormat = "xlsx"
+	ExportFormatOFX   ExportFormat = "ofx"
+)
+
+var exportContentTypes = map[ExportFormat]string{
+	ExportFormatCSV:   "text/csv",
+	ExportFormatJSONL: "application/x-ndjson",
+	ExportFormatXLSX:  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	ExportFormatOFX:   "application/x-ofx",
+}
+
+func (f ExportFormat) IsValid() bool {
+	_, ok := exportContentTypes[f]
+	return ok
+}
+
+// ContentType returns the MIME type of the format.
+func (f ExportFormat) ContentType() string {
+	return exportContentTypes[f]
+}
+
+// TransactionExportFilter selects the transactions to export. Dates are YYYY-MM-DD and empty
+// fields do not filter. With Legs set a transfer is exported twice, once leaving its source
+// account and once arriving in its destination account, and rows are grouped by account.
+type TransactionExportFilter struct {
+	StartDate  string
+	EndDate    string
+	AccountID  uuid.NullUUID
+	CategoryID uuid.NullUUID
+	Type       TransactionType
+	Legs       bool
+}
+
+// TransactionExportRow is a transaction with the names of its category and accounts.
+type TransactionExportRow struct {
+	ID                 uuid.UUID       `json:"id"`
+	TransactionDate    time.Time       `json:"transactionDate"`
+	Description        string          `json:"description"`
+	Amount             Money           `json:"amount"`
+	Currency           Currency        `json:"currency"`
+	Type               TransactionType `json:"type"`
+	Category           string          `json:"category,omitempty"`
+	AccountID          uuid.UUID       `json:"accountId"`
+	Account            string          `json:"account"`
+	DestinationAccount string          `json:"destinationAccount,omitempty"`
+	DestinationAmount  *Money          `json:"destinationAmount,omitempty"`
+	Note               string          `json:"note,omitempty"`
+	// The fields below describe the row's account and are only used by statement formats.
+	AccountType    AccountType `json:"-"`
+	AccountBalance Money       `json:"-"`
+	// Incoming marks the destination leg of a transfer when exporting legs.
+	Incoming bool `json:"-"`
+}
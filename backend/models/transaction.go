@@ -11,23 +11,31 @@ import (
 type TransactionType string
 
 const (
-	TransactionTypeIncome  TransactionType = "income"
-	TransactionTypeExpense TransactionType = "expense"
+	TransactionTypeIncome   TransactionType = "income"
+	TransactionTypeExpense  TransactionType = "expense"
+	TransactionTypeTransfer TransactionType = "transfer"
 )
 
 type Transaction struct {
 	ID              uuid.UUID       `json:"id"`
 	UserID          uuid.UUID       `json:"userId"`
 	AccountID       uuid.UUID       `json:"accountId"`
-	CategoryID      uuid.UUID       `json:"categoryId"`
+	CategoryID      uuid.NullUUID   `json:"categoryId"`
 	BudgetID        uuid.NullUUID   `json:"budgetId,omitempty"`
 	Description     string          `json:"description"`
-	Amount          float64         `json:"amount"` // See note on NUMERIC type above
+	Amount          Money           `json:"amount"`
 	Type            TransactionType `json:"type"`
 	TransactionDate time.Time       `json:"transactionDate"`
 	Note            sql.NullString  `json:"note,omitempty"`
 	CreatedAt       time.Time       `json:"createdAt"`
 	UpdatedAt       time.Time       `json:"updatedAt"`
+	Currency        Currency        `json:"currency"`
+	// DestinationAccountID and DestinationAmount are only set for transfers. AccountID is the
+	// source account and DestinationAmount is what the destination receives in its own currency.
+	DestinationAccountID uuid.NullUUID `json:"destinationAccountId,omitempty"`
+	DestinationAmount    *Money        `json:"destinationAmount,omitempty"`
+	// ExternalID is the bank's identifier of an imported transaction, unique within the account.
+	ExternalID sql.NullString `json:"externalId,omitempty"`
 }
 
-var TransactionColumns = "id, user_id, account_id, category_id, budget_id, description, amount, type, transaction_date, note, created_at, updated_at"
+var TransactionColumns = "id, user_id, account_id, category_id, budget_id, description, amount, type, transaction_date, note, created_at, updated_at, currency, destination_account_id, destination_amount, external_id"
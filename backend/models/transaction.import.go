@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TransactionImportRow is one line of an imported file as it would be stored. Rows with Errors
+// cannot be imported and duplicates of existing transactions are skipped. RuleID is the rule that
+// categorised the row.
+type TransactionImportRow struct {
+	Line            int             `json:"line"`
+	TransactionDate *time.Time      `json:"transactionDate,omitempty"`
+	Description     string          `json:"description"`
+	Amount          Money           `json:"amount"`
+	Type            TransactionType `json:"type,omitempty"`
+	CategoryID      uuid.NullUUID   `json:"categoryId"`
+	BudgetID        uuid.NullUUID   `json:"budgetId,omitempty"`
+	Note            string          `json:"note,omitempty"`
+	ExternalID      string          `json:"externalId,omitempty"`
+	RuleID          uuid.NullUUID   `json:"ruleId,omitempty"`
+	Duplicate       bool            `json:"duplicate"`
+	Errors          []string        `json:"errors,omitempty"`
+}
+
+// TransactionImportResult summarises an import. A dry run only previews the rows.
+type TransactionImportResult struct {
+	DryRun     bool                   `json:"dryRun"`
+	AccountID  uuid.UUID              `json:"accountId"`
+	Total      int                    `json:"total"`
+	Imported   int                    `json:"imported"`
+	Duplicates int                    `json:"duplicates"`
+	Invalid    int                    `json:"invalid"`
+	Rows       []TransactionImportRow `json:"rows"`
+}
+
+// StatementFormat is a bank statement file format.
+type StatementFormat string
+
+const (
+	StatementFormatOFX StatementFormat = "ofx"
+	StatementFormatQFX StatementFormat = "qfx"
+	StatementFormatQIF StatementFormat = "qif"
+)
+
+// StatementImportOptions apply to every file of a statement import. Rows without a category of
+// their own get the default category of their type, and DayFirst gives the order of QIF dates.
+type StatementImportOptions struct {
+	IncomeCategoryID  uuid.UUID
+	ExpenseCategoryID uuid.UUID
+	DayFirst          bool
+}
+
+// StatementImportFailure is a row of a statement file that could not be imported. QIF rows are
+// identified by their line and OFX rows by the bank's transaction ID.
+type StatementImportFailure struct {
+	Line      int    `json:"line,omitempty"`
+	Reference string `json:"reference,omitempty"`
+	Error     string `json:"error"`
+}
+
+// StatementImportResult reports what happened to the rows of one statement file. Error is set
+// when the file could not be read at all.
+type StatementImportResult struct {
+	File     string                   `json:"file"`
+	Format   StatementFormat          `json:"format,omitempty"`
+	Imported int                      `json:"imported"`
+	Skipped  int                      `json:"skipped"`
+	Failed   int                      `json:"failed"`
+	Error    string                   `json:"error,omitempty"`
+	Failures []StatementImportFailure `json:"failures,omitempty"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RuleConditions are what a transaction has to match for a rule to apply. Empty conditions are
+// not checked. DescriptionContains and NoteContains ignore case, DescriptionPattern is a regular
+// expression matched against the description without regard to case, and the amount range is
+// inclusive.
+type RuleConditions struct {
+	DescriptionContains string        `json:"descriptionContains"`
+	DescriptionPattern  string        `json:"descriptionPattern"`
+	MinAmount           *Money        `json:"minAmount"`
+	MaxAmount           *Money        `json:"maxAmount"`
+	AccountID           uuid.NullUUID `json:"accountId"`
+	NoteContains        string        `json:"noteContains"`
+}
+
+// RuleActions are what a matching rule sets on the transaction. A rule with a category only
+// matches transactions of that category's type.
+type RuleActions struct {
+	CategoryID uuid.NullUUID `json:"categoryId"`
+	BudgetID   uuid.NullUUID `json:"budgetId"`
+	Note       string        `json:"note"`
+}
+
+// TransactionRule corresponds to the `transaction_rules` table. Rules are tried by descending
+// Priority, older rules first on a tie, and the first matching rule is applied.
+type TransactionRule struct {
+	ID       uuid.UUID `json:"id"`
+	UserID   uuid.UUID `json:"userId"`
+	Name     string    `json:"name"`
+	Priority int       `json:"priority"`
+	IsActive bool      `json:"isActive"`
+	RuleConditions
+	RuleActions
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+var TransactionRuleColumns = "id, user_id, name, priority, is_active, description_contains, description_pattern, min_amount, max_amount, account_id, note_contains, category_id, budget_id, note, created_at, updated_at"
+
+// TransactionRuleMatch is what the first matching rule sets on a transaction.
+type TransactionRuleMatch struct {
+	Matched    bool             `json:"matched"`
+	Rule       *TransactionRule `json:"rule,omitempty"`
+	CategoryID uuid.NullUUID    `json:"categoryId"`
+	BudgetID   uuid.NullUUID    `json:"budgetId"`
+	Note       string           `json:"note,omitempty"`
+}
+
+// TransactionRuleChange is a transaction changed by re-running the rules.
+type TransactionRuleChange struct {
+	TransactionID uuid.UUID     `json:"transactionId"`
+	RuleID        uuid.UUID     `json:"ruleId"`
+	CategoryID    uuid.NullUUID `json:"categoryId"`
+	BudgetID      uuid.NullUUID `json:"budgetId"`
+	Note          string        `json:"note,omitempty"`
+}
+
+// TransactionRuleApplyResult summarises a run of the rules over stored transactions. A dry run
+// only lists the changes.
+type TransactionRuleApplyResult struct {
+	DryRun  bool                    `json:"dryRun"`
+	Checked int                     `json:"checked"`
+	Matched int                     `json:"matched"`
+	Updated int                     `json:"updated"`
+	Changes []TransactionRuleChange `json:"changes"`
+}
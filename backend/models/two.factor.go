@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TwoFactor corresponds to the `user_two_factor` table. The TOTP secret is stored encrypted. A row
+// without EnabledAt is an enrollment that was started but not confirmed with a code yet.
+// LastUsedStep is the time step of the last accepted code, so a code cannot be replayed.
+type TwoFactor struct {
+	UserID       uuid.UUID  `json:"userId"`
+	Secret       string     `json:"-"`
+	EnabledAt    *time.Time `json:"enabledAt"`
+	LastUsedStep int64      `json:"-"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
+
+var TwoFactorColumns = "user_id, secret, enabled_at, last_used_step, created_at, updated_at"
+
+func (t TwoFactor) IsEnabled() bool {
+	return t.EnabledAt != nil
+}
+
+// TwoFactorRecoveryCode corresponds to the `two_factor_recovery_codes` table. Only a SHA-256 hash
+// of each code is stored, and each code works once.
+type TwoFactorRecoveryCode struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"userId"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"usedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+var TwoFactorRecoveryCodeColumns = "id, user_id, code_hash, used_at, created_at"
+
+// TwoFactorChallenge corresponds to the `two_factor_challenges` table. It is created when a
+// password login needs a second factor and limits how many codes can be tried against it.
+type TwoFactorChallenge struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"userId"`
+	Attempts  int        `json:"attempts"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+var TwoFactorChallengeColumns = "id, user_id, attempts, expires_at, used_at, created_at"
+
+// TwoFactorSetup is returned when enrollment starts. ProvisioningURI is shown as a QR code.
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabledAt"`
+	RecoveryCodesRemaining int        `json:"recoveryCodesRemaining"`
+}
+
+// LoginResult is the outcome of checking a user's first factor. Either Tokens is set and a
+// session started, or ChallengeToken is set and the login has to be finished with a code.
+type LoginResult struct {
+	User               *User
+	Tokens             *AuthTokens
+	ChallengeToken     string
+	ChallengeExpiresAt time.Time
+}
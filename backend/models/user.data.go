@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// UserDataExport is everything stored about a user, as returned by the personal data export.
+type UserDataExport struct {
+	ExportedAt            time.Time              `json:"exportedAt"`
+	User                  *User                  `json:"user"`
+	Preferences           *UserPreferences       `json:"preferences"`
+	Identities            []UserIdentity         `json:"identities"`
+	Accounts              []Account              `json:"accounts"`
+	Categories            []Category             `json:"categories"`
+	Transactions          []Transaction          `json:"transactions"`
+	Budgets               []Budget               `json:"budgets"`
+	RecurringTransactions []RecurringTransaction `json:"recurringTransactions"`
+	ImportProfiles        []ImportProfile        `json:"importProfiles"`
+	TransactionRules      []TransactionRule      `json:"transactionRules"`
+	Logs                  []Log                  `json:"logs"`
+}
@@ -14,14 +14,23 @@ const (
 	AuthProviderGoogle AuthProvider = "google"
 )
 
-// User corresponds to the `users` table.
+// User corresponds to the `users` table. DeletionScheduledAt is set while the user's request to
+// delete their account is in its grace period.
 type User struct {
-	ID        uuid.UUID    `json:"id"`
-	Name      string       `json:"name"`
-	Email     string       `json:"email"`
-	Password  string       `json:"-"` // Omitted from JSON responses for security
-	Provider  AuthProvider `json:"provider"`
-	CreatedAt time.Time    `json:"createdAt"`
+	ID                  uuid.UUID    `json:"id"`
+	Name                string       `json:"name"`
+	Email               string       `json:"email"`
+	Password            string       `json:"-"` // Omitted from JSON responses for security
+	Provider            AuthProvider `json:"provider"`
+	CreatedAt           time.Time    `json:"createdAt"`
+	BaseCurrency        Currency     `json:"baseCurrency"`
+	EmailVerifiedAt     *time.Time   `json:"emailVerifiedAt"`
+	DeletionScheduledAt *time.Time   `json:"deletionScheduledAt"`
 }
 
-var UserColumns = "id, name, email, password, provider, created_at"
+var UserColumns = "id, name, email, password, provider, created_at, base_currency, email_verified_at, deletion_scheduled_at"
+
+// IsEmailVerified reports whether the user proved they own their email address.
+func (u User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity corresponds to the `user_identities` table. It links an account to a login at an
+// external provider, identified by the provider's stable subject rather than by email.
+type UserIdentity struct {
+	ID        uuid.UUID    `json:"id"`
+	UserID    uuid.UUID    `json:"userId"`
+	Provider  AuthProvider `json:"provider"`
+	Subject   string       `json:"-"`
+	Email     string       `json:"email"`
+	CreatedAt time.Time    `json:"createdAt"`
+}
+
+var UserIdentityColumns = "id, user_id, provider, subject, email, created_at"
+
+// OAuthPurpose tells the OAuth callback whether to log a user in or link the identity to the
+// account that started the flow.
+type OAuthPurpose string
+
+const (
+	OAuthPurposeLogin OAuthPurpose = "login"
+	OAuthPurposeLink  OAuthPurpose = "link"
+)
+
+// OAuthState corresponds to the `oauth_states` table. Each OAuth redirect gets one row holding its
+// PKCE code verifier, and the callback consumes it so that a state can only be used once.
+type OAuthState struct {
+	Nonce        string        `json:"-"`
+	CodeVerifier string        `json:"-"`
+	Purpose      OAuthPurpose  `json:"purpose"`
+	UserID       uuid.NullUUID `json:"userId"`
+	ExpiresAt    time.Time     `json:"expiresAt"`
+	CreatedAt    time.Time     `json:"createdAt"`
+}
+
+var OAuthStateColumns = "nonce, code_verifier, purpose, user_id, expires_at, created_at"
+
+// OAuthRedirect starts an OAuth flow: the provider URL to send the browser to and the binding the
+// same browser has to present, in a cookie, when it comes back to the callback.
+type OAuthRedirect struct {
+	URL       string
+	Binding   string
+	ExpiresAt time.Time
+}
+
+// OAuthProfile is the part of the provider's OpenID Connect userinfo response the app relies on.
+type OAuthProfile struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DateFormat is how dates are shown to the user.
+type DateFormat string
+
+const (
+	DateFormatISO       DateFormat = "YYYY-MM-DD"
+	DateFormatDayMonth  DateFormat = "DD/MM/YYYY"
+	DateFormatMonthDay  DateFormat = "MM/DD/YYYY"
+	DateFormatDayDotted DateFormat = "DD.MM.YYYY"
+	DateFormatDayDashed DateFormat = "DD-MM-YYYY"
+)
+
+var dateFormatLayouts = map[DateFormat]string{
+	DateFormatISO:       "2006-01-02",
+	DateFormatDayMonth:  "02/01/2006",
+	DateFormatMonthDay:  "01/02/2006",
+	DateFormatDayDotted: "02.01.2006",
+	DateFormatDayDashed: "02-01-2006",
+}
+
+func (f DateFormat) IsValid() bool {
+	_, ok := dateFormatLayouts[f]
+	return ok
+}
+
+// Layout returns the Go time layout for the format.
+func (f DateFormat) Layout() string {
+	return dateFormatLayouts[f]
+}
+
+// localePattern accepts BCP 47 tags such as en, en-IN or zh-Hant-TW.
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+func IsValidLocale(locale string) bool {
+	return localePattern.MatchString(locale)
+}
+
+// UserPreferences are the user's display settings and defaults. BaseCurrency is stored on the
+// user, the rest in the `user_preferences` table. WeekStart is a weekday with 0 for Sunday.
+type UserPreferences struct {
+	Timezone         string        `json:"timezone"`
+	BaseCurrency     Currency      `json:"baseCurrency"`
+	Locale           string        `json:"locale"`
+	DateFormat       DateFormat    `json:"dateFormat"`
+	WeekStart        int           `json:"weekStart"`
+	DefaultAccountID uuid.NullUUID `json:"defaultAccountId"`
+	UpdatedAt        *time.Time    `json:"updatedAt"`
+}
+
+var UserPreferencesColumns = "user_id, timezone, locale, date_format, week_start, default_account_id, updated_at"
+
+// Location returns the user's time zone, falling back to UTC when it cannot be loaded.
+func (p UserPreferences) Location() *time.Location {
+	location, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// UserPreferencesUpdate holds the preferences to change, nil fields are left as they are. A
+// DefaultAccountID that is not Valid clears the default account.
+type UserPreferencesUpdate struct {
+	Timezone         *string
+	BaseCurrency     *Currency
+	Locale           *string
+	DateFormat       *DateFormat
+	WeekStart        *int
+	DefaultAccountID *uuid.NullUUID
+}
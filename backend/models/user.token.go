@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserTokenPurpose defines what a user token can be redeemed for.
+type UserTokenPurpose string
+
+const (
+	UserTokenEmailVerification UserTokenPurpose = "email_verification"
+	UserTokenPasswordReset     UserTokenPurpose = "password_reset"
+)
+
+// UserToken corresponds to the `user_tokens` table. Tokens are emailed to the user and only a
+// SHA-256 hash is stored. Each token can be redeemed once before it expires.
+type UserToken struct {
+	ID        uuid.UUID        `json:"id"`
+	UserID    uuid.UUID        `json:"userId"`
+	Purpose   UserTokenPurpose `json:"purpose"`
+	TokenHash string           `json:"-"`
+	ExpiresAt time.Time        `json:"expiresAt"`
+	UsedAt    *time.Time       `json:"usedAt,omitempty"`
+	CreatedAt time.Time        `json:"createdAt"`
+}
+
+var UserTokenColumns = "id, user_id, purpose, token_hash, expires_at, used_at, created_at"
@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LogMailer prints messages to the application log instead of sending them.
+type LogMailer struct {
+	From string
+}
+
+func (m *LogMailer) Send(msg Message) error {
+	log.Printf("Email to %s\n%s", msg.To, msg.Bytes(m.From))
+	return nil
+}
+
+// FileMailer writes every message to its own .eml file in Dir, where it can be opened with any
+// mail client.
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func (m *FileMailer) Send(msg Message) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405"), hex.EncodeToString(suffix))
+	return os.WriteFile(filepath.Join(m.Dir, name), msg.Bytes(m.From), 0o644)
+}
@@ -0,0 +1,43 @@
+// Package mailer sends plain text emails. Production deployments use SMTP, while local
+// development can log messages or write them to an outbox directory instead.
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Message is a plain text email to a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// headerValue strips line breaks so that a value cannot inject extra headers.
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+// Bytes renders the message in RFC 5322 format with the given sender.
+func (m Message) Bytes(from string) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", headerValue(from))
+	fmt.Fprintf(&buf, "To: %s\r\n", headerValue(m.To))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", headerValue(m.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(m.Body, "\n", "\r\n"))
+
+	return buf.Bytes()
+}
@@ -0,0 +1,29 @@
+package mailer
+
+import (
+	"net"
+	"net/smtp"
+)
+
+// SMTPMailer sends messages through an SMTP server. Authentication is only used when a username
+// is set, and net/smtp upgrades the connection with STARTTLS when the server offers it.
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	// From is the From header, e.g. "Finance Tracker <no-reply@example.com>".
+	From string
+	// Sender is the bare envelope sender address, e.g. "no-reply@example.com". Servers reject a
+	// MAIL FROM that carries a display name.
+	Sender string
+}
+
+func (m *SMTPMailer) Send(msg Message) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	return smtp.SendMail(net.JoinHostPort(m.Host, m.Port), auth, m.Sender, []string{msg.To}, msg.Bytes(m.From))
+}
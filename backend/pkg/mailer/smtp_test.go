@@ -0,0 +1,94 @@
+package mailer
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeSMTPServer accepts one message and returns the commands it received.
+func fakeSMTPServer(t *testing.T) (string, <-chan []string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var commands []string
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		reply("220 localhost ESMTP")
+		inData := false
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				break
+			}
+			line = strings.TrimRight(line, "\r\n")
+
+			if inData {
+				if line == "." {
+					inData = false
+					reply("250 queued")
+				}
+				continue
+			}
+
+			commands = append(commands, line)
+			switch {
+			case strings.HasPrefix(line, "EHLO"), strings.HasPrefix(line, "HELO"):
+				reply("250 localhost")
+			case line == "DATA":
+				inData = true
+				reply("354 go ahead")
+			case line == "QUIT":
+				reply("221 bye")
+				received <- commands
+				return
+			case strings.HasPrefix(line, "MAIL FROM:") && strings.ContainsAny(strings.TrimPrefix(line, "MAIL FROM:"), " \""):
+				reply("501 bad sender address")
+			default:
+				reply("250 ok")
+			}
+		}
+		received <- commands
+	}()
+
+	return listener.Addr().String(), received
+}
+
+func TestSMTPMailerEnvelopeSender(t *testing.T) {
+	address, received := fakeSMTPServer(t)
+	host, port, _ := net.SplitHostPort(address)
+
+	m := &SMTPMailer{Host: host, Port: port, From: "Finance Tracker <no-reply@localhost>", Sender: "no-reply@localhost"}
+	if err := m.Send(Message{To: "user@example.com", Subject: "Hello", Body: "Hi"}); err != nil {
+		t.Fatal(err)
+	}
+
+	commands := <-received
+	want := []string{"MAIL FROM:<no-reply@localhost>", "RCPT TO:<user@example.com>"}
+	for _, command := range want {
+		found := false
+		for _, got := range commands {
+			if strings.HasPrefix(got, command) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("server received %q, want %q", commands, command)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+// Package migrate applies numbered SQL migrations to PostgreSQL and records them in the
+// schema_migrations table.
+//
+// Every migration runs in its own transaction together with its schema_migrations row, so a
+// failing migration leaves nothing behind and stops the run. A session advisory lock is held for
+// the whole run so that several server instances starting at once apply each migration once.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// lockKey identifies the advisory lock taken while migrating.
+const lockKey int64 = 7_326_054_918_114_503
+
+// ErrNoDownFile is returned when rolling back a migration that has no down file.
+var ErrNoDownFile = errors.New("migration has no down file")
+
+// Status is a migration in the directory or the database with the time it was applied. Missing
+// migrations were applied but their files are gone.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+	Missing   bool
+}
+
+// Migrator applies the migrations found in a directory.
+type Migrator struct {
+	db  *sql.DB
+	dir string
+}
+
+func New(db *sql.DB, dir string) *Migrator {
+	return &Migrator{db: db, dir: dir}
+}
+
+// Up applies every pending migration in version order and returns the ones it applied. It stops
+// at the first failure, keeping the migrations applied before it.
+func (m *Migrator) Up() ([]Migration, error) {
+	migrations, err := Load(m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	err = m.withLock(func(conn *sql.Conn) error {
+		versions, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+
+			if err := run(conn, migration, migration.UpPath, "INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, NOW())", migration.Version, migration.Name); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls back the last n applied migrations, newest first, and returns the ones it rolled back.
+func (m *Migrator) Down(n int) ([]Migration, error) {
+	if n < 1 {
+		return nil, errors.New("number of migrations to roll back must be at least 1")
+	}
+
+	migrations, err := Load(m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]Migration, len(migrations))
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	var reverted []Migration
+	err = m.withLock(func(conn *sql.Conn) error {
+		rows, err := conn.QueryContext(context.Background(), "SELECT version, name FROM schema_migrations ORDER BY version DESC LIMIT $1", n)
+		if err != nil {
+			return err
+		}
+
+		var targets []Migration
+		for rows.Next() {
+			var target Migration
+			if err := rows.Scan(&target.Version, &target.Name); err != nil {
+				rows.Close()
+				return err
+			}
+			targets = append(targets, target)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, target := range targets {
+			migration, ok := byVersion[target.Version]
+			if !ok || migration.DownPath == "" {
+				return fmt.Errorf("%s: %w", target, ErrNoDownFile)
+			}
+
+			if err := run(conn, migration, migration.DownPath, "DELETE FROM schema_migrations WHERE version = $1", migration.Version); err != nil {
+				return err
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status lists every migration in version order with the time it was applied, including applied
+// migrations whose files no longer exist.
+func (m *Migrator) Status() ([]Status, error) {
+	migrations, err := Load(m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+	err = m.withLock(func(conn *sql.Conn) error {
+		rows, err := conn.QueryContext(context.Background(), "SELECT version, name, applied_at FROM schema_migrations ORDER BY version")
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		applied := make(map[int64]Status)
+		for rows.Next() {
+			var status Status
+			var appliedAt time.Time
+			if err := rows.Scan(&status.Version, &status.Name, &appliedAt); err != nil {
+				return err
+			}
+			status.AppliedAt = &appliedAt
+			status.Missing = true
+			applied[status.Version] = status
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			status := Status{Migration: migration}
+			if found, ok := applied[migration.Version]; ok {
+				status.AppliedAt = found.AppliedAt
+				delete(applied, migration.Version)
+			}
+			statuses = append(statuses, status)
+		}
+
+		for _, status := range applied {
+			statuses = append(statuses, status)
+		}
+		sort.Slice(statuses, func(i, j int) bool {
+			return statuses[i].Version < statuses[j].Version
+		})
+		return nil
+	})
+
+	return statuses, err
+}
+
+// withLock runs fn on a single connection holding the migration lock, creating the
+// schema_migrations table first if needed.
+func (m *Migrator) withLock(fn func(conn *sql.Conn) error) error {
+	ctx := context.Background()
+
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("acquiring migration lock: %w", err)
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockKey)
+
+	if _, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW())"); err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// appliedVersions returns the versions recorded in schema_migrations.
+func appliedVersions(conn *sql.Conn) (map[int64]struct{}, error) {
+	rows, err := conn.QueryContext(context.Background(), "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make(map[int64]struct{})
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		versions[version] = struct{}{}
+	}
+	return versions, rows.Err()
+}
+
+// run executes a migration file and the statement recording it in one transaction. The file is
+// sent as a single query, so it may contain several statements, functions and quoted semicolons.
+func run(conn *sql.Conn, migration Migration, path string, record string, args ...any) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, string(content)); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s: %w", migration, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s: %w", migration, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", migration, err)
+	}
+	return nil
+}
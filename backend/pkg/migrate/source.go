@@ -0,0 +1,128 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Migration is one numbered schema change. Its files are named <version>_<name>.up.sql and
+// <version>_<name>.down.sql, the down file is optional.
+type Migration struct {
+	Version  int64
+	Name     string
+	UpPath   string
+	DownPath string
+}
+
+// String returns the migration's file name without the direction, e.g. 0002_add_rules.
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+var (
+	fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	namePattern     = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Load reads the migrations in dir, ordered by version. Files that do not follow the naming
+// scheme are ignored.
+func Load(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		matches := fileNamePattern.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != matches[2] {
+			return nil, fmt.Errorf("migration %d has files with different names: %s and %s", version, migration.Name, matches[2])
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if matches[3] == "up" {
+			migration.UpPath = path
+		} else {
+			migration.DownPath = path
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.UpPath == "" {
+			return nil, fmt.Errorf("migration %s has no up file", migration)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Create writes an empty up and down file for a new migration numbered after the last one in dir.
+// The name is reduced to lower case words joined by underscores.
+func Create(dir string, name string) (Migration, error) {
+	name = strings.Trim(namePattern.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return Migration{}, errors.New("migration name must contain letters or digits")
+	}
+
+	migrations, err := Load(dir)
+	if err != nil {
+		return Migration{}, err
+	}
+
+	migration := Migration{Version: 1, Name: name}
+	if len(migrations) > 0 {
+		migration.Version = migrations[len(migrations)-1].Version + 1
+	}
+	migration.UpPath = filepath.Join(dir, migration.String()+".up.sql")
+	migration.DownPath = filepath.Join(dir, migration.String()+".down.sql")
+
+	files := []struct{ path, content string }{
+		{migration.UpPath, "-- " + migration.String() + ": apply the change.\n"},
+		{migration.DownPath, "-- " + migration.String() + ": revert the up migration.\n"},
+	}
+	for _, f := range files {
+		file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return Migration{}, err
+		}
+		_, err = file.WriteString(f.content)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return Migration{}, err
+		}
+	}
+
+	return migration, nil
+}
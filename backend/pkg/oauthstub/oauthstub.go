@@ -0,0 +1,195 @@
+// Package oauthstub is a minimal OAuth 2.0 authorization server that mimics Google's
+// authorization code flow with PKCE. It lets the login and account linking flows run offline,
+// during local development or in integration tests through httptest.
+//
+// The authorize endpoint approves every request without showing a consent screen. The identity
+// it signs in can be chosen per request with the login_hint, sub, name and email_verified
+// query parameters, otherwise the server's default identity is used.
+package oauthstub
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Identity is the profile returned by the userinfo endpoint, using the OpenID Connect claim names.
+type Identity struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+}
+
+type grant struct {
+	identity      Identity
+	redirectURI   string
+	codeChallenge string
+	expiresAt     time.Time
+}
+
+// Server implements the authorize, token and userinfo endpoints.
+type Server struct {
+	defaultIdentity Identity
+	mux             *http.ServeMux
+
+	mu     sync.Mutex
+	codes  map[string]grant
+	tokens map[string]Identity
+}
+
+func New(defaultIdentity Identity) *Server {
+	s := &Server{
+		defaultIdentity: defaultIdentity,
+		mux:             http.NewServeMux(),
+		codes:           make(map[string]grant),
+		tokens:          make(map[string]Identity),
+	}
+
+	s.mux.HandleFunc("/authorize", s.authorize)
+	s.mux.HandleFunc("/token", s.token)
+	s.mux.HandleFunc("/userinfo", s.userinfo)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func randomString() string {
+	buf := make([]byte, 24)
+	_, _ = rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, code string, description string) {
+	writeJSON(w, status, map[string]string{"error": code, "error_description": description})
+}
+
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "redirect_uri is required")
+		return
+	}
+
+	if query.Get("response_type") != "code" {
+		writeError(w, http.StatusBadRequest, "unsupported_response_type", "response_type must be code")
+		return
+	}
+
+	if query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "a S256 code_challenge is required")
+		return
+	}
+
+	identity := s.defaultIdentity
+	if email := query.Get("login_hint"); email != "" {
+		identity.Email = email
+		identity.Subject = "stub-" + email
+	}
+	if subject := query.Get("sub"); subject != "" {
+		identity.Subject = subject
+	}
+	if name := query.Get("name"); name != "" {
+		identity.Name = name
+	}
+	if query.Get("email_verified") == "false" {
+		identity.EmailVerified = false
+	}
+
+	code := randomString()
+
+	s.mu.Lock()
+	s.codes[code] = grant{
+		identity:      identity,
+		redirectURI:   redirectURI.String(),
+		codeChallenge: query.Get("code_challenge"),
+		expiresAt:     time.Now().Add(5 * time.Minute),
+	}
+	s.mu.Unlock()
+
+	values := redirectURI.Query()
+	values.Set("code", code)
+	values.Set("state", query.Get("state"))
+	redirectURI.RawQuery = values.Encode()
+
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "invalid_request", "use POST")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		writeError(w, http.StatusBadRequest, "unsupported_grant_type", "grant_type must be authorization_code")
+		return
+	}
+
+	// Codes are single use, so the grant is removed before it is checked.
+	s.mu.Lock()
+	code := r.PostForm.Get("code")
+	grant, ok := s.codes[code]
+	delete(s.codes, code)
+	s.mu.Unlock()
+
+	if !ok || time.Now().After(grant.expiresAt) || grant.redirectURI != r.PostForm.Get("redirect_uri") {
+		writeError(w, http.StatusBadRequest, "invalid_grant", "code is invalid or expired")
+		return
+	}
+
+	challenge := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(challenge[:]) != grant.codeChallenge {
+		writeError(w, http.StatusBadRequest, "invalid_grant", "code_verifier does not match the code_challenge")
+		return
+	}
+
+	accessToken := randomString()
+
+	s.mu.Lock()
+	s.tokens[accessToken] = grant.identity
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+func (s *Server) userinfo(w http.ResponseWriter, r *http.Request) {
+	accessToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	s.mu.Lock()
+	identity, ok := s.tokens[accessToken]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "invalid_token", "access token is invalid")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, identity)
+}
@@ -0,0 +1,246 @@
+package ofx
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrNotOFX is returned by Parse when the input has no OFX element.
+var ErrNotOFX = errors.New("ofx: the file is not an OFX document")
+
+// ParsedStatement is a bank or credit card statement read from a file. Amounts are signed
+// decimals with a dot, Posted is zero when the file's date could not be read.
+type ParsedStatement struct {
+	Statement
+	Transactions []Transaction
+}
+
+// Parse reads the bank and credit card statements of an OFX document. Both the SGML syntax of
+// OFX 1.x, where elements holding a value are not closed, and the XML syntax of OFX 2.x are
+// accepted, as are Quicken's QFX files, which are OFX with extra elements.
+func Parse(r io.Reader) ([]ParsedStatement, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	start := indexOFX(data)
+	if start < 0 {
+		return nil, ErrNotOFX
+	}
+
+	body := string(data[start:])
+	if !utf8.ValidString(body) {
+		// OFX 1.x files are usually in a Windows code page. Reading them as Latin-1 keeps
+		// every letter but the few that code page places in 0x80 to 0x9F.
+		body = latin1(data[start:])
+	}
+
+	tokens := tokenize(body)
+
+	// In SGML only aggregates are closed, so an empty element that is never closed, such as a
+	// <MEMO> without text, holds a value rather than the elements after it.
+	closed := make(map[string]bool)
+	for _, token := range tokens {
+		if token.closing {
+			closed[token.name] = true
+		}
+	}
+
+	var statements []ParsedStatement
+	var statement *ParsedStatement
+	var transaction *Transaction
+	var path []string
+
+	for _, token := range tokens {
+		switch {
+		case token.empty || (token.value == "" && !closed[token.name]):
+			continue
+
+		case token.closing:
+			// Elements with a value may be left open in SGML, so close everything up to the
+			// matching aggregate.
+			for i := len(path) - 1; i >= 0; i-- {
+				if path[i] == token.name {
+					path = path[:i]
+					break
+				}
+			}
+
+			switch token.name {
+			case "STMTTRN":
+				if statement != nil && transaction != nil {
+					statement.Transactions = append(statement.Transactions, *transaction)
+				}
+				transaction = nil
+			case "STMTRS", "CCSTMTRS":
+				if statement != nil {
+					statements = append(statements, *statement)
+				}
+				statement = nil
+			}
+
+		case token.value == "":
+			path = append(path, token.name)
+
+			switch token.name {
+			case "STMTRS", "CCSTMTRS":
+				statement = &ParsedStatement{}
+				if token.name == "CCSTMTRS" {
+					statement.AccountType = AccountCreditLine
+				}
+			case "STMTTRN":
+				transaction = &Transaction{}
+			}
+
+		default:
+			parent := ""
+			if len(path) > 0 {
+				parent = path[len(path)-1]
+			}
+
+			if transaction != nil && parent == "STMTTRN" {
+				setTransactionField(transaction, token.name, token.value)
+			} else if statement != nil {
+				setStatementField(statement, parent, token.name, token.value)
+			}
+		}
+	}
+
+	return statements, nil
+}
+
+func setTransactionField(transaction *Transaction, name string, value string) {
+	switch name {
+	case "TRNTYPE":
+		transaction.Type = value
+	case "DTPOSTED":
+		transaction.Posted, _ = parseDate(value)
+	case "TRNAMT":
+		transaction.Amount = strings.ReplaceAll(value, ",", ".")
+	case "FITID":
+		transaction.FITID = value
+	case "NAME":
+		transaction.Name = value
+	case "MEMO":
+		transaction.Memo = value
+	}
+}
+
+func setStatementField(statement *ParsedStatement, parent string, name string, value string) {
+	switch {
+	case name == "CURDEF":
+		statement.Currency = value
+	case name == "ACCTID" && (parent == "BANKACCTFROM" || parent == "CCACCTFROM"):
+		statement.AccountID = value
+	case name == "ACCTTYPE" && parent == "BANKACCTFROM":
+		statement.AccountType = value
+	case name == "DTSTART" && parent == "BANKTRANLIST":
+		statement.Start, _ = parseDate(value)
+	case name == "DTEND" && parent == "BANKTRANLIST":
+		statement.End, _ = parseDate(value)
+	case name == "BALAMT" && parent == "LEDGERBAL":
+		statement.Balance = strings.ReplaceAll(value, ",", ".")
+	case name == "DTASOF" && parent == "LEDGERBAL":
+		statement.BalanceAt, _ = parseDate(value)
+	}
+}
+
+// parseDate reads the calendar date at the start of an OFX date time such as
+// 20251009120000.000[-5:EST]. The time and zone are ignored, since banks post on a date.
+func parseDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, errors.New("ofx: invalid date")
+	}
+	return time.Parse("20060102", value[:8])
+}
+
+type token struct {
+	name    string
+	value   string
+	closing bool
+	empty   bool
+}
+
+// tokenize splits the body into tags, each with the text that follows it up to the next tag.
+// Comments and processing instructions are skipped.
+func tokenize(body string) []token {
+	var tokens []token
+
+	for {
+		open := strings.IndexByte(body, '<')
+		if open < 0 {
+			return tokens
+		}
+		body = body[open+1:]
+
+		if strings.HasPrefix(body, "!--") {
+			end := strings.Index(body, "-->")
+			if end < 0 {
+				return tokens
+			}
+			body = body[end+3:]
+			continue
+		}
+
+		end := strings.IndexByte(body, '>')
+		if end < 0 {
+			return tokens
+		}
+		tag := strings.TrimSpace(body[:end])
+		body = body[end+1:]
+
+		if strings.HasPrefix(tag, "?") || strings.HasPrefix(tag, "!") || tag == "" {
+			continue
+		}
+
+		closing := strings.HasPrefix(tag, "/")
+		empty := strings.HasSuffix(tag, "/")
+		tag = strings.TrimSuffix(strings.TrimPrefix(tag, "/"), "/")
+		if fields := strings.Fields(tag); len(fields) > 0 {
+			tag = fields[0]
+		}
+		tag = strings.ToUpper(tag)
+
+		value := ""
+		if !closing && !empty {
+			next := strings.IndexByte(body, '<')
+			if next < 0 {
+				next = len(body)
+			}
+			value = unescape(strings.TrimSpace(body[:next]))
+		}
+
+		tokens = append(tokens, token{name: tag, value: value, closing: closing, empty: empty})
+	}
+}
+
+var entities = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&nbsp;", " ", "&amp;", "&")
+
+func unescape(value string) string {
+	return entities.Replace(value)
+}
+
+// indexOFX returns the offset of the OFX element, ignoring case, or -1. It compares bytes so that
+// text in another encoding before the element cannot shift the offset.
+func indexOFX(data []byte) int {
+	tag := []byte("<OFX>")
+	for i := 0; i+len(tag) <= len(data); i++ {
+		if data[i] == '<' && bytes.EqualFold(data[i:i+len(tag)], tag) {
+			return i
+		}
+	}
+	return -1
+}
+
+func latin1(data []byte) string {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
@@ -0,0 +1,299 @@
+package ofx
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+const sgmlStatement = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+ENCODING:USASCII
+CHARSET:1252
+
+<OFX>
+<SIGNONMSGSRSV1><SONRS>
+<STATUS><CODE>0<SEVERITY>INFO</STATUS>
+<DTSERVER>20251010
+<LANGUAGE>ENG
+</SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1>
+<STMTTRNRS>
+<TRNUID>1
+<STATUS><CODE>0<SEVERITY>INFO</STATUS>
+<STMTRS>
+<CURDEF>USD
+<BANKACCTFROM>
+<BANKID>121000248
+<ACCTID>000123456
+<ACCTTYPE>CHECKING
+</BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>20251001
+<DTEND>20251009120000.000[-5:EST]
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20251003120000.000[-5:EST]
+<TRNAMT>-42,50
+<FITID>2025100301
+<NAME>Corner Shop &amp; Deli
+<MEMO>Card 1234
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20251005
+<TRNAMT>1500.00
+<MEMO>
+<FITID>2025100502
+<NAME>Salary
+</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL>
+<BALAMT>2457.50
+<DTASOF>20251009
+</LEDGERBAL>
+<AVAILBAL>
+<BALAMT>2000.00
+<DTASOF>20251009
+</AVAILBAL>
+</STMTRS>
+</STMTTRNRS>
+</BANKMSGSRSV1>
+</OFX>
+`
+
+func TestParseSGML(t *testing.T) {
+	statements, err := Parse(strings.NewReader(sgmlStatement))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ParsedStatement{{
+		Statement: Statement{
+			AccountID:   "000123456",
+			AccountType: AccountChecking,
+			Currency:    "USD",
+			Start:       date(2025, time.October, 1),
+			End:         date(2025, time.October, 9),
+			Balance:     "2457.50",
+			BalanceAt:   date(2025, time.October, 9),
+		},
+		Transactions: []Transaction{
+			{Type: "DEBIT", Posted: date(2025, time.October, 3), Amount: "-42.50", FITID: "2025100301", Name: "Corner Shop & Deli", Memo: "Card 1234"},
+			{Type: "CREDIT", Posted: date(2025, time.October, 5), Amount: "1500.00", FITID: "2025100502", Name: "Salary"},
+		},
+	}}
+
+	if !reflect.DeepEqual(statements, want) {
+		t.Fatalf("Parse() =\n%+v\nwant\n%+v", statements, want)
+	}
+}
+
+func TestParseXML(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<!-- exported by a bank -->
+<OFX>
+  <CREDITCARDMSGSRSV1>
+    <CCSTMTTRNRS>
+      <TRNUID>1</TRNUID>
+      <CCSTMTRS>
+        <CURDEF>EUR</CURDEF>
+        <CCACCTFROM><ACCTID>4111111111111111</ACCTID></CCACCTFROM>
+        <BANKTRANLIST>
+          <DTSTART>20250901000000</DTSTART>
+          <DTEND>20250930000000</DTEND>
+          <STMTTRN>
+            <TRNTYPE>DEBIT</TRNTYPE>
+            <DTPOSTED>20250912</DTPOSTED>
+            <TRNAMT>-9.99</TRNAMT>
+            <MEMO/>
+            <FITID>CC-1</FITID>
+            <NAME>Café &lt;Online&gt;</NAME>
+          </STMTTRN>
+          <!-- <STMTTRN><FITID>commented-out</FITID></STMTTRN> -->
+          <STMTTRN>
+            <TRNTYPE>CREDIT</TRNTYPE>
+            <DTPOSTED>20250920</DTPOSTED>
+            <TRNAMT>25</TRNAMT>
+            <NAME></NAME>
+            <FITID>CC-2</FITID>
+          </STMTTRN>
+        </BANKTRANLIST>
+        <LEDGERBAL><BALAMT>-120.01</BALAMT><DTASOF>20250930</DTASOF></LEDGERBAL>
+      </CCSTMTRS>
+    </CCSTMTTRNRS>
+  </CREDITCARDMSGSRSV1>
+</OFX>`
+
+	statements, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ParsedStatement{{
+		Statement: Statement{
+			AccountID:   "4111111111111111",
+			AccountType: AccountCreditLine,
+			Currency:    "EUR",
+			Start:       date(2025, time.September, 1),
+			End:         date(2025, time.September, 30),
+			Balance:     "-120.01",
+			BalanceAt:   date(2025, time.September, 30),
+		},
+		Transactions: []Transaction{
+			{Type: "DEBIT", Posted: date(2025, time.September, 12), Amount: "-9.99", FITID: "CC-1", Name: "Café <Online>"},
+			{Type: "CREDIT", Posted: date(2025, time.September, 20), Amount: "25", FITID: "CC-2"},
+		},
+	}}
+
+	if !reflect.DeepEqual(statements, want) {
+		t.Fatalf("Parse() =\n%+v\nwant\n%+v", statements, want)
+	}
+}
+
+func TestParseLatin1(t *testing.T) {
+	input := "OFXHEADER:100\r\nCHARSET:1252\r\n\r\n<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><CURDEF>EUR" +
+		"<BANKACCTFROM><ACCTID>FR76<ACCTTYPE>SAVINGS</BANKACCTFROM><BANKTRANLIST>" +
+		"<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20250102<TRNAMT>-3,20<FITID>A1<NAME>Caf\xe9 cr\xe8me<MEMO>Boulangerie M\xfcller</STMTTRN>" +
+		"</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>"
+
+	statements, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(statements) != 1 || len(statements[0].Transactions) != 1 {
+		t.Fatalf("Parse() = %+v, want one statement with one transaction", statements)
+	}
+
+	transaction := statements[0].Transactions[0]
+	if transaction.Name != "Café crème" || transaction.Memo != "Boulangerie Müller" || transaction.Amount != "-3.20" {
+		t.Fatalf("transaction = %+v", transaction)
+	}
+	if statements[0].AccountType != AccountSavings || statements[0].AccountID != "FR76" {
+		t.Fatalf("statement = %+v", statements[0].Statement)
+	}
+}
+
+func TestParseMultipleStatements(t *testing.T) {
+	input := `<ofx>
+<BANKMSGSRSV1>
+<STMTTRNRS><STMTRS><CURDEF>USD
+<BANKACCTFROM><ACCTID>111<ACCTTYPE>CHECKING</BANKACCTFROM>
+<BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20250301<TRNAMT>-1<FITID>SAME</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS>
+<STMTTRNRS><STMTRS><CURDEF>GBP
+<BANKACCTFROM><ACCTID>222<ACCTTYPE>SAVINGS</BANKACCTFROM>
+<BANKTRANLIST>
+<STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20250302<TRNAMT>2<FITID>SAME</STMTTRN>
+<STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20250303<TRNAMT>3<FITID>OTHER</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS>
+</BANKMSGSRSV1>
+<CREDITCARDMSGSRSV1>
+<CCSTMTTRNRS><CCSTMTRS><CURDEF>USD
+<CCACCTFROM><ACCTID>333</CCACCTFROM>
+<BANKTRANLIST></BANKTRANLIST>
+</CCSTMTRS></CCSTMTTRNRS>
+</CREDITCARDMSGSRSV1>
+</ofx>`
+
+	statements, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(statements) != 3 {
+		t.Fatalf("got %d statements, want 3", len(statements))
+	}
+
+	tests := []struct {
+		accountID   string
+		accountType string
+		currency    string
+		fitids      []string
+	}{
+		{"111", AccountChecking, "USD", []string{"SAME"}},
+		{"222", AccountSavings, "GBP", []string{"SAME", "OTHER"}},
+		{"333", AccountCreditLine, "USD", nil},
+	}
+
+	for i, test := range tests {
+		statement := statements[i]
+		if statement.AccountID != test.accountID || statement.AccountType != test.accountType || statement.Currency != test.currency {
+			t.Errorf("statement %d = %+v, want account %s %s in %s", i, statement.Statement, test.accountID, test.accountType, test.currency)
+		}
+
+		var fitids []string
+		for _, transaction := range statement.Transactions {
+			fitids = append(fitids, transaction.FITID)
+		}
+		if !reflect.DeepEqual(fitids, test.fitids) {
+			t.Errorf("statement %d FITIDs = %v, want %v", i, fitids, test.fitids)
+		}
+	}
+}
+
+func TestParseInvalidDates(t *testing.T) {
+	input := `<OFX><STMTRS><BANKTRANLIST>
+<STMTTRN><DTPOSTED>2025<TRNAMT>1<FITID>A</STMTTRN>
+<STMTTRN><DTPOSTED>20251340<TRNAMT>1<FITID>B</STMTTRN>
+</BANKTRANLIST></STMTRS></OFX>`
+
+	statements, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, transaction := range statements[0].Transactions {
+		if !transaction.Posted.IsZero() {
+			t.Errorf("transaction %s posted %s, want the zero time", transaction.FITID, transaction.Posted)
+		}
+	}
+}
+
+func TestParseNotOFX(t *testing.T) {
+	for _, input := range []string{"", "Date,Amount\n2025-01-01,1\n", "!Type:Bank\nD1/1/25\n^\n", "<OFXHEADER>"} {
+		if _, err := Parse(strings.NewReader(input)); !errors.Is(err, ErrNotOFX) {
+			t.Errorf("Parse(%q) error = %v, want %v", input, err, ErrNotOFX)
+		}
+	}
+}
+
+func TestParseHeaderInAnotherEncoding(t *testing.T) {
+	// A non UTF-8 byte before the OFX element must not shift where the body starts.
+	input := "OFXHEADER:100\nNOTE:\xe9\xe9\xe9\n<OFX><STMTRS><CURDEF>USD</STMTRS></OFX>"
+
+	statements, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statements) != 1 || statements[0].Currency != "USD" {
+		t.Fatalf("Parse() = %+v", statements)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tokens := tokenize(`<?xml version="1.0"?><!DOCTYPE ofx><!-- a <b> comment --><A attr="1">x &amp; y<B/><c>  z  </C>`)
+
+	want := []token{
+		{name: "A", value: "x & y"},
+		{name: "B", empty: true},
+		{name: "C", value: "z"},
+		{name: "C", closing: true},
+	}
+
+	if !reflect.DeepEqual(tokens, want) {
+		t.Fatalf("tokenize() = %+v, want %+v", tokens, want)
+	}
+}
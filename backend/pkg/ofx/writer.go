@@ -0,0 +1,156 @@
+// Package ofx reads bank statements in the Open Financial Exchange formats and writes them in
+// the OFX 2.2 XML format.
+package ofx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Account types of a bank statement.
+const (
+	AccountChecking    = "CHECKING"
+	AccountSavings     = "SAVINGS"
+	AccountMoneyMarket = "MONEYMRKT"
+	AccountCreditLine  = "CREDITLINE"
+)
+
+// Transaction types used by the writer.
+const (
+	TypeCredit   = "CREDIT"
+	TypeDebit    = "DEBIT"
+	TypeTransfer = "XFER"
+)
+
+// Field lengths allowed by the specification.
+const (
+	maxNameLength = 32
+	maxMemoLength = 255
+)
+
+// Statement describes one account's statement. Start and End bound the transactions in it and
+// Balance is the ledger balance as of BalanceAt.
+type Statement struct {
+	AccountID   string
+	AccountType string
+	Currency    string
+	Start       time.Time
+	End         time.Time
+	Balance     string
+	BalanceAt   time.Time
+}
+
+// Transaction is one statement line. Amount is signed: negative amounts leave the account.
+type Transaction struct {
+	Type   string
+	Posted time.Time
+	Amount string
+	FITID  string
+	Name   string
+	Memo   string
+}
+
+// Writer streams statements into a single OFX document.
+type Writer struct {
+	w          io.Writer
+	statements int
+	open       *Statement
+	err        error
+}
+
+// NewWriter writes the OFX header and sign-on response. now is reported as the server time.
+func NewWriter(w io.Writer, now time.Time) (*Writer, error) {
+	writer := &Writer{w: w}
+	writer.printf(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1>
+`, formatTime(now))
+	return writer, writer.err
+}
+
+// StartStatement opens a statement. Transactions written afterwards belong to it until
+// EndStatement is called.
+func (w *Writer) StartStatement(statement Statement) error {
+	if w.open != nil {
+		return errors.New("ofx: previous statement is still open")
+	}
+
+	w.statements++
+	w.open = &statement
+	w.printf("<STMTTRNRS><TRNUID>%d</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>\n<STMTRS><CURDEF>%s</CURDEF>", w.statements, escape(statement.Currency, 0))
+	w.printf("<BANKACCTFROM><BANKID>0</BANKID><ACCTID>%s</ACCTID><ACCTTYPE>%s</ACCTTYPE></BANKACCTFROM>\n", escape(statement.AccountID, 22), statement.AccountType)
+	w.printf("<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>\n", formatDate(statement.Start), formatDate(statement.End))
+	return w.err
+}
+
+// WriteTransaction adds a line to the open statement.
+func (w *Writer) WriteTransaction(transaction Transaction) error {
+	if w.open == nil {
+		return errors.New("ofx: no statement is open")
+	}
+
+	w.printf("<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%s</FITID><NAME>%s</NAME>",
+		transaction.Type, formatDate(transaction.Posted), transaction.Amount, escape(transaction.FITID, 255), escape(transaction.Name, maxNameLength))
+	if transaction.Memo != "" {
+		w.printf("<MEMO>%s</MEMO>", escape(transaction.Memo, maxMemoLength))
+	}
+	w.printf("</STMTTRN>\n")
+	return w.err
+}
+
+// EndStatement closes the open statement with its ledger balance.
+func (w *Writer) EndStatement() error {
+	if w.open == nil {
+		return errors.New("ofx: no statement is open")
+	}
+
+	w.printf("</BANKTRANLIST><LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL></STMTRS></STMTTRNRS>\n", w.open.Balance, formatTime(w.open.BalanceAt))
+	w.open = nil
+	return w.err
+}
+
+// Close ends an open statement and the document. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.open != nil {
+		if err := w.EndStatement(); err != nil {
+			return err
+		}
+	}
+
+	w.printf("</BANKMSGSRSV1>\n</OFX>\n")
+	return w.err
+}
+
+func (w *Writer) printf(format string, args ...any) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = fmt.Fprintf(w.w, format, args...)
+}
+
+// formatDate writes a calendar date, which OFX treats as midnight of that day.
+func formatDate(t time.Time) string {
+	return t.Format("20060102")
+}
+
+// formatTime writes an instant in UTC.
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
+
+// escape shortens value to limit characters, when limit is positive, and escapes it for XML.
+func escape(value string, limit int) string {
+	if runes := []rune(value); limit > 0 && len(runes) > limit {
+		value = string(runes[:limit])
+	}
+
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
@@ -0,0 +1,177 @@
+// Package qif reads transactions from Quicken Interchange Format files.
+package qif
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrNotQIF is returned by Parse when the input has no section with account transactions.
+var ErrNotQIF = errors.New("qif: the file has no account transactions")
+
+// Transaction is one record of a QIF file. Amount is signed as written, Date is left as written
+// because the order of day and month depends on the program that wrote the file.
+type Transaction struct {
+	Line     int
+	Date     string
+	Amount   string
+	Payee    string
+	Memo     string
+	Number   string
+	Category string
+}
+
+// accountTypes are the sections holding transactions of a single account. Investment,
+// category and memorised transaction lists are skipped.
+var accountTypes = map[string]bool{
+	"bank":  true,
+	"cash":  true,
+	"ccard": true,
+	"oth a": true,
+	"oth l": true,
+}
+
+// Parse reads the bank, cash, credit card, asset and liability transactions of a QIF file.
+func Parse(r io.Reader) ([]Transaction, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var transactions []Transaction
+	var current *Transaction
+	inAccount := false
+	found := false
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if !utf8.ValidString(text) {
+			text = latin1(text)
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		if text[0] == '!' {
+			header := strings.ToLower(strings.TrimSpace(text[1:]))
+			if strings.HasPrefix(header, "type:") {
+				inAccount = accountTypes[strings.TrimSpace(strings.TrimPrefix(header, "type:"))]
+				found = found || inAccount
+			} else if !strings.HasPrefix(header, "option") && !strings.HasPrefix(header, "clear") {
+				// Includes !Account, whose records describe the account rather than transactions.
+				inAccount = false
+			}
+			current = nil
+			continue
+		}
+
+		if !inAccount {
+			continue
+		}
+
+		if current == nil {
+			current = &Transaction{Line: line}
+		}
+
+		value := strings.TrimSpace(text[1:])
+		switch text[0] {
+		case 'D':
+			current.Date = value
+		case 'T', 'U':
+			if current.Amount == "" || text[0] == 'T' {
+				current.Amount = value
+			}
+		case 'P':
+			current.Payee = value
+		case 'M':
+			current.Memo = value
+		case 'N':
+			current.Number = value
+		case 'L':
+			current.Category = value
+		case '^':
+			transactions = append(transactions, *current)
+			current = nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// The last record of a file is sometimes left without its terminating caret.
+	if current != nil && current.Date != "" {
+		transactions = append(transactions, *current)
+	}
+
+	if !found {
+		return nil, ErrNotQIF
+	}
+
+	return transactions, nil
+}
+
+// ParseDate reads a QIF date such as 12/31/2025, 12/31/99, 1/ 5'25 or 2025-12-31. Quicken marks
+// two digit years of the 2000s with an apostrophe, others are read as 1970 to 2069. With dayFirst
+// the day comes before the month.
+func ParseDate(value string, dayFirst bool) (time.Time, error) {
+	invalid := errors.New("qif: invalid date " + strconv.Quote(value))
+
+	apostrophe := strings.Contains(value, "'")
+	parts := strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsDigit(r)
+	})
+	if len(parts) != 3 {
+		return time.Time{}, invalid
+	}
+
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil {
+			return time.Time{}, invalid
+		}
+		numbers[i] = number
+	}
+
+	var year, month, day int
+	switch {
+	case len(parts[0]) == 4:
+		year, month, day = numbers[0], numbers[1], numbers[2]
+	case dayFirst:
+		day, month, year = numbers[0], numbers[1], numbers[2]
+	default:
+		month, day, year = numbers[0], numbers[1], numbers[2]
+	}
+
+	if len(parts[0]) != 4 && len(parts[2]) <= 2 {
+		if apostrophe || year < 70 {
+			year += 2000
+		} else {
+			year += 1900
+		}
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Year() != year || int(date.Month()) != month || date.Day() != day {
+		return time.Time{}, invalid
+	}
+	return date, nil
+}
+
+func latin1(text string) string {
+	runes := make([]rune, len(text))
+	for i := 0; i < len(text); i++ {
+		runes[i] = rune(text[i])
+	}
+	return string(runes)
+}
@@ -0,0 +1,186 @@
+package qif
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	input := "\ufeff!Option:AutoSwitch\r\n" +
+		"!Account\r\n" +
+		"NChecking\r\n" +
+		"TBank\r\n" +
+		"D1/ 1'25\r\n" +
+		"^\r\n" +
+		"NVisa\r\n" +
+		"TCCard\r\n" +
+		"^\r\n" +
+		"!Clear:AutoSwitch\r\n" +
+		"!Type:Bank\r\n" +
+		"D1/ 5'25\r\n" +
+		"U-1,234.56\r\n" +
+		"T-1,234.56\r\n" +
+		"PLandlord\r\n" +
+		"MJanuary rent\r\n" +
+		"N1001\r\n" +
+		"LHousing:Rent\r\n" +
+		"^\r\n" +
+		"\r\n" +
+		"D1/ 6'25\r\n" +
+		"T25.00\r\n" +
+		"U99.00\r\n" +
+		"PRefund\r\n" +
+		"^\r\n" +
+		"D1/ 7'25\r\n" +
+		"U-3.50\r\n" +
+		"PCaf\xe9\r\n" +
+		"^\r\n" +
+		"!Type:Cat\r\n" +
+		"NHousing\r\n" +
+		"E\r\n" +
+		"^\r\n" +
+		"!Type:Invst\r\n" +
+		"D1/ 8'25\r\n" +
+		"NBuy\r\n" +
+		"T100.00\r\n" +
+		"^\r\n" +
+		"!Type:CCard\r\n" +
+		"D01/09/2025\r\n" +
+		"T-12.00\r\n" +
+		"PBookshop\r\n"
+
+	transactions, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Transaction{
+		{Line: 12, Date: "1/ 5'25", Amount: "-1,234.56", Payee: "Landlord", Memo: "January rent", Number: "1001", Category: "Housing:Rent"},
+		{Line: 21, Date: "1/ 6'25", Amount: "25.00", Payee: "Refund"},
+		{Line: 26, Date: "1/ 7'25", Amount: "-3.50", Payee: "Café"},
+		{Line: 40, Date: "01/09/2025", Amount: "-12.00", Payee: "Bookshop"},
+	}
+
+	if !reflect.DeepEqual(transactions, want) {
+		t.Fatalf("Parse() =\n%+v\nwant\n%+v", transactions, want)
+	}
+}
+
+func TestParseAmountPrecedence(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+		want   string
+	}{
+		{"T only", "T1.00\n", "1.00"},
+		{"U only", "U2.00\n", "2.00"},
+		{"U before T", "U2.00\nT1.00\n", "1.00"},
+		{"T before U", "T1.00\nU2.00\n", "1.00"},
+		{"two U", "U2.00\nU3.00\n", "2.00"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transactions, err := Parse(strings.NewReader("!Type:Bank\nD1/1/25\n" + test.record + "^\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(transactions) != 1 || transactions[0].Amount != test.want {
+				t.Fatalf("Parse() = %+v, want amount %s", transactions, test.want)
+			}
+		})
+	}
+}
+
+func TestParseNotQIF(t *testing.T) {
+	inputs := []string{
+		"",
+		"Date,Amount\n2025-01-01,1\n",
+		"!Account\nNChecking\nTBank\n^\n",
+		"!Type:Invst\nD1/1/25\nT1\n^\n",
+		"!Type:Cat\nNFood\n^\n",
+	}
+
+	for _, input := range inputs {
+		if _, err := Parse(strings.NewReader(input)); !errors.Is(err, ErrNotQIF) {
+			t.Errorf("Parse(%q) error = %v, want %v", input, err, ErrNotQIF)
+		}
+	}
+}
+
+func TestParseEmptyAccount(t *testing.T) {
+	transactions, err := Parse(strings.NewReader("!Type:Oth A\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 0 {
+		t.Fatalf("Parse() = %+v, want no transactions", transactions)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value    string
+		dayFirst bool
+		want     time.Time
+	}{
+		{"12/31/2025", false, date(2025, time.December, 31)},
+		{"31/12/2025", true, date(2025, time.December, 31)},
+		{"1/ 5'03", false, date(2003, time.January, 5)},
+		{"1/ 5'03", true, date(2003, time.May, 1)},
+		{" 1/ 5' 3", false, date(2003, time.January, 5)},
+		{"01/05/25", false, date(2025, time.January, 5)},
+		{"01/05/25", true, date(2025, time.May, 1)},
+		{"12/31/99", false, date(1999, time.December, 31)},
+		{"1/1/69", false, date(2069, time.January, 1)},
+		{"1/1/70", false, date(1970, time.January, 1)},
+		{"1/1'99", false, date(2099, time.January, 1)},
+		{"1.2.2025", false, date(2025, time.January, 2)},
+		{"1-2-2025", true, date(2025, time.February, 1)},
+		{"2025-12-31", false, date(2025, time.December, 31)},
+		{"2025-12-31", true, date(2025, time.December, 31)},
+		{"2/29/2024", false, date(2024, time.February, 29)},
+	}
+
+	for _, test := range tests {
+		got, err := ParseDate(test.value, test.dayFirst)
+		if err != nil {
+			t.Errorf("ParseDate(%q, %t) error = %v", test.value, test.dayFirst, err)
+			continue
+		}
+		if !got.Equal(test.want) {
+			t.Errorf("ParseDate(%q, %t) = %s, want %s", test.value, test.dayFirst, got.Format("2006-01-02"), test.want.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestParseDateInvalid(t *testing.T) {
+	tests := []struct {
+		value    string
+		dayFirst bool
+	}{
+		{"", false},
+		{"1/2", false},
+		{"1/2/3/4", false},
+		{"31/12/2025", false},
+		{"12/31/2025", true},
+		{"2/29/2025", false},
+		{"0/1/2025", false},
+		{"13/13/2025", true},
+		{"2025-02-30", false},
+		{"yesterday", false},
+	}
+
+	for _, test := range tests {
+		if got, err := ParseDate(test.value, test.dayFirst); err == nil {
+			t.Errorf("ParseDate(%q, %t) = %s, want an error", test.value, test.dayFirst, got.Format("2006-01-02"))
+		}
+	}
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often stores forget buckets that have refilled.
+const sweepInterval = time.Minute
+
+// MemoryStore keeps buckets in process memory. Each server instance counts requests separately.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+func (s *MemoryStore) Take(key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = newBucket(limit, now)
+		s.buckets[key] = b
+	}
+
+	return b.take(limit, now), nil
+}
+
+// sweep drops full buckets, which behave the same as missing ones.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if !b.expiresAt.After(now) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table so that every server instance
+// shares them.
+type PostgresStore struct {
+	db        *sql.DB
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Take(key string, limit Limit, now time.Time) (Result, error) {
+	s.sweep(now)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Result{}, err
+	}
+	defer tx.Rollback()
+
+	// The no-op update locks an existing row so that concurrent requests for the key wait their turn.
+	b := newBucket(limit, now)
+	query := "INSERT INTO rate_limit_buckets (key, tokens, updated_at, expires_at) VALUES ($1, $2, $3, $3) ON CONFLICT (key) DO UPDATE SET key = EXCLUDED.key RETURNING tokens, updated_at"
+	if err := tx.QueryRow(query, key, b.tokens, now).Scan(&b.tokens, &b.updatedAt); err != nil {
+		return Result{}, err
+	}
+
+	result := b.take(limit, now)
+
+	query = "UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3, expires_at = $4 WHERE key = $1"
+	if _, err := tx.Exec(query, key, b.tokens, b.updatedAt, b.expiresAt); err != nil {
+		return Result{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Result{}, err
+	}
+
+	return result, nil
+}
+
+// sweep deletes full buckets in the background at most once per sweepInterval.
+func (s *PostgresStore) sweep(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	go func() {
+		if _, err := s.db.Exec("DELETE FROM rate_limit_buckets WHERE expires_at <= $1", now); err != nil {
+			log.Println("Error deleting expired rate limit buckets:", err)
+		}
+	}()
+}
@@ -0,0 +1,81 @@
+// Package ratelimit implements a token bucket rate limiter. Buckets live in a Store so that
+// several server instances can share them.
+//
+// A bucket holds up to Limit.Max tokens and refills at Limit.Max tokens per Limit.Period. Every
+// request spends one token, so clients can burst up to Max requests and are then held to the
+// average rate.
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// Limit is the size and refill period of a bucket.
+type Limit struct {
+	Max    int
+	Period time.Duration
+}
+
+// rate returns how many tokens the bucket regains per second.
+func (l Limit) rate() float64 {
+	return float64(l.Max) / l.Period.Seconds()
+}
+
+// Result describes the bucket after a request was counted.
+type Result struct {
+	Allowed bool
+	Limit   int
+	// Remaining is the number of whole tokens left.
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed. It is zero when the request was allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps buckets by key.
+type Store interface {
+	// Take spends a token from the key's bucket, creating a full bucket on first use.
+	Take(key string, limit Limit, now time.Time) (Result, error)
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	// expiresAt is when the bucket is full again and can be forgotten.
+	expiresAt time.Time
+}
+
+func newBucket(limit Limit, now time.Time) *bucket {
+	return &bucket{tokens: float64(limit.Max), updatedAt: now, expiresAt: now}
+}
+
+// take refills the bucket for the time since its last update and spends one token if there is one.
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	rate := limit.rate()
+
+	// Instances may disagree slightly about the time, so a bucket never moves backwards.
+	if now.After(b.updatedAt) {
+		b.tokens = math.Min(float64(limit.Max), b.tokens+now.Sub(b.updatedAt).Seconds()*rate)
+		b.updatedAt = now
+	}
+
+	result := Result{Limit: limit.Max}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((float64(limit.Max) - b.tokens) / rate)
+	b.expiresAt = b.updatedAt.Add(result.Reset)
+
+	return result
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,248 @@
+// Package recurrence computes the dates on which a recurring transaction occurs.
+//
+// Occurrences are numbered from zero starting at the rule's start date, so the same rule always
+// produces the same sequence of dates. Every date is a calendar day at midnight UTC.
+package recurrence
+
+import (
+	"errors"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily     Frequency = "daily"
+	Weekly    Frequency = "weekly"
+	Biweekly  Frequency = "biweekly"
+	Monthly   Frequency = "monthly"
+	Quarterly Frequency = "quarterly"
+	Yearly    Frequency = "yearly"
+)
+
+var (
+	ErrInvalidFrequency      = errors.New("frequency must be one of daily, weekly, biweekly, monthly, quarterly or yearly")
+	ErrInvalidInterval       = errors.New("interval must be between 1 and 366")
+	ErrInvalidWeekday        = errors.New("weekday must be between 0 (Sunday) and 6 (Saturday)")
+	ErrInvalidDayOfMonth     = errors.New("day of month must be between 1 and 31")
+	ErrInvalidEndDate        = errors.New("end date must not be before the start date")
+	ErrInvalidMaxOccurrences = errors.New("max occurrences must be at least 1")
+)
+
+// Rule describes when a recurring transaction occurs.
+//
+// Weekday only applies to weekly and biweekly rules and defaults to the start date's weekday.
+// DayOfMonth applies to monthly, quarterly and yearly rules and is clamped to the length of short
+// months; LastDayOfMonth overrides it. Yearly rules occur in the start date's month.
+type Rule struct {
+	Frequency      Frequency
+	Interval       int
+	Weekday        *time.Weekday
+	DayOfMonth     int
+	LastDayOfMonth bool
+	StartDate      time.Time
+	EndDate        *time.Time
+	MaxOccurrences *int
+}
+
+// Occurrence is one scheduled date of a rule together with its position in the sequence.
+type Occurrence struct {
+	Index int       `json:"index"`
+	Date  time.Time `json:"date"`
+}
+
+// Date truncates t to its calendar day at midnight UTC.
+func Date(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func (r Rule) Validate() error {
+	switch r.Frequency {
+	case Daily, Weekly, Biweekly, Monthly, Quarterly, Yearly:
+	default:
+		return ErrInvalidFrequency
+	}
+
+	if r.Interval < 1 || r.Interval > 366 {
+		return ErrInvalidInterval
+	}
+
+	if r.Weekday != nil && (*r.Weekday < time.Sunday || *r.Weekday > time.Saturday) {
+		return ErrInvalidWeekday
+	}
+
+	if r.monthStep() > 0 && !r.LastDayOfMonth && (r.DayOfMonth < 1 || r.DayOfMonth > 31) {
+		return ErrInvalidDayOfMonth
+	}
+
+	if r.EndDate != nil && Date(*r.EndDate).Before(Date(r.StartDate)) {
+		return ErrInvalidEndDate
+	}
+
+	if r.MaxOccurrences != nil && *r.MaxOccurrences < 1 {
+		return ErrInvalidMaxOccurrences
+	}
+
+	return nil
+}
+
+// dayStep is the number of days between occurrences of day based rules.
+func (r Rule) dayStep() int {
+	switch r.Frequency {
+	case Daily:
+		return r.Interval
+	case Weekly:
+		return 7 * r.Interval
+	case Biweekly:
+		return 14 * r.Interval
+	}
+	return 0
+}
+
+// monthStep is the number of months between occurrences of month based rules.
+func (r Rule) monthStep() int {
+	switch r.Frequency {
+	case Monthly:
+		return r.Interval
+	case Quarterly:
+		return 3 * r.Interval
+	case Yearly:
+		return 12 * r.Interval
+	}
+	return 0
+}
+
+// dayIn returns the rule's day in the given month, clamped to the month's length.
+func (r Rule) dayIn(year int, month time.Month) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	lastDay := first.AddDate(0, 1, -1).Day()
+
+	day := r.DayOfMonth
+	if r.LastDayOfMonth || day > lastDay {
+		day = lastDay
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// first returns the date of occurrence 0, the first matching day on or after the start date.
+func (r Rule) first() time.Time {
+	start := Date(r.StartDate)
+
+	if r.Frequency == Daily {
+		return start
+	}
+
+	if r.dayStep() > 0 {
+		weekday := start.Weekday()
+		if r.Weekday != nil {
+			weekday = *r.Weekday
+		}
+		return start.AddDate(0, 0, (int(weekday)-int(start.Weekday())+7)%7)
+	}
+
+	candidate := r.dayIn(start.Year(), start.Month())
+	if candidate.Before(start) {
+		next := time.Date(start.Year(), start.Month()+time.Month(r.monthStep()), 1, 0, 0, 0, 0, time.UTC)
+		return r.dayIn(next.Year(), next.Month())
+	}
+	return candidate
+}
+
+// at returns the date of the occurrence with the given index, ignoring the end date and max occurrences.
+func (r Rule) at(index int) time.Time {
+	first := r.first()
+
+	if step := r.dayStep(); step > 0 {
+		return first.AddDate(0, 0, step*index)
+	}
+
+	month := time.Date(first.Year(), first.Month()+time.Month(r.monthStep()*index), 1, 0, 0, 0, 0, time.UTC)
+	return r.dayIn(month.Year(), month.Month())
+}
+
+// inRange reports whether the occurrence with the given index and date is allowed by the
+// rule's end date and maximum number of occurrences.
+func (r Rule) inRange(index int, date time.Time) bool {
+	if r.MaxOccurrences != nil && index >= *r.MaxOccurrences {
+		return false
+	}
+	if r.EndDate != nil && date.After(Date(*r.EndDate)) {
+		return false
+	}
+	return true
+}
+
+// At returns the occurrence with the given index, or false when the rule has ended before it.
+func (r Rule) At(index int) (time.Time, bool) {
+	if index < 0 {
+		return time.Time{}, false
+	}
+
+	date := r.at(index)
+	return date, r.inRange(index, date)
+}
+
+// indexOnOrAfter returns the index of the first occurrence on or after date.
+func (r Rule) indexOnOrAfter(date time.Time) int {
+	date = Date(date)
+	first := r.first()
+	if !first.Before(date) {
+		return 0
+	}
+
+	var index int
+	if step := r.dayStep(); step > 0 {
+		days := int(date.Sub(first).Hours() / 24)
+		index = (days + step - 1) / step
+	} else {
+		months := (date.Year()-first.Year())*12 + int(date.Month()) - int(first.Month())
+		index = months / r.monthStep()
+	}
+
+	for index > 0 && !r.at(index-1).Before(date) {
+		index--
+	}
+	for r.at(index).Before(date) {
+		index++
+	}
+	return index
+}
+
+// Next returns the first occurrence on or after date, or false when the rule has no more occurrences.
+func (r Rule) Next(date time.Time) (Occurrence, bool) {
+	index := r.indexOnOrAfter(date)
+	occurrence := r.at(index)
+	if !r.inRange(index, occurrence) {
+		return Occurrence{}, false
+	}
+	return Occurrence{Index: index, Date: occurrence}, true
+}
+
+// Between returns the occurrences from one date to another, both inclusive, in order.
+func (r Rule) Between(from time.Time, to time.Time) []Occurrence {
+	to = Date(to)
+
+	var occurrences []Occurrence
+	for index := r.indexOnOrAfter(from); ; index++ {
+		date := r.at(index)
+		if date.After(to) || !r.inRange(index, date) {
+			break
+		}
+		occurrences = append(occurrences, Occurrence{Index: index, Date: date})
+	}
+	return occurrences
+}
+
+// Upcoming returns at most count occurrences on or after date.
+func (r Rule) Upcoming(date time.Time, count int) []Occurrence {
+	occurrences := make([]Occurrence, 0, count)
+	for index := r.indexOnOrAfter(date); len(occurrences) < count; index++ {
+		occurrence := r.at(index)
+		if !r.inRange(index, occurrence) {
+			break
+		}
+		occurrences = append(occurrences, Occurrence{Index: index, Date: occurrence})
+	}
+	return occurrences
+}
@@ -0,0 +1,264 @@
+package recurrence
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func weekday(day time.Weekday) *time.Weekday {
+	return &day
+}
+
+func maxOccurrences(count int) *int {
+	return &count
+}
+
+func endDate(year int, month time.Month, day int) *time.Time {
+	end := date(year, month, day)
+	return &end
+}
+
+func TestRuleAt(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		want []time.Time
+	}{
+		{
+			name: "daily every third day",
+			rule: Rule{Frequency: Daily, Interval: 3, StartDate: time.Date(2025, time.January, 30, 18, 45, 0, 0, time.UTC)},
+			want: []time.Time{date(2025, time.January, 30), date(2025, time.February, 2), date(2025, time.February, 5)},
+		},
+		{
+			name: "weekly on the start date's weekday",
+			rule: Rule{Frequency: Weekly, Interval: 1, StartDate: date(2025, time.January, 1)},
+			want: []time.Time{date(2025, time.January, 1), date(2025, time.January, 8), date(2025, time.January, 15)},
+		},
+		{
+			name: "biweekly on a later weekday",
+			rule: Rule{Frequency: Biweekly, Interval: 1, Weekday: weekday(time.Friday), StartDate: date(2025, time.January, 1)},
+			want: []time.Time{date(2025, time.January, 3), date(2025, time.January, 17), date(2025, time.January, 31)},
+		},
+		{
+			name: "biweekly on an earlier weekday",
+			rule: Rule{Frequency: Biweekly, Interval: 1, Weekday: weekday(time.Monday), StartDate: date(2025, time.January, 1)},
+			want: []time.Time{date(2025, time.January, 6), date(2025, time.January, 20), date(2025, time.February, 3)},
+		},
+		{
+			name: "monthly on the 31st",
+			rule: Rule{Frequency: Monthly, Interval: 1, DayOfMonth: 31, StartDate: date(2025, time.January, 15)},
+			want: []time.Time{date(2025, time.January, 31), date(2025, time.February, 28), date(2025, time.March, 31), date(2025, time.April, 30)},
+		},
+		{
+			name: "monthly on the 31st in a leap year",
+			rule: Rule{Frequency: Monthly, Interval: 1, DayOfMonth: 31, StartDate: date(2024, time.January, 31)},
+			want: []time.Time{date(2024, time.January, 31), date(2024, time.February, 29), date(2024, time.March, 31)},
+		},
+		{
+			name: "monthly on the 30th after a short month",
+			rule: Rule{Frequency: Monthly, Interval: 1, DayOfMonth: 30, StartDate: date(2025, time.February, 1)},
+			want: []time.Time{date(2025, time.February, 28), date(2025, time.March, 30), date(2025, time.April, 30)},
+		},
+		{
+			name: "every second month starting after the day",
+			rule: Rule{Frequency: Monthly, Interval: 2, DayOfMonth: 15, StartDate: date(2025, time.January, 20)},
+			want: []time.Time{date(2025, time.March, 15), date(2025, time.May, 15), date(2025, time.July, 15)},
+		},
+		{
+			name: "quarterly on the last day",
+			rule: Rule{Frequency: Quarterly, Interval: 1, DayOfMonth: 1, LastDayOfMonth: true, StartDate: date(2025, time.January, 1)},
+			want: []time.Time{date(2025, time.January, 31), date(2025, time.April, 30), date(2025, time.July, 31), date(2025, time.October, 31)},
+		},
+		{
+			name: "yearly starting after the day",
+			rule: Rule{Frequency: Yearly, Interval: 1, DayOfMonth: 10, StartDate: date(2025, time.March, 20)},
+			want: []time.Time{date(2026, time.March, 10), date(2027, time.March, 10)},
+		},
+		{
+			name: "yearly on the 29th of February",
+			rule: Rule{Frequency: Yearly, Interval: 1, DayOfMonth: 29, StartDate: date(2024, time.February, 1)},
+			want: []time.Time{date(2024, time.February, 29), date(2025, time.February, 28), date(2026, time.February, 28), date(2027, time.February, 28), date(2028, time.February, 29)},
+		},
+		{
+			name: "yearly across the end of the year",
+			rule: Rule{Frequency: Yearly, Interval: 2, DayOfMonth: 5, StartDate: date(2025, time.December, 6)},
+			want: []time.Time{date(2027, time.December, 5), date(2029, time.December, 5)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.rule.Validate(); err != nil {
+				t.Fatal(err)
+			}
+
+			for index, want := range test.want {
+				got, ok := test.rule.At(index)
+				if !ok || !got.Equal(want) {
+					t.Errorf("At(%d) = %s, %t, want %s", index, got.Format("2006-01-02"), ok, want.Format("2006-01-02"))
+				}
+			}
+		})
+	}
+}
+
+func TestRuleLimits(t *testing.T) {
+	rule := Rule{Frequency: Daily, Interval: 1, StartDate: date(2025, time.January, 1), MaxOccurrences: maxOccurrences(3)}
+
+	if _, ok := rule.At(2); !ok {
+		t.Error("At(2) has ended, want the last occurrence")
+	}
+	if _, ok := rule.At(3); ok {
+		t.Error("At(3) is in range beyond MaxOccurrences")
+	}
+	if _, ok := rule.At(-1); ok {
+		t.Error("At(-1) is in range")
+	}
+	if occurrence, ok := rule.Next(date(2025, time.January, 3)); !ok || occurrence.Index != 2 {
+		t.Errorf("Next() = %+v, %t, want index 2", occurrence, ok)
+	}
+	if occurrence, ok := rule.Next(date(2025, time.January, 4)); ok {
+		t.Errorf("Next() = %+v after the last occurrence", occurrence)
+	}
+
+	rule = Rule{Frequency: Monthly, Interval: 1, DayOfMonth: 31, StartDate: date(2025, time.January, 1), EndDate: endDate(2025, time.April, 30)}
+
+	if got, ok := rule.At(3); !ok || !got.Equal(date(2025, time.April, 30)) {
+		t.Errorf("At(3) = %s, %t, want the end date", got.Format("2006-01-02"), ok)
+	}
+	if _, ok := rule.At(4); ok {
+		t.Error("At(4) is in range after the end date")
+	}
+}
+
+func TestRuleBetween(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     Rule
+		from, to time.Time
+		want     []Occurrence
+	}{
+		{
+			name: "across the end date",
+			rule: Rule{Frequency: Monthly, Interval: 1, DayOfMonth: 31, StartDate: date(2025, time.January, 1), EndDate: endDate(2025, time.April, 15)},
+			from: date(2025, time.February, 1),
+			to:   date(2025, time.December, 31),
+			want: []Occurrence{{Index: 1, Date: date(2025, time.February, 28)}, {Index: 2, Date: date(2025, time.March, 31)}},
+		},
+		{
+			name: "both ends inclusive",
+			rule: Rule{Frequency: Weekly, Interval: 1, Weekday: weekday(time.Monday), StartDate: date(2025, time.January, 1)},
+			from: date(2025, time.January, 13),
+			to:   time.Date(2025, time.January, 27, 23, 0, 0, 0, time.UTC),
+			want: []Occurrence{{Index: 1, Date: date(2025, time.January, 13)}, {Index: 2, Date: date(2025, time.January, 20)}, {Index: 3, Date: date(2025, time.January, 27)}},
+		},
+		{
+			name: "before the start date",
+			rule: Rule{Frequency: Daily, Interval: 1, StartDate: date(2025, time.January, 30), MaxOccurrences: maxOccurrences(2)},
+			from: date(2024, time.December, 1),
+			to:   date(2025, time.February, 28),
+			want: []Occurrence{{Index: 0, Date: date(2025, time.January, 30)}, {Index: 1, Date: date(2025, time.January, 31)}},
+		},
+		{
+			name: "between two occurrences",
+			rule: Rule{Frequency: Quarterly, Interval: 1, DayOfMonth: 1, StartDate: date(2025, time.January, 1)},
+			from: date(2025, time.January, 2),
+			to:   date(2025, time.March, 31),
+			want: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.rule.Between(test.from, test.to); !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("Between() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+// TestRuleNext checks Next on every day of a span against the occurrences listed one by one, so
+// the estimate of the first index on or after a date is never off.
+func TestRuleNext(t *testing.T) {
+	rules := []Rule{
+		{Frequency: Daily, Interval: 5, StartDate: date(2024, time.February, 27)},
+		{Frequency: Weekly, Interval: 3, Weekday: weekday(time.Sunday), StartDate: date(2024, time.March, 1)},
+		{Frequency: Biweekly, Interval: 1, Weekday: weekday(time.Thursday), StartDate: date(2024, time.December, 30)},
+		{Frequency: Monthly, Interval: 1, DayOfMonth: 31, StartDate: date(2024, time.January, 31)},
+		{Frequency: Monthly, Interval: 5, DayOfMonth: 29, StartDate: date(2024, time.March, 30)},
+		{Frequency: Quarterly, Interval: 1, LastDayOfMonth: true, StartDate: date(2024, time.February, 10)},
+		{Frequency: Yearly, Interval: 1, DayOfMonth: 29, StartDate: date(2024, time.February, 29)},
+	}
+
+	for _, rule := range rules {
+		var occurrences []time.Time
+		for index := 0; ; index++ {
+			occurrence, _ := rule.At(index)
+			occurrences = append(occurrences, occurrence)
+			if occurrence.Year() > 2027 {
+				break
+			}
+		}
+
+		for day := date(2024, time.January, 1); day.Year() < 2027; day = day.AddDate(0, 0, 1) {
+			want := 0
+			for occurrences[want].Before(day) {
+				want++
+			}
+
+			got, ok := rule.Next(day)
+			if !ok || got.Index != want || !got.Date.Equal(occurrences[want]) {
+				t.Fatalf("%s rule: Next(%s) = %+v, %t, want index %d on %s", rule.Frequency, day.Format("2006-01-02"), got, ok, want, occurrences[want].Format("2006-01-02"))
+			}
+		}
+	}
+}
+
+func TestRuleUpcoming(t *testing.T) {
+	rule := Rule{Frequency: Monthly, Interval: 1, DayOfMonth: 31, StartDate: date(2025, time.January, 1), MaxOccurrences: maxOccurrences(3)}
+
+	want := []Occurrence{{Index: 1, Date: date(2025, time.February, 28)}, {Index: 2, Date: date(2025, time.March, 31)}}
+	if got := rule.Upcoming(date(2025, time.February, 1), 5); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Upcoming() = %+v, want %+v", got, want)
+	}
+	if got := rule.Upcoming(date(2025, time.January, 1), 1); len(got) != 1 || got[0].Index != 0 {
+		t.Fatalf("Upcoming() = %+v, want the first occurrence only", got)
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	valid := Rule{Frequency: Monthly, Interval: 1, DayOfMonth: 31, StartDate: date(2025, time.January, 1)}
+
+	tests := []struct {
+		name   string
+		change func(rule *Rule)
+		want   error
+	}{
+		{"valid", func(rule *Rule) {}, nil},
+		{"last day without a day", func(rule *Rule) { rule.DayOfMonth = 0; rule.LastDayOfMonth = true }, nil},
+		{"daily ignores the day", func(rule *Rule) { rule.Frequency = Daily; rule.DayOfMonth = 0 }, nil},
+		{"end on the start date", func(rule *Rule) { rule.EndDate = endDate(2025, time.January, 1) }, nil},
+		{"unknown frequency", func(rule *Rule) { rule.Frequency = "hourly" }, ErrInvalidFrequency},
+		{"zero interval", func(rule *Rule) { rule.Interval = 0 }, ErrInvalidInterval},
+		{"interval too large", func(rule *Rule) { rule.Interval = 367 }, ErrInvalidInterval},
+		{"weekday out of range", func(rule *Rule) { rule.Frequency = Weekly; rule.Weekday = weekday(7) }, ErrInvalidWeekday},
+		{"day 0", func(rule *Rule) { rule.DayOfMonth = 0 }, ErrInvalidDayOfMonth},
+		{"day 32", func(rule *Rule) { rule.DayOfMonth = 32 }, ErrInvalidDayOfMonth},
+		{"end before the start", func(rule *Rule) { rule.EndDate = endDate(2024, time.December, 31) }, ErrInvalidEndDate},
+		{"no occurrences", func(rule *Rule) { rule.MaxOccurrences = maxOccurrences(0) }, ErrInvalidMaxOccurrences},
+	}
+
+	for _, test := range tests {
+		rule := valid
+		test.change(&rule)
+		if err := rule.Validate(); !errors.Is(err, test.want) || (test.want == nil && err != nil) {
+			t.Errorf("%s: Validate() = %v, want %v", test.name, err, test.want)
+		}
+	}
+}
@@ -3,62 +3,50 @@ package scheduler
 import (
 	"database/sql"
 	"log"
-	"time"
 
 	"github.com/go-co-op/gocron"
-	"github.com/google/uuid"
-	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
-	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/services"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
 )
 
-func StartScheduler(db *sql.DB) {
-	s := gocron.NewScheduler(time.Local)
+func StartScheduler(db *sql.DB, cfg *config.Config) {
+	s := gocron.NewScheduler(utils.LOC)
 
-	s.Every(1).Day().At("00:00").Do(func() {
-		log.Println("Running recurring transaction check...")
-		ProcessRecurringTransactions(db)
-		log.Println("Complete for today.")
-	})
+	// Catch up on occurrences missed while the server was down.
+	go func() {
+		log.Println("Running recurring transaction catch-up...")
+		services.ProcessRecurringTransactions(db)
+		log.Println("Recurring transaction catch-up complete.")
+	}()
 
-	s.StartAsync()
-}
-
-func ProcessRecurringTransactions(db *sql.DB) {
-	recurringTransactions, err := repository.GetRecurringTransactions(db)
-	if err != nil {
-		log.Println("Error getting recurring transactions:", err)
-		return
-	}
+	// Users live in different time zones, so the check runs often enough to post each occurrence
+	// shortly after the owner's local midnight.
+	s.Every(15).Minutes().WaitForSchedule().SingletonMode().Do(func() {
+		services.ProcessRecurringTransactions(db)
+	})
 
-	for _, rt := range recurringTransactions {
-		today := time.Now().In(utils.LOC).Day()
+	s.Every(1).Day().At("00:30").Do(func() {
+		log.Println("Running budget alert check...")
+		services.CheckAllBudgetAlerts(db)
+		log.Println("Budget alert check complete.")
+	})
 
-		if rt.RecurringFrequency == models.Monthly && rt.RecurringDate == today {
-			createTransactionFromRecurring(rt, db)
-		} else if rt.RecurringFrequency == models.Yearly && rt.RecurringDate == today && time.Now().In(utils.LOC).Month() == rt.CreatedAt.Month() {
-			createTransactionFromRecurring(rt, db)
-		}
-	}
-}
+	s.Every(1).Day().At("03:00").Do(func() {
+		log.Println("Running auth cleanup...")
+		services.CleanupSessions(db)
+		services.CleanupOAuthStates(db)
+		services.CleanupUserTokens(db)
+		services.CleanupTwoFactorChallenges(db)
+		services.CleanupLoginAttempts(db)
+		log.Println("Auth cleanup complete.")
+	})
 
-func createTransactionFromRecurring(rt models.RecurringTransaction, db *sql.DB) {
-	transaction := &models.Transaction{
-		ID:              uuid.New(),
-		UserID:          rt.UserID,
-		AccountID:       rt.AccountID,
-		CategoryID:      rt.CategoryID,
-		BudgetID:        rt.BudgetID,
-		Description:     rt.Description,
-		Amount:          rt.Amount,
-		Type:            rt.Type,
-		Note:            rt.Note,
-		TransactionDate: time.Now().In(utils.LOC),
-		CreatedAt:       time.Now().In(utils.LOC),
-		UpdatedAt:       time.Now().In(utils.LOC),
-	}
+	s.Every(1).Day().At("04:00").Do(func() {
+		log.Println("Running account deletion...")
+		services.PurgeDeletedUsers(db, cfg)
+		log.Println("Account deletion complete.")
+	})
 
-	if err := repository.CreateTransaction(transaction, db); err != nil {
-		log.Println("Error creating transaction from recurring:", err)
-	}
+	s.StartAsync()
 }
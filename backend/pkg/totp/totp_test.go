@@ -0,0 +1,137 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key of the RFC 6238 test vectors, the ASCII string "12345678901234567890".
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeAtRFC6238(t *testing.T) {
+	// The RFC lists 8 digit codes; a 6 digit code is their last 6 digits.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, test := range tests {
+		step := Step(time.Unix(test.unix, 0))
+		for _, secret := range []string{rfcSecret, strings.ToLower(rfcSecret)} {
+			got, err := CodeAt(secret, step)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("CodeAt(%s, %d) = %s, want %s", secret, step, got, test.want)
+			}
+		}
+	}
+}
+
+func TestCodeAtInvalidSecret(t *testing.T) {
+	for _, secret := range []string{"not base32!", "GEZDGNBV=", "1"} {
+		if _, err := CodeAt(secret, 1); err == nil {
+			t.Errorf("CodeAt(%q) succeeded, want an error", secret)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	code := func(step int64) string {
+		t.Helper()
+		code, err := CodeAt(rfcSecret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name   string
+		code   string
+		want   int64
+		wantOK bool
+	}{
+		{"current step", code(current), current, true},
+		{"one step behind", code(current - 1), current - 1, true},
+		{"one step ahead", code(current + 1), current + 1, true},
+		{"two steps behind", code(current - 2), 0, false},
+		{"two steps ahead", code(current + 2), 0, false},
+		{"with spaces", "050 471", current, true},
+		{"8 digit RFC code", "14050471", 0, false},
+		{"5 digits", "50471", 0, false},
+		{"7 digits", "0504710", 0, false},
+		{"empty", "", 0, false},
+		{"letters", "O5O471", 0, false},
+		{"wrong code", "123456", 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, test.code, now, 1)
+			if ok != test.wantOK || step != test.want {
+				t.Fatalf("Validate(%q) = %d, %t, want %d, %t", test.code, step, ok, test.want, test.wantOK)
+			}
+		})
+	}
+
+	if _, ok := Validate(rfcSecret, code(current-1), now, 0); ok {
+		t.Error("Validate() accepted the previous step without skew")
+	}
+	if _, ok := Validate("not base32!", code(current), now, 1); ok {
+		t.Error("Validate() accepted a code for an invalid secret")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := encoding.DecodeString(secret)
+	if err != nil || len(key) != secretSize {
+		t.Fatalf("GenerateSecret() = %q, decodes to %d bytes, %v", secret, len(key), err)
+	}
+
+	other, err := GenerateSecret()
+	if err != nil || other == secret {
+		t.Fatalf("GenerateSecret() returned %q twice", secret)
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("Finance Tracker", "user@example.com", rfcSecret)
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" || parsed.Path != "/Finance Tracker:user@example.com" {
+		t.Fatalf("ProvisioningURI() = %s", uri)
+	}
+	if strings.Contains(uri, "+") {
+		t.Errorf("ProvisioningURI() = %s, encodes spaces as +", uri)
+	}
+
+	query := parsed.Query()
+	want := map[string]string{"secret": rfcSecret, "issuer": "Finance Tracker", "algorithm": "SHA1", "digits": "6", "period": "30"}
+	for key, value := range want {
+		if query.Get(key) != value {
+			t.Errorf("ProvisioningURI() %s = %q, want %q", key, query.Get(key), value)
+		}
+	}
+}
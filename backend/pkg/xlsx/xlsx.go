@@ -0,0 +1,177 @@
+// Package xlsx writes single-sheet Office Open XML spreadsheets. Rows are streamed into the
+// archive as they are written, so large sheets are never held in memory.
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type cellKind int
+
+const (
+	kindString cellKind = iota
+	kindNumber
+	kindDate
+)
+
+// Cell is one value in a row.
+type Cell struct {
+	kind  cellKind
+	value string
+	date  time.Time
+}
+
+// String is a text cell.
+func String(value string) Cell {
+	return Cell{kind: kindString, value: value}
+}
+
+// Number is a numeric cell holding a decimal such as "-12.50". It is written as is, so no
+// precision is lost through float64.
+func Number(value string) Cell {
+	return Cell{kind: kindNumber, value: value}
+}
+
+// Date is a calendar date shown as YYYY-MM-DD. The time of day is ignored.
+func Date(value time.Time) Cell {
+	return Cell{kind: kindDate, date: value}
+}
+
+// Style indexes into the cellXfs of styles.xml.
+const (
+	styleDate = 1
+	styleBold = 2
+)
+
+// excelEpoch is day zero of spreadsheet date serials.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+var staticParts = []struct{ name, content string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`},
+	{"xl/styles.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd"/></numFmts><fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs></styleSheet>`},
+}
+
+// Writer streams rows into the only sheet of a workbook.
+type Writer struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	rows  int
+}
+
+// NewWriter starts a workbook with one sheet of the given name.
+func NewWriter(w io.Writer, sheetName string) (*Writer, error) {
+	zw := zip.NewWriter(w)
+
+	for _, part := range staticParts {
+		if err := writePart(zw, part.name, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	var name strings.Builder
+	xml.EscapeText(&name, []byte(sheetName))
+	workbook := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="` + name.String() + `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	if err := writePart(zw, "xl/workbook.xml", workbook); err != nil {
+		return nil, err
+	}
+
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return nil, err
+	}
+
+	return &Writer{zw: zw, sheet: sheet}, nil
+}
+
+// WriteHeader writes a row of bold text cells.
+func (w *Writer) WriteHeader(titles ...string) error {
+	return w.writeRow(styleBold, titles, nil)
+}
+
+// WriteRow appends a row.
+func (w *Writer) WriteRow(cells ...Cell) error {
+	return w.writeRow(0, nil, cells)
+}
+
+func (w *Writer) writeRow(style int, titles []string, cells []Cell) error {
+	for _, title := range titles {
+		cells = append(cells, String(title))
+	}
+
+	w.rows++
+	row := strconv.Itoa(w.rows)
+
+	var b strings.Builder
+	b.WriteString(`<row r="` + row + `">`)
+	for i, cell := range cells {
+		b.WriteString(`<c r="` + columnName(i) + row + `"`)
+		if style != 0 {
+			b.WriteString(` s="` + strconv.Itoa(style) + `"`)
+		}
+
+		switch cell.kind {
+		case kindNumber:
+			b.WriteString(`><v>`)
+			xml.EscapeText(&b, []byte(cell.value))
+			b.WriteString(`</v></c>`)
+		case kindDate:
+			year, month, day := cell.date.Date()
+			serial := int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Sub(excelEpoch).Hours() / 24)
+			if style == 0 {
+				b.WriteString(` s="` + strconv.Itoa(styleDate) + `"`)
+			}
+			b.WriteString(`><v>` + strconv.Itoa(serial) + `</v></c>`)
+		default:
+			b.WriteString(` t="inlineStr"><is><t xml:space="preserve">`)
+			xml.EscapeText(&b, []byte(cell.value))
+			b.WriteString(`</t></is></c>`)
+		}
+	}
+	b.WriteString(`</row>`)
+
+	_, err := io.WriteString(w.sheet, b.String())
+	return err
+}
+
+// Close finishes the sheet and the archive. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if _, err := io.WriteString(w.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+func writePart(zw *zip.Writer, name string, content string) error {
+	part, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(part, content)
+	return err
+}
+
+// columnName returns the letters of the zero-based column index, e.g. A, Z, AA.
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
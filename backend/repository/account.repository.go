@@ -2,21 +2,24 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
 )
 
 func CreateAccount(account *models.Account, db interfaces.SqlExecutor) error {
-	query := fmt.Sprintf("INSERT INTO accounts (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)", models.AccountColumns)
-	_, err := db.Exec(query, account.ID, account.UserID, account.Name, account.Type, account.Balance, account.IsActive, account.CreatedAt, account.UpdatedAt)
+	query := fmt.Sprintf("INSERT INTO accounts (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)", models.AccountColumns)
+	_, err := db.Exec(query, account.ID, account.UserID, account.Name, account.Type, account.Balance, account.IsActive, account.CreatedAt, account.UpdatedAt, account.Currency)
 	return err
 }
 
 func GetAccountsByUserID(userID uuid.UUID, db interfaces.SqlExecutor) ([]models.Account, error) {
-	query := "SELECT * FROM accounts WHERE user_id = $1"
+	query := "SELECT " + models.AccountColumns + " FROM accounts WHERE user_id = $1"
 	rows, err := db.Query(query, userID)
 	if err != nil {
 		return nil, err
@@ -26,7 +29,7 @@ func GetAccountsByUserID(userID uuid.UUID, db interfaces.SqlExecutor) ([]models.
 	var accounts []models.Account
 	for rows.Next() {
 		var account models.Account
-		if err := rows.Scan(&account.ID, &account.UserID, &account.Name, &account.Type, &account.Balance, &account.IsActive, &account.CreatedAt, &account.UpdatedAt); err != nil {
+		if err := rows.Scan(&account.ID, &account.UserID, &account.Name, &account.Type, &account.Balance, &account.IsActive, &account.CreatedAt, &account.UpdatedAt, &account.Currency); err != nil {
 			return nil, err
 		}
 		accounts = append(accounts, account)
@@ -34,12 +37,12 @@ func GetAccountsByUserID(userID uuid.UUID, db interfaces.SqlExecutor) ([]models.
 	return accounts, nil
 }
 
-func GetAccountByID(id uuid.UUID, db interfaces.SqlExecutor) (*models.Account, error) {
-	query := "SELECT * FROM accounts WHERE id = $1"
-	row := db.QueryRow(query, id)
+func GetAccountByID(id uuid.UUID, userID uuid.UUID, db interfaces.SqlExecutor) (*models.Account, error) {
+	query := "SELECT " + models.AccountColumns + " FROM accounts WHERE id = $1 AND user_id = $2"
+	row := db.QueryRow(query, id, userID)
 
 	var account models.Account
-	if err := row.Scan(&account.ID, &account.UserID, &account.Name, &account.Type, &account.Balance, &account.IsActive, &account.CreatedAt, &account.UpdatedAt); err != nil {
+	if err := row.Scan(&account.ID, &account.UserID, &account.Name, &account.Type, &account.Balance, &account.IsActive, &account.CreatedAt, &account.UpdatedAt, &account.Currency); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Or a custom not found error
 		}
@@ -48,14 +51,41 @@ func GetAccountByID(id uuid.UUID, db interfaces.SqlExecutor) (*models.Account, e
 	return &account, nil
 }
 
+// UpdateAccount stores the account's details. The balance is left alone and the stored one is read
+// back into account; balances only change through AdjustAccountBalance.
 func UpdateAccount(account *models.Account, db interfaces.SqlExecutor) error {
-	query := "UPDATE accounts SET name = $1, type = $2, balance = $3, is_active = $4, updated_at = $5 WHERE id = $6"
-	_, err := db.Exec(query, account.Name, account.Type, account.Balance, account.IsActive, account.UpdatedAt, account.ID)
-	return err
+	query := "UPDATE accounts SET name = $1, type = $2, is_active = $3, updated_at = $4 WHERE id = $5 AND user_id = $6 RETURNING balance"
+	return db.QueryRow(query, account.Name, account.Type, account.IsActive, account.UpdatedAt, account.ID, account.UserID).Scan(&account.Balance)
+}
+
+// AdjustAccountBalance adds delta to the stored balance in a single statement so concurrent
+// updates inside a database transaction cannot overwrite each other. A balance beyond
+// models.MaxMoney fails with models.ErrMoneyOverflow, and the surrounding transaction has to be
+// rolled back.
+func AdjustAccountBalance(id uuid.UUID, userID uuid.UUID, delta models.Money, updatedAt time.Time, db interfaces.SqlExecutor) error {
+	query := "UPDATE accounts SET balance = balance + $1, updated_at = $2 WHERE id = $3 AND user_id = $4 RETURNING balance"
+
+	var balance models.Money
+	if err := db.QueryRow(query, delta, updatedAt, id, userID).Scan(&balance); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "22003" {
+			// numeric_value_out_of_range: the sum does not fit the NUMERIC(19, 4) column.
+			return models.ErrMoneyOverflow
+		}
+		return err
+	}
+	return nil
+}
+
+// LockAccount locks the account row until the surrounding database transaction ends, so imports
+// into the same account run one after another.
+func LockAccount(id uuid.UUID, userID uuid.UUID, db interfaces.SqlExecutor) error {
+	var locked uuid.UUID
+	return db.QueryRow("SELECT id FROM accounts WHERE id = $1 AND user_id = $2 FOR UPDATE", id, userID).Scan(&locked)
 }
 
-func DeleteAccount(id uuid.UUID, db interfaces.SqlExecutor) error {
-	query := "DELETE FROM accounts WHERE id = $1"
-	_, err := db.Exec(query, id)
+func DeleteAccount(id uuid.UUID, userID uuid.UUID, db interfaces.SqlExecutor) error {
+	query := "DELETE FROM accounts WHERE id = $1 AND user_id = $2"
+	_, err := db.Exec(query, id, userID)
 	return err
 }
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+)
+
+// RecordBudgetAlert remembers that a threshold fired for a budget period. It returns false when the
+// alert was already recorded, which is how each threshold is limited to once per period.
+func RecordBudgetAlert(budgetID uuid.UUID, periodStart time.Time, threshold int64, createdAt time.Time, db interfaces.SqlExecutor) (bool, error) {
+	query := "INSERT INTO budget_alerts (budget_id, period_start, threshold, created_at) VALUES ($1, $2, $3, $4) ON CONFLICT (budget_id, period_start, threshold) DO NOTHING"
+	result, err := db.Exec(query, budgetID, periodStart.Format("2006-01-02"), threshold, createdAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// GetUserIDsWithBudgets lists every user owning at least one budget, for the scheduled alert check.
+func GetUserIDsWithBudgets(db interfaces.SqlExecutor) ([]uuid.UUID, error) {
+	rows, err := db.Query("SELECT DISTINCT user_id FROM budgets")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, nil
+}
@@ -3,20 +3,22 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
 )
 
 func CreateBudget(budget *models.Budget, db interfaces.SqlExecutor) error {
-	query := fmt.Sprintf("INSERT INTO budgets (%s) VALUES ($1, $2, $3, $4, $5, $6)", models.BudgetColumns)
-	_, err := db.Exec(query, budget.ID, budget.UserID, budget.Name, budget.Amount, budget.CreatedAt, budget.UpdatedAt)
+	query := fmt.Sprintf("INSERT INTO budgets (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)", models.BudgetColumns)
+	_, err := db.Exec(query, budget.ID, budget.UserID, budget.Name, budget.Limit, budget.CreatedAt, budget.UpdatedAt, budget.Period, budget.StartDate, budget.EndDate, budget.CategoryID, budget.Rollover, pq.Array(budget.AlertThresholds))
 	return err
 }
 
 func GetBudgetsByUserID(userID uuid.UUID, db interfaces.SqlExecutor) ([]models.Budget, error) {
-	query := "SELECT * FROM budgets WHERE user_id = $1"
+	query := "SELECT " + models.BudgetColumns + " FROM budgets WHERE user_id = $1 ORDER BY created_at"
 	rows, err := db.Query(query, userID)
 	if err != nil {
 		return nil, err
@@ -26,7 +28,7 @@ func GetBudgetsByUserID(userID uuid.UUID, db interfaces.SqlExecutor) ([]models.B
 	var budgets []models.Budget
 	for rows.Next() {
 		var budget models.Budget
-		if err := rows.Scan(&budget.ID, &budget.UserID, &budget.Name, &budget.Amount, &budget.CreatedAt, &budget.UpdatedAt); err != nil {
+		if err := rows.Scan(&budget.ID, &budget.UserID, &budget.Name, &budget.Limit, &budget.CreatedAt, &budget.UpdatedAt, &budget.Period, &budget.StartDate, &budget.EndDate, &budget.CategoryID, &budget.Rollover, pq.Array(&budget.AlertThresholds)); err != nil {
 			return nil, err
 		}
 		budgets = append(budgets, budget)
@@ -34,12 +36,12 @@ func GetBudgetsByUserID(userID uuid.UUID, db interfaces.SqlExecutor) ([]models.B
 	return budgets, nil
 }
 
-func GetBudgetByID(id uuid.UUID, db interfaces.SqlExecutor) (*models.Budget, error) {
-	query := "SELECT * FROM budgets WHERE id = $1"
-	row := db.QueryRow(query, id)
+func GetBudgetByID(id uuid.UUID, userID uuid.UUID, db interfaces.SqlExecutor) (*models.Budget, error) {
+	query := "SELECT " + models.BudgetColumns + " FROM budgets WHERE id = $1 AND user_id = $2"
+	row := db.QueryRow(query, id, userID)
 
 	var budget models.Budget
-	if err := row.Scan(&budget.ID, &budget.UserID, &budget.Name, &budget.Amount, &budget.CreatedAt, &budget.UpdatedAt); err != nil {
+	if err := row.Scan(&budget.ID, &budget.UserID, &budget.Name, &budget.Limit, &budget.CreatedAt, &budget.UpdatedAt, &budget.Period, &budget.StartDate, &budget.EndDate, &budget.CategoryID, &budget.Rollover, pq.Array(&budget.AlertThresholds)); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Or a custom not found error
 		}
@@ -49,13 +51,46 @@ func GetBudgetByID(id uuid.UUID, db interfaces.SqlExecutor) (*models.Budget, err
 }
 
 func UpdateBudget(budget *models.Budget, db interfaces.SqlExecutor) error {
-	query := "UPDATE budgets SET name = $1, amount = $2, updated_at = $3 WHERE id = $4"
-	_, err := db.Exec(query, budget.Name, budget.Amount, budget.UpdatedAt, budget.ID)
+	query := "UPDATE budgets SET name = $1, amount = $2, updated_at = $3, period = $4, start_date = $5, end_date = $6, category_id = $7, rollover = $8, alert_thresholds = $9 WHERE id = $10 AND user_id = $11"
+	_, err := db.Exec(query, budget.Name, budget.Limit, budget.UpdatedAt, budget.Period, budget.StartDate, budget.EndDate, budget.CategoryID, budget.Rollover, pq.Array(budget.AlertThresholds), budget.ID, budget.UserID)
 	return err
 }
 
-func DeleteBudget(id uuid.UUID, db interfaces.SqlExecutor) error {
-	query := "DELETE FROM budgets WHERE id = $1"
-	_, err := db.Exec(query, id)
+func DeleteBudget(id uuid.UUID, userID uuid.UUID, db interfaces.SqlExecutor) error {
+	query := "DELETE FROM budgets WHERE id = $1 AND user_id = $2"
+	_, err := db.Exec(query, id, userID)
 	return err
 }
+
+// DeleteBudgetsByUserID removes all of the user's budgets.
+func DeleteBudgetsByUserID(userID uuid.UUID, db interfaces.SqlExecutor) error {
+	_, err := db.Exec("DELETE FROM budgets WHERE user_id = $1", userID)
+	return err
+}
+
+// GetBudgetSpendingByDate returns the expenses counted against a budget between two dates (inclusive),
+// summed per day (keyed by YYYY-MM-DD) and converted into baseCurrency. A transaction counts when it is linked to the budget
+// or, for category scoped budgets, when it belongs to the budget's category.
+func GetBudgetSpendingByDate(budget *models.Budget, baseCurrency models.Currency, startDate string, endDate string, db interfaces.SqlExecutor) (map[string]models.Money, error) {
+	query := fmt.Sprintf(`SELECT t.transaction_date, COALESCE(SUM(%s), 0) FROM transactions t
+		WHERE t.user_id = $1 AND t.type = 'expense'
+		AND (t.budget_id = $3 OR ($4::UUID IS NOT NULL AND t.category_id = $4::UUID))
+		AND t.transaction_date >= $5 AND t.transaction_date <= $6
+		GROUP BY t.transaction_date`, convertedAmountSQL("$2"))
+	rows, err := db.Query(query, budget.UserID, baseCurrency, budget.ID, budget.CategoryID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	spending := map[string]models.Money{}
+	for rows.Next() {
+		var date time.Time
+		var amount models.Money
+		if err := rows.Scan(&date, &amount); err != nil {
+			return nil, err
+		}
+		spending[date.Format("2006-01-02")] = amount
+	}
+	return spending, nil
+}
@@ -2,22 +2,32 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
 )
 
+// IsUniqueViolation reports whether err is a unique_violation, e.g. a category name that a
+// concurrent request stored first.
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
 func CreateCategory(category *models.Category, db interfaces.SqlExecutor) error {
-	query := fmt.Sprintf("INSERT INTO categories (%s) VALUES ($1, $2, $3)", models.CategoryColumns)
-	_, err := db.Exec(query, category.ID, category.Name, category.Type)
+	query := fmt.Sprintf("INSERT INTO categories (%s) VALUES ($1, $2, $3, $4)", models.CategoryColumns)
+	_, err := db.Exec(query, category.ID, category.Name, category.Type, category.UserID)
 	return err
 }
 
-func GetCategories(db interfaces.SqlExecutor) ([]models.Category, error) {
-	query := "SELECT * FROM categories"
-	rows, err := db.Query(query)
+// GetCategoriesByUserID returns the system categories merged with the user's own custom categories.
+func GetCategoriesByUserID(userID uuid.UUID, db interfaces.SqlExecutor) ([]models.Category, error) {
+	query := "SELECT " + models.CategoryColumns + " FROM categories WHERE user_id IS NULL OR user_id = $1 ORDER BY user_id NULLS FIRST, type, name"
+	rows, err := db.Query(query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -26,36 +36,55 @@ func GetCategories(db interfaces.SqlExecutor) ([]models.Category, error) {
 	var categories []models.Category
 	for rows.Next() {
 		var category models.Category
-		if err := rows.Scan(&category.ID, &category.Name, &category.Type); err != nil {
+		if err := rows.Scan(&category.ID, &category.Name, &category.Type, &category.UserID); err != nil {
 			return nil, err
 		}
+		category.IsSystem = !category.UserID.Valid
 		categories = append(categories, category)
 	}
 	return categories, nil
 }
 
-func GetCategoryByID(id uuid.UUID, db interfaces.SqlExecutor) (*models.Category, error) {
-	query := "SELECT * FROM categories WHERE id = $1"
-	row := db.QueryRow(query, id)
+// GetCategoryByID returns the category if it is a system category or belongs to the user.
+func GetCategoryByID(id uuid.UUID, userID uuid.UUID, db interfaces.SqlExecutor) (*models.Category, error) {
+	query := "SELECT " + models.CategoryColumns + " FROM categories WHERE id = $1 AND (user_id IS NULL OR user_id = $2)"
+	row := db.QueryRow(query, id, userID)
 
 	var category models.Category
-	if err := row.Scan(&category.ID, &category.Name, &category.Type); err != nil {
+	if err := row.Scan(&category.ID, &category.Name, &category.Type, &category.UserID); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Or a custom not found error
 		}
 		return nil, err
 	}
+	category.IsSystem = !category.UserID.Valid
+	return &category, nil
+}
+
+// GetCategoryByNameAndType looks up a category with the given name and type that is visible to the user.
+func GetCategoryByNameAndType(name string, categoryType models.TransactionType, userID uuid.UUID, db interfaces.SqlExecutor) (*models.Category, error) {
+	query := "SELECT " + models.CategoryColumns + " FROM categories WHERE LOWER(name) = LOWER($1) AND type = $2 AND (user_id IS NULL OR user_id = $3) LIMIT 1"
+	row := db.QueryRow(query, name, categoryType, userID)
+
+	var category models.Category
+	if err := row.Scan(&category.ID, &category.Name, &category.Type, &category.UserID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	category.IsSystem = !category.UserID.Valid
 	return &category, nil
 }
 
 func UpdateCategory(category *models.Category, db interfaces.SqlExecutor) error {
-	query := "UPDATE categories SET name = $1, type = $2 WHERE id = $3"
-	_, err := db.Exec(query, category.Name, category.Type, category.ID)
+	query := "UPDATE categories SET name = $1, type = $2 WHERE id = $3 AND user_id = $4"
+	_, err := db.Exec(query, category.Name, category.Type, category.ID, category.UserID)
 	return err
 }
 
-func DeleteCategory(id uuid.UUID, db interfaces.SqlExecutor) error {
-	query := "DELETE FROM categories WHERE id = $1"
-	_, err := db.Exec(query, id)
+func DeleteCategory(id uuid.UUID, userID uuid.UUID, db interfaces.SqlExecutor) error {
+	query := "DELETE FROM categories WHERE id = $1 AND user_id = $2"
+	_, err := db.Exec(query, id, userID)
 	return err
 }
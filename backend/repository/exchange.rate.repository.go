@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+)
+
+// convertedAmountSQL builds an expression converting t.amount from the transaction's currency into
+// the currency bound to the given placeholder. It uses the closest rate on or before the transaction
+// date, falling back to the earliest later rate, and inverts rates stored in the opposite direction.
+func convertedAmountSQL(currencyPlaceholder string) string {
+	return fmt.Sprintf(`(CASE WHEN t.currency = %[1]s::CHAR(3) THEN t.amount ELSE ROUND(t.amount * (
+		SELECT CASE WHEN er.base_currency = t.currency THEN er.rate ELSE 1 / er.rate END
+		FROM exchange_rates er
+		WHERE (er.base_currency = t.currency AND er.quote_currency = %[1]s::CHAR(3)) OR (er.base_currency = %[1]s::CHAR(3) AND er.quote_currency = t.currency)
+		ORDER BY (er.rate_date <= t.transaction_date) DESC, ABS(er.rate_date - t.transaction_date)
+		LIMIT 1), 4) END)`, currencyPlaceholder)
+}
+
+func UpsertExchangeRate(rate *models.ExchangeRate, db interfaces.SqlExecutor) error {
+	query := fmt.Sprintf(`INSERT INTO exchange_rates (%s) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (base_currency, quote_currency, rate_date) DO UPDATE SET rate = EXCLUDED.rate, updated_at = EXCLUDED.updated_at`, models.ExchangeRateColumns)
+	_, err := db.Exec(query, rate.ID, rate.BaseCurrency, rate.QuoteCurrency, rate.Rate, rate.RateDate, rate.CreatedAt, rate.UpdatedAt)
+	return err
+}
+
+func GetExchangeRates(baseCurrency string, quoteCurrency string, page int, limit int, db interfaces.SqlExecutor) ([]models.ExchangeRate, error) {
+	var query strings.Builder
+	query.WriteString("SELECT " + models.ExchangeRateColumns + " FROM exchange_rates WHERE 1 = 1")
+
+	args := []interface{}{}
+	argCount := 1
+
+	if baseCurrency != "" {
+		query.WriteString(fmt.Sprintf(" AND base_currency = $%d", argCount))
+		args = append(args, baseCurrency)
+		argCount++
+	}
+
+	if quoteCurrency != "" {
+		query.WriteString(fmt.Sprintf(" AND quote_currency = $%d", argCount))
+		args = append(args, quoteCurrency)
+		argCount++
+	}
+
+	query.WriteString(fmt.Sprintf(" ORDER BY rate_date DESC, base_currency, quote_currency LIMIT %d OFFSET %d", limit, (page-1)*limit))
+
+	rows, err := db.Query(query.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []models.ExchangeRate
+	for rows.Next() {
+		var rate models.ExchangeRate
+		if err := rows.Scan(&rate.ID, &rate.BaseCurrency, &rate.QuoteCurrency, &rate.Rate, &rate.RateDate, &rate.CreatedAt, &rate.UpdatedAt); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+// GetExchangeRate returns the rate converting one unit of `from` into `to` on the given date (YYYY-MM-DD),
+// using the same closest-rate rules as the aggregate queries. It returns nil when no rate is known for the pair.
+func GetExchangeRate(from models.Currency, to models.Currency, date string, db interfaces.SqlExecutor) (*models.Rate, error) {
+	query := `SELECT CASE WHEN base_currency = $1 THEN rate ELSE ROUND(1 / rate, 8) END
+		FROM exchange_rates
+		WHERE (base_currency = $1 AND quote_currency = $2) OR (base_currency = $2 AND quote_currency = $1)
+		ORDER BY (rate_date <= $3::DATE) DESC, ABS(rate_date - $3::DATE)
+		LIMIT 1`
+	row := db.QueryRow(query, from, to, date)
+
+	var rate models.Rate
+	if err := row.Scan(&rate); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// GetCurrenciesWithoutExchangeRate lists the currencies used by the user's accounts or transactions
+// that cannot be converted into baseCurrency because no rate exists for the pair in either direction.
+func GetCurrenciesWithoutExchangeRate(userID uuid.UUID, baseCurrency models.Currency, db interfaces.SqlExecutor) ([]models.Currency, error) {
+	query := `SELECT used.currency FROM (
+			SELECT currency FROM accounts WHERE user_id = $1
+			UNION
+			SELECT currency FROM transactions WHERE user_id = $1
+		) used
+		WHERE used.currency <> $2 AND NOT EXISTS (
+			SELECT 1 FROM exchange_rates er
+			WHERE (er.base_currency = used.currency AND er.quote_currency = $2) OR (er.base_currency = $2 AND er.quote_currency = used.currency)
+		)
+		ORDER BY used.currency`
+	rows, err := db.Query(query, userID, baseCurrency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var currencies []models.Currency
+	for rows.Next() {
+		var currency models.Currency
+		if err := rows.Scan(&currency); err != nil {
+			return nil, err
+		}
+		currencies = append(currencies, currency)
+	}
+	return currencies, nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+)
+
+// importProfileFields returns pointers to the profile's fields in the order of ImportProfileColumns.
+func importProfileFields(profile *models.ImportProfile) []interface{} {
+	return []interface{}{&profile.ID, &profile.UserID, &profile.Name, &profile.Delimiter, &profile.SkipRows, &profile.DateColumn, &profile.DateFormat, &profile.DescriptionColumn, &profile.AmountColumn, &profile.DebitColumn, &profile.CreditColumn, &profile.AmountSign, &profile.DecimalSeparator, &profile.NoteColumn, &profile.CategoryColumn, &profile.IncomeCategoryID, &profile.ExpenseCategoryID, &profile.CreatedAt, &profile.UpdatedAt}
+}
+
+func CreateImportProfile(profile *models.ImportProfile, db interfaces.SqlExecutor) error {
+	query := fmt.Sprintf("INSERT INTO import_profiles (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)", models.ImportProfileColumns)
+	_, err := db.Exec(query, profile.ID, profile.UserID, profile.Name, profile.Delimiter, profile.SkipRows, profile.DateColumn, profile.DateFormat, profile.DescriptionColumn, profile.AmountColumn, profile.DebitColumn, profile.CreditColumn, profile.AmountSign, profile.DecimalSeparator, profile.NoteColumn, profile.CategoryColumn, profile.IncomeCategoryID, profile.ExpenseCategoryID, profile.CreatedAt, profile.UpdatedAt)
+	return err
+}
+
+func GetImportProfilesByUserID(userID uuid.UUID, db interfaces.SqlExecutor) ([]models.ImportProfile, error) {
+	query := "SELECT " + models.ImportProfileColumns + " FROM import_profiles WHERE user_id = $1 ORDER BY name"
+	rows, err := db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := []models.ImportProfile{}
+	for rows.Next() {
+		var profile models.ImportProfile
+		if err := rows.Scan(importProfileFields(&profile)...); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, rows.Err()
+}
+
+func GetImportProfileByID(id uuid.UUID, userID uuid.UUID, db interfaces.SqlExecutor) (*models.ImportProfile, error) {
+	query := "SELECT " + models.ImportProfileColumns + " FROM import_profiles WHERE id = $1 AND user_id = $2"
+	row := db.QueryRow(query, id, userID)
+
+	var profile models.ImportProfile
+	if err := row.Scan(importProfileFields(&profile)...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// GetImportProfileByName looks up one of the user's profiles by name, ignoring case.
+func GetImportProfileByName(name string, userID uuid.UUID, db interfaces.SqlExecutor) (*models.ImportProfile, error) {
+	query := "SELECT " + models.ImportProfileColumns + " FROM import_profiles WHERE LOWER(name) = LOWER($1) AND user_id = $2 LIMIT 1"
+	row := db.QueryRow(query, name, userID)
+
+	var profile models.ImportProfile
+	if err := row.Scan(importProfileFields(&profile)...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func UpdateImportProfile(profile *models.ImportProfile, db interfaces.SqlExecutor) error {
+	query := "UPDATE import_profiles SET name = $1, delimiter = $2, skip_rows = $3, date_column = $4, date_format = $5, description_column = $6, amount_column = $7, debit_column = $8, credit_column = $9, amount_sign = $10, decimal_separator = $11, note_column = $12, category_column = $13, income_category_id = $14, expense_category_id = $15, updated_at = $16 WHERE id = $17 AND user_id = $18"
+	_, err := db.Exec(query, profile.Name, profile.Delimiter, profile.SkipRows, profile.DateColumn, profile.DateFormat, profile.DescriptionColumn, profile.AmountColumn, profile.DebitColumn, profile.CreditColumn, profile.AmountSign, profile.DecimalSeparator, profile.NoteColumn, profile.CategoryColumn, profile.IncomeCategoryID, profile.ExpenseCategoryID, profile.UpdatedAt, profile.ID, profile.UserID)
+	return err
+}
+
+func DeleteImportProfile(id uuid.UUID, userID uuid.UUID, db interfaces.SqlExecutor) error {
+	query := "DELETE FROM import_profiles WHERE id = $1 AND user_id = $2"
+	_, err := db.Exec(query, id, userID)
+	return err
+}
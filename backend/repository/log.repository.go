@@ -2,7 +2,7 @@ package repository
 
 import (
 	"fmt"
-	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
@@ -15,24 +15,32 @@ func CreateLog(log *models.Log, db interfaces.SqlExecutor) error {
 	return err
 }
 
-func GetLogsByUserID(userID uuid.UUID, startDate string, endDate string, page int, limit int, db interfaces.SqlExecutor) ([]models.Log, error) {
-	var query strings.Builder
-	query.WriteString("SELECT id, user_id, message, created_at FROM logs WHERE user_id = $1")
-
-	args := []interface{}{userID}
-	argCount := 2
+// GetLogsByUserID returns a page of the user's logs created from one instant up to, but not
+// including, another, newest first.
+func GetLogsByUserID(userID uuid.UUID, from time.Time, to time.Time, page int, limit int, db interfaces.SqlExecutor) ([]models.Log, error) {
+	query := fmt.Sprintf("SELECT %s FROM logs WHERE user_id = $1 AND created_at >= $2 AND created_at < $3 ORDER BY created_at DESC LIMIT %d OFFSET %d", models.LogColumns, limit, (page-1)*limit)
+	rows, err := db.Query(query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	if startDate != "" && endDate != "" {
-		query.WriteString(" AND created_at BETWEEN $2 AND $3 ORDER BY created_at DESC")
-		args = append(args, "%"+startDate+"%")
-		args = append(args, "%"+endDate+"%")
-		argCount++
-		argCount++
+	var logs []models.Log
+	for rows.Next() {
+		var log models.Log
+		if err := rows.Scan(&log.ID, &log.UserID, &log.Message, &log.CreatedAt); err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
 	}
 
-	query.WriteString(fmt.Sprintf(" LIMIT %d OFFSET %d", limit, (page-1)*limit))
+	return logs, nil
+}
 
-	rows, err := db.Query(query.String(), args...)
+// GetAllLogsByUserID returns every log entry of the user, oldest first.
+func GetAllLogsByUserID(userID uuid.UUID, db interfaces.SqlExecutor) ([]models.Log, error) {
+	query := "SELECT " + models.LogColumns + " FROM logs WHERE user_id = $1 ORDER BY created_at"
+	rows, err := db.Query(query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -46,6 +54,5 @@ func GetLogsByUserID(userID uuid.UUID, startDate string, endDate string, page in
 		}
 		logs = append(logs, log)
 	}
-
 	return logs, nil
 }
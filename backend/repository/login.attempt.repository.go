@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+)
+
+func GetLoginAttempt(scope models.LoginAttemptScope, key string, db interfaces.SqlExecutor) (*models.LoginAttempt, error) {
+	query := "SELECT " + models.LoginAttemptColumns + " FROM login_attempts WHERE scope = $1 AND key = $2"
+	row := db.QueryRow(query, scope, key)
+
+	var attempt models.LoginAttempt
+	if err := row.Scan(&attempt.Scope, &attempt.Key, &attempt.Failures, &attempt.LastFailureAt, &attempt.BlockedUntil); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// RecordLoginFailure counts a failed login and returns the number of failures in the current
+// window. Counting starts over when the previous failure happened before windowStart.
+func RecordLoginFailure(scope models.LoginAttemptScope, key string, failedAt time.Time, windowStart time.Time, db interfaces.SqlExecutor) (int, error) {
+	query := "INSERT INTO login_attempts (" + models.LoginAttemptColumns + ") VALUES ($1, $2, 1, $3, NULL) ON CONFLICT (scope, key) DO UPDATE SET failures = CASE WHEN login_attempts.last_failure_at < $4 THEN 1 ELSE login_attempts.failures + 1 END, last_failure_at = EXCLUDED.last_failure_at RETURNING failures"
+
+	var failures int
+	err := db.QueryRow(query, scope, key, failedAt, windowStart).Scan(&failures)
+	return failures, err
+}
+
+func SetLoginAttemptBlockedUntil(scope models.LoginAttemptScope, key string, blockedUntil time.Time, db interfaces.SqlExecutor) error {
+	_, err := db.Exec("UPDATE login_attempts SET blocked_until = $1 WHERE scope = $2 AND key = $3", blockedUntil, scope, key)
+	return err
+}
+
+// ClearLoginAttempts forgets the failed logins for a key, lifting any backoff or lockout.
+func ClearLoginAttempts(scope models.LoginAttemptScope, key string, db interfaces.SqlExecutor) error {
+	_, err := db.Exec("DELETE FROM login_attempts WHERE scope = $1 AND key = $2", scope, key)
+	return err
+}
+
+// DeleteStaleLoginAttempts removes counters whose last failure was before the given time and that
+// are no longer blocked.
+func DeleteStaleLoginAttempts(before time.Time, db interfaces.SqlExecutor) (int64, error) {
+	result, err := db.Exec("DELETE FROM login_attempts WHERE last_failure_at < $1 AND (blocked_until IS NULL OR blocked_until < $1)", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
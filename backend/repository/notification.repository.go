@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+)
+
+func CreateNotification(notification *models.Notification, db interfaces.SqlExecutor) error {
+	query := fmt.Sprintf("INSERT INTO notifications (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)", models.NotificationColumns)
+	_, err := db.Exec(query, notification.ID, notification.UserID, notification.Type, notification.Title, notification.Message, notification.ReferenceID, notification.IsRead, notification.CreatedAt, notification.ReadAt)
+	return err
+}
+
+func GetNotificationsByUserID(userID uuid.UUID, unreadOnly bool, page int, limit int, db interfaces.SqlExecutor) ([]models.Notification, error) {
+	var query strings.Builder
+	query.WriteString("SELECT " + models.NotificationColumns + " FROM notifications WHERE user_id = $1")
+
+	if unreadOnly {
+		query.WriteString(" AND is_read = FALSE")
+	}
+
+	query.WriteString(fmt.Sprintf(" ORDER BY created_at DESC LIMIT %d OFFSET %d", limit, (page-1)*limit))
+
+	rows, err := db.Query(query.String(), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var notification models.Notification
+		if err := rows.Scan(&notification.ID, &notification.UserID, &notification.Type, &notification.Title, &notification.Message, &notification.ReferenceID, &notification.IsRead, &notification.CreatedAt, &notification.ReadAt); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, nil
+}
+
+func CountUnreadNotifications(userID uuid.UUID, db interfaces.SqlExecutor) (int, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND is_read = FALSE", userID).Scan(&count)
+	return count, err
+}
+
+// MarkNotificationRead marks one notification as read and reports whether it belonged to the user.
+func MarkNotificationRead(id uuid.UUID, userID uuid.UUID, readAt time.Time, db interfaces.SqlExecutor) (bool, error) {
+	query := "UPDATE notifications SET is_read = TRUE, read_at = COALESCE(read_at, $1) WHERE id = $2 AND user_id = $3"
+	result, err := db.Exec(query, readAt, id, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func MarkAllNotificationsRead(userID uuid.UUID, readAt time.Time, db interfaces.SqlExecutor) (int64, error) {
+	query := "UPDATE notifications SET is_read = TRUE, read_at = $1 WHERE user_id = $2 AND is_read = FALSE"
+	result, err := db.Exec(query, readAt, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+)
+
+func CreateOAuthState(state *models.OAuthState, db interfaces.SqlExecutor) error {
+	query := fmt.Sprintf("INSERT INTO oauth_states (%s) VALUES ($1, $2, $3, $4, $5, $6)", models.OAuthStateColumns)
+	_, err := db.Exec(query, state.Nonce, state.CodeVerifier, state.Purpose, state.UserID, state.ExpiresAt, state.CreatedAt)
+	return err
+}
+
+// ConsumeOAuthState deletes and returns an unexpired state. It returns nil when the state is
+// unknown, expired or was already consumed, so a callback can never be replayed.
+func ConsumeOAuthState(nonce string, now time.Time, db interfaces.SqlExecutor) (*models.OAuthState, error) {
+	query := "DELETE FROM oauth_states WHERE nonce = $1 AND expires_at > $2 RETURNING " + models.OAuthStateColumns
+	row := db.QueryRow(query, nonce, now)
+
+	var state models.OAuthState
+	if err := row.Scan(&state.Nonce, &state.CodeVerifier, &state.Purpose, &state.UserID, &state.ExpiresAt, &state.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &state, nil
+}
+
+// DeleteExpiredOAuthStates removes states whose flow was abandoned and returns how many were deleted.
+func DeleteExpiredOAuthStates(before time.Time, db interfaces.SqlExecutor) (int64, error) {
+	result, err := db.Exec("DELETE FROM oauth_states WHERE expires_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+)
+
+// UpsertRecurringTransactionException stores the exception for an occurrence, replacing any earlier one.
+func UpsertRecurringTransactionException(exception *models.RecurringTransactionException, db interfaces.SqlExecutor) error {
+	query := fmt.Sprintf("INSERT INTO recurring_transaction_exceptions (%s) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (recurring_transaction_id, occurrence_date) DO UPDATE SET skip = EXCLUDED.skip, amount = EXCLUDED.amount, updated_at = EXCLUDED.updated_at", models.RecurringTransactionExceptionColumns)
+	_, err := db.Exec(query, exception.RecurringTransactionID, exception.OccurrenceDate.Format("2006-01-02"), exception.Skip, exception.Amount, exception.CreatedAt, exception.UpdatedAt)
+	return err
+}
+
+// GetRecurringTransactionExceptions returns a rule's exceptions from one date to another, both
+// inclusive, keyed by occurrence date in YYYY-MM-DD form.
+func GetRecurringTransactionExceptions(recurringTransactionID uuid.UUID, from time.Time, to time.Time, db interfaces.SqlExecutor) (map[string]models.RecurringTransactionException, error) {
+	query := "SELECT " + models.RecurringTransactionExceptionColumns + " FROM recurring_transaction_exceptions WHERE recurring_transaction_id = $1 AND occurrence_date BETWEEN $2 AND $3"
+	rows, err := db.Query(query, recurringTransactionID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exceptions := make(map[string]models.RecurringTransactionException)
+	for rows.Next() {
+		var exception models.RecurringTransactionException
+		if err := rows.Scan(&exception.RecurringTransactionID, &exception.OccurrenceDate, &exception.Skip, &exception.Amount, &exception.CreatedAt, &exception.UpdatedAt); err != nil {
+			return nil, err
+		}
+		exceptions[exception.OccurrenceDate.Format("2006-01-02")] = exception
+	}
+	return exceptions, nil
+}
+
+// DeleteRecurringTransactionException removes the exception for an occurrence. It returns false
+// when the occurrence had none.
+func DeleteRecurringTransactionException(recurringTransactionID uuid.UUID, occurrenceDate time.Time, db interfaces.SqlExecutor) (bool, error) {
+	query := "DELETE FROM recurring_transaction_exceptions WHERE recurring_transaction_id = $1 AND occurrence_date = $2"
+	result, err := db.Exec(query, recurringTransactionID, occurrenceDate.Format("2006-01-02"))
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
@@ -10,8 +11,8 @@ import (
 )
 
 func CreateRecurringTransaction(recurringTransaction *models.RecurringTransaction, db interfaces.SqlExecutor) error {
-	query := fmt.Sprintf("INSERT INTO recurring_transactions (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)", models.RecurringTransactionColumns)
-	_, err := db.Exec(query, recurringTransaction.ID, recurringTransaction.UserID, recurringTransaction.AccountID, recurringTransaction.CategoryID, recurringTransaction.BudgetID, recurringTransaction.Description, recurringTransaction.Amount, recurringTransaction.Type, recurringTransaction.Note, recurringTransaction.RecurringFrequency, recurringTransaction.RecurringDate, recurringTransaction.CreatedAt, recurringTransaction.UpdatedAt)
+	query := fmt.Sprintf("INSERT INTO recurring_transactions (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)", models.RecurringTransactionColumns)
+	_, err := db.Exec(query, recurringTransaction.ID, recurringTransaction.UserID, recurringTransaction.AccountID, recurringTransaction.CategoryID, recurringTransaction.BudgetID, recurringTransaction.Description, recurringTransaction.Amount, recurringTransaction.Type, recurringTransaction.Note, recurringTransaction.RecurringFrequency, recurringTransaction.RecurringDate, recurringTransaction.CreatedAt, recurringTransaction.UpdatedAt, recurringTransaction.Interval, recurringTransaction.Weekday, recurringTransaction.LastDayOfMonth, recurringTransaction.StartDate, recurringTransaction.EndDate, recurringTransaction.MaxOccurrences, recurringTransaction.LastRunAt, recurringTransaction.IsPaused)
 	return err
 }
 
@@ -26,7 +27,7 @@ func GetRecurringTransactionsByUserID(userID uuid.UUID, db interfaces.SqlExecuto
 	var recurringTransactions []models.RecurringTransaction
 	for rows.Next() {
 		var recurringTransaction models.RecurringTransaction
-		if err := rows.Scan(&recurringTransaction.ID, &recurringTransaction.UserID, &recurringTransaction.AccountID, &recurringTransaction.CategoryID, &recurringTransaction.BudgetID, &recurringTransaction.Description, &recurringTransaction.Amount, &recurringTransaction.Type, &recurringTransaction.Note, &recurringTransaction.RecurringFrequency, &recurringTransaction.RecurringDate, &recurringTransaction.CreatedAt, &recurringTransaction.UpdatedAt); err != nil {
+		if err := rows.Scan(&recurringTransaction.ID, &recurringTransaction.UserID, &recurringTransaction.AccountID, &recurringTransaction.CategoryID, &recurringTransaction.BudgetID, &recurringTransaction.Description, &recurringTransaction.Amount, &recurringTransaction.Type, &recurringTransaction.Note, &recurringTransaction.RecurringFrequency, &recurringTransaction.RecurringDate, &recurringTransaction.CreatedAt, &recurringTransaction.UpdatedAt, &recurringTransaction.Interval, &recurringTransaction.Weekday, &recurringTransaction.LastDayOfMonth, &recurringTransaction.StartDate, &recurringTransaction.EndDate, &recurringTransaction.MaxOccurrences, &recurringTransaction.LastRunAt, &recurringTransaction.IsPaused); err != nil {
 			return nil, err
 		}
 		recurringTransactions = append(recurringTransactions, recurringTransaction)
@@ -45,7 +46,7 @@ func GetRecurringTransactions(db interfaces.SqlExecutor) ([]models.RecurringTran
 	var recurringTransactions []models.RecurringTransaction
 	for rows.Next() {
 		var recurringTransaction models.RecurringTransaction
-		if err := rows.Scan(&recurringTransaction.ID, &recurringTransaction.UserID, &recurringTransaction.AccountID, &recurringTransaction.CategoryID, &recurringTransaction.BudgetID, &recurringTransaction.Description, &recurringTransaction.Amount, &recurringTransaction.Type, &recurringTransaction.Note, &recurringTransaction.RecurringFrequency, &recurringTransaction.RecurringDate, &recurringTransaction.CreatedAt, &recurringTransaction.UpdatedAt); err != nil {
+		if err := rows.Scan(&recurringTransaction.ID, &recurringTransaction.UserID, &recurringTransaction.AccountID, &recurringTransaction.CategoryID, &recurringTransaction.BudgetID, &recurringTransaction.Description, &recurringTransaction.Amount, &recurringTransaction.Type, &recurringTransaction.Note, &recurringTransaction.RecurringFrequency, &recurringTransaction.RecurringDate, &recurringTransaction.CreatedAt, &recurringTransaction.UpdatedAt, &recurringTransaction.Interval, &recurringTransaction.Weekday, &recurringTransaction.LastDayOfMonth, &recurringTransaction.StartDate, &recurringTransaction.EndDate, &recurringTransaction.MaxOccurrences, &recurringTransaction.LastRunAt, &recurringTransaction.IsPaused); err != nil {
 			return nil, err
 		}
 		recurringTransactions = append(recurringTransactions, recurringTransaction)
@@ -53,12 +54,12 @@ func GetRecurringTransactions(db interfaces.SqlExecutor) ([]models.RecurringTran
 	return recurringTransactions, nil
 }
 
-func GetRecurringTransactionByID(id uuid.UUID, db interfaces.SqlExecutor) (*models.RecurringTransaction, error) {
-	query := "SELECT " + models.RecurringTransactionColumns + " FROM recurring_transactions WHERE id = $1"
-	row := db.QueryRow(query, id)
+func GetRecurringTransactionByID(id uuid.UUID, userID uuid.UUID, db interfaces.SqlExecutor) (*models.RecurringTransaction, error) {
+	query := "SELECT " + models.RecurringTransactionColumns + " FROM recurring_transactions WHERE id = $1 AND user_id = $2"
+	row := db.QueryRow(query, id, userID)
 
 	var recurringTransaction models.RecurringTransaction
-	if err := row.Scan(&recurringTransaction.ID, &recurringTransaction.UserID, &recurringTransaction.AccountID, &recurringTransaction.CategoryID, &recurringTransaction.BudgetID, &recurringTransaction.Description, &recurringTransaction.Amount, &recurringTransaction.Type, &recurringTransaction.Note, &recurringTransaction.RecurringFrequency, &recurringTransaction.RecurringDate, &recurringTransaction.CreatedAt, &recurringTransaction.UpdatedAt); err != nil {
+	if err := row.Scan(&recurringTransaction.ID, &recurringTransaction.UserID, &recurringTransaction.AccountID, &recurringTransaction.CategoryID, &recurringTransaction.BudgetID, &recurringTransaction.Description, &recurringTransaction.Amount, &recurringTransaction.Type, &recurringTransaction.Note, &recurringTransaction.RecurringFrequency, &recurringTransaction.RecurringDate, &recurringTransaction.CreatedAt, &recurringTransaction.UpdatedAt, &recurringTransaction.Interval, &recurringTransaction.Weekday, &recurringTransaction.LastDayOfMonth, &recurringTransaction.StartDate, &recurringTransaction.EndDate, &recurringTransaction.MaxOccurrences, &recurringTransaction.LastRunAt, &recurringTransaction.IsPaused); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Or a custom not found error
 		}
@@ -68,13 +69,33 @@ func GetRecurringTransactionByID(id uuid.UUID, db interfaces.SqlExecutor) (*mode
 }
 
 func UpdateRecurringTransaction(recurringTransaction *models.RecurringTransaction, db interfaces.SqlExecutor) error {
-	query := "UPDATE recurring_transactions SET account_id = $1, category_id = $2, budget_id = $3, description = $4, amount = $5, type = $6, note = $7, recurring_frequency = $8, recurring_date = $9, updated_at = $10 WHERE id = $11"
-	_, err := db.Exec(query, recurringTransaction.AccountID, recurringTransaction.CategoryID, recurringTransaction.BudgetID, recurringTransaction.Description, recurringTransaction.Amount, recurringTransaction.Type, recurringTransaction.Note, recurringTransaction.RecurringFrequency, recurringTransaction.RecurringDate, recurringTransaction.UpdatedAt, recurringTransaction.ID)
+	query := "UPDATE recurring_transactions SET account_id = $1, category_id = $2, budget_id = $3, description = $4, amount = $5, type = $6, note = $7, recurring_frequency = $8, recurring_date = $9, updated_at = $10, recurrence_interval = $11, weekday = $12, last_day_of_month = $13, start_date = $14, end_date = $15, max_occurrences = $16 WHERE id = $17 AND user_id = $18"
+	_, err := db.Exec(query, recurringTransaction.AccountID, recurringTransaction.CategoryID, recurringTransaction.BudgetID, recurringTransaction.Description, recurringTransaction.Amount, recurringTransaction.Type, recurringTransaction.Note, recurringTransaction.RecurringFrequency, recurringTransaction.RecurringDate, recurringTransaction.UpdatedAt, recurringTransaction.Interval, recurringTransaction.Weekday, recurringTransaction.LastDayOfMonth, recurringTransaction.StartDate, recurringTransaction.EndDate, recurringTransaction.MaxOccurrences, recurringTransaction.ID, recurringTransaction.UserID)
 	return err
 }
 
-func DeleteRecurringTransaction(id uuid.UUID, db interfaces.SqlExecutor) error {
-	query := "DELETE FROM recurring_transactions WHERE id = $1"
-	_, err := db.Exec(query, id)
+// UpdateRecurringTransactionLastRun records the last day the processor has handled for a rule.
+func UpdateRecurringTransactionLastRun(id uuid.UUID, lastRunAt time.Time, db interfaces.SqlExecutor) error {
+	query := "UPDATE recurring_transactions SET last_run_at = $1 WHERE id = $2"
+	_, err := db.Exec(query, lastRunAt.Format("2006-01-02"), id)
+	return err
+}
+
+// SetRecurringTransactionPaused pauses or resumes a rule.
+func SetRecurringTransactionPaused(id uuid.UUID, userID uuid.UUID, paused bool, updatedAt time.Time, db interfaces.SqlExecutor) error {
+	query := "UPDATE recurring_transactions SET is_paused = $1, updated_at = $2 WHERE id = $3 AND user_id = $4"
+	_, err := db.Exec(query, paused, updatedAt, id, userID)
+	return err
+}
+
+func DeleteRecurringTransaction(id uuid.UUID, userID uuid.UUID, db interfaces.SqlExecutor) error {
+	query := "DELETE FROM recurring_transactions WHERE id = $1 AND user_id = $2"
+	_, err := db.Exec(query, id, userID)
+	return err
+}
+
+// DeleteRecurringTransactionsByUserID removes all of the user's recurring transactions.
+func DeleteRecurringTransactionsByUserID(userID uuid.UUID, db interfaces.SqlExecutor) error {
+	_, err := db.Exec("DELETE FROM recurring_transactions WHERE user_id = $1", userID)
 	return err
 }
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+)
+
+// CreateRecurringTransactionRun adds an occurrence to the ledger. It returns false when the
+// occurrence was already recorded, which is how each occurrence is posted only once.
+func CreateRecurringTransactionRun(run *models.RecurringTransactionRun, db interfaces.SqlExecutor) (bool, error) {
+	query := fmt.Sprintf("INSERT INTO recurring_transaction_runs (%s) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (recurring_transaction_id, occurrence_date) DO NOTHING", models.RecurringTransactionRunColumns)
+	result, err := db.Exec(query, run.RecurringTransactionID, run.OccurrenceDate.Format("2006-01-02"), run.OccurrenceIndex, run.TransactionID, run.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+)
+
+func CreateRefreshToken(token *models.RefreshToken, db interfaces.SqlExecutor) error {
+	query := fmt.Sprintf("INSERT INTO refresh_tokens (%s) VALUES ($1, $2, $3, $4, $5, $6)", models.RefreshTokenColumns)
+	_, err := db.Exec(query, token.ID, token.SessionID, token.TokenHash, token.ExpiresAt, token.UsedAt, token.CreatedAt)
+	return err
+}
+
+func GetRefreshTokenByHash(tokenHash string, db interfaces.SqlExecutor) (*models.RefreshToken, error) {
+	query := "SELECT " + models.RefreshTokenColumns + " FROM refresh_tokens WHERE token_hash = $1"
+	row := db.QueryRow(query, tokenHash)
+
+	var token models.RefreshToken
+	if err := row.Scan(&token.ID, &token.SessionID, &token.TokenHash, &token.ExpiresAt, &token.UsedAt, &token.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkRefreshTokenUsed consumes a refresh token. It returns false when the token was already
+// used, so two concurrent refreshes with the same token cannot both succeed.
+func MarkRefreshTokenUsed(id uuid.UUID, usedAt time.Time, db interfaces.SqlExecutor) (bool, error) {
+	result, err := db.Exec("UPDATE refresh_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL", usedAt, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
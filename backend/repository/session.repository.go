@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+)
+
+func CreateSession(session *models.Session, db interfaces.SqlExecutor) error {
+	query := fmt.Sprintf("INSERT INTO sessions (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)", models.SessionColumns)
+	_, err := db.Exec(query, session.ID, session.UserID, session.UserAgent, session.IPAddress, session.CreatedAt, session.LastUsedAt, session.ExpiresAt, session.RevokedAt)
+	return err
+}
+
+func GetSessionByID(id uuid.UUID, db interfaces.SqlExecutor) (*models.Session, error) {
+	query := "SELECT " + models.SessionColumns + " FROM sessions WHERE id = $1"
+	row := db.QueryRow(query, id)
+
+	var session models.Session
+	if err := row.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IPAddress, &session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt, &session.RevokedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetActiveSessionsByUserID lists the sessions that are neither revoked nor expired, most recently used first.
+func GetActiveSessionsByUserID(userID uuid.UUID, now time.Time, db interfaces.SqlExecutor) ([]models.Session, error) {
+	query := "SELECT " + models.SessionColumns + " FROM sessions WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2 ORDER BY last_used_at DESC"
+	rows, err := db.Query(query, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var session models.Session
+		if err := rows.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IPAddress, &session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt, &session.RevokedAt); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// TouchSession records that a session was refreshed and extends its expiry.
+func TouchSession(id uuid.UUID, lastUsedAt time.Time, expiresAt time.Time, db interfaces.SqlExecutor) error {
+	query := "UPDATE sessions SET last_used_at = $1, expires_at = $2 WHERE id = $3"
+	_, err := db.Exec(query, lastUsedAt, expiresAt, id)
+	return err
+}
+
+// RevokeSession revokes one of the user's sessions. It returns false when no active session matched.
+func RevokeSession(id uuid.UUID, userID uuid.UUID, revokedAt time.Time, db interfaces.SqlExecutor) (bool, error) {
+	query := "UPDATE sessions SET revoked_at = $1 WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL"
+	result, err := db.Exec(query, revokedAt, id, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// RevokeSessionsByUserID revokes every active session of the user except the one given, if any.
+func RevokeSessionsByUserID(userID uuid.UUID, exceptID uuid.NullUUID, revokedAt time.Time, db interfaces.SqlExecutor) error {
+	query := "UPDATE sessions SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL AND ($3::UUID IS NULL OR id <> $3)"
+	_, err := db.Exec(query, revokedAt, userID, exceptID)
+	return err
+}
+
+// DeleteStaleSessions removes sessions that expired or were revoked before the given time.
+func DeleteStaleSessions(before time.Time, db interfaces.SqlExecutor) (int64, error) {
+	result, err := db.Exec("DELETE FROM sessions WHERE expires_at < $1 OR revoked_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
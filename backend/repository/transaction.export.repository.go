@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+)
+
+const transactionExportSelect = "SELECT t.id, t.transaction_date, t.description, t.amount, t.currency, t.type, COALESCE(c.name, ''), a.id AS leg_account_id, a.name, COALESCE(d.name, ''), t.destination_amount, COALESCE(t.note, ''), a.type, a.balance, FALSE, t.created_at FROM transactions t JOIN accounts a ON a.id = t.account_id LEFT JOIN categories c ON c.id = t.category_id LEFT JOIN accounts d ON d.id = t.destination_account_id"
+
+// transactionExportIncomingSelect returns the destination legs of transfers, booked on the
+// destination account in its own currency.
+const transactionExportIncomingSelect = "SELECT t.id, t.transaction_date, t.description, t.destination_amount, d.currency, t.type, '', d.id, d.name, '', NULL::NUMERIC, COALESCE(t.note, ''), d.type, d.balance, TRUE, t.created_at FROM transactions t JOIN accounts d ON d.id = t.destination_account_id"
+
+// StreamTransactionsForExport reads the user's transactions matching the filter with one query
+// and passes them to fn one at a time, oldest first. Rows are grouped by account when exporting
+// legs. It stops at the first error returned by fn.
+func StreamTransactionsForExport(userID uuid.UUID, filter *models.TransactionExportFilter, fn func(row *models.TransactionExportRow) error, db interfaces.SqlExecutor) error {
+	args := []interface{}{userID}
+	conditions := []string{"t.user_id = $1"}
+
+	if filter.StartDate != "" {
+		args = append(args, filter.StartDate)
+		conditions = append(conditions, fmt.Sprintf("t.transaction_date >= $%d", len(args)))
+	}
+
+	if filter.EndDate != "" {
+		args = append(args, filter.EndDate)
+		conditions = append(conditions, fmt.Sprintf("t.transaction_date <= $%d", len(args)))
+	}
+
+	if filter.CategoryID.Valid {
+		args = append(args, filter.CategoryID.UUID)
+		conditions = append(conditions, fmt.Sprintf("t.category_id = $%d", len(args)))
+	}
+
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		conditions = append(conditions, fmt.Sprintf("t.type = $%d", len(args)))
+	}
+
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var query string
+	if filter.Legs {
+		outgoing, incoming := where, where+" AND t.destination_account_id IS NOT NULL"
+		if filter.AccountID.Valid {
+			args = append(args, filter.AccountID.UUID)
+			outgoing += fmt.Sprintf(" AND t.account_id = $%d", len(args))
+			incoming += fmt.Sprintf(" AND t.destination_account_id = $%d", len(args))
+		}
+		query = transactionExportSelect + outgoing + " UNION ALL " + transactionExportIncomingSelect + incoming + " ORDER BY leg_account_id, transaction_date, created_at"
+	} else {
+		if filter.AccountID.Valid {
+			args = append(args, filter.AccountID.UUID)
+			where += fmt.Sprintf(" AND (t.account_id = $%d OR t.destination_account_id = $%d)", len(args), len(args))
+		}
+		query = transactionExportSelect + where + " ORDER BY t.transaction_date, t.created_at"
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row models.TransactionExportRow
+		var createdAt interface{}
+		if err := rows.Scan(&row.ID, &row.TransactionDate, &row.Description, &row.Amount, &row.Currency, &row.Type, &row.Category, &row.AccountID, &row.Account, &row.DestinationAccount, &row.DestinationAmount, &row.Note, &row.AccountType, &row.AccountBalance, &row.Incoming, &createdAt); err != nil {
+			return err
+		}
+
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -4,20 +4,22 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
 )
 
 func CreateTransaction(transaction *models.Transaction, db interfaces.SqlExecutor) error {
-	query := fmt.Sprintf("INSERT INTO transactions (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)", models.TransactionColumns)
-	_, err := db.Exec(query, transaction.ID, transaction.UserID, transaction.AccountID, transaction.CategoryID, transaction.BudgetID, transaction.Description, transaction.Amount, transaction.Type, transaction.TransactionDate, transaction.Note, transaction.CreatedAt, transaction.UpdatedAt)
+	query := fmt.Sprintf("INSERT INTO transactions (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)", models.TransactionColumns)
+	_, err := db.Exec(query, transaction.ID, transaction.UserID, transaction.AccountID, transaction.CategoryID, transaction.BudgetID, transaction.Description, transaction.Amount, transaction.Type, transaction.TransactionDate, transaction.Note, transaction.CreatedAt, transaction.UpdatedAt, transaction.Currency, transaction.DestinationAccountID, transaction.DestinationAmount, transaction.ExternalID)
 	return err
 }
 
 func GetTransactionsByUserID(userID uuid.UUID, db interfaces.SqlExecutor) ([]models.Transaction, error) {
-	query := "SELECT * FROM transactions WHERE user_id = $1"
+	query := "SELECT " + models.TransactionColumns + " FROM transactions WHERE user_id = $1"
 	rows, err := db.Query(query, userID)
 	if err != nil {
 		return nil, err
@@ -27,7 +29,7 @@ func GetTransactionsByUserID(userID uuid.UUID, db interfaces.SqlExecutor) ([]mod
 	var transactions []models.Transaction
 	for rows.Next() {
 		var transaction models.Transaction
-		if err := rows.Scan(&transaction.ID, &transaction.UserID, &transaction.AccountID, &transaction.CategoryID, &transaction.BudgetID, &transaction.Description, &transaction.Amount, &transaction.Type, &transaction.TransactionDate, &transaction.Note, &transaction.CreatedAt, &transaction.UpdatedAt); err != nil {
+		if err := rows.Scan(&transaction.ID, &transaction.UserID, &transaction.AccountID, &transaction.CategoryID, &transaction.BudgetID, &transaction.Description, &transaction.Amount, &transaction.Type, &transaction.TransactionDate, &transaction.Note, &transaction.CreatedAt, &transaction.UpdatedAt, &transaction.Currency, &transaction.DestinationAccountID, &transaction.DestinationAmount, &transaction.ExternalID); err != nil {
 			return nil, err
 		}
 		transactions = append(transactions, transaction)
@@ -35,12 +37,12 @@ func GetTransactionsByUserID(userID uuid.UUID, db interfaces.SqlExecutor) ([]mod
 	return transactions, nil
 }
 
-func GetTransactionByID(id uuid.UUID, db interfaces.SqlExecutor) (*models.Transaction, error) {
-	query := "SELECT * FROM transactions WHERE id = $1"
-	row := db.QueryRow(query, id)
+func GetTransactionByID(id uuid.UUID, userID uuid.UUID, db interfaces.SqlExecutor) (*models.Transaction, error) {
+	query := "SELECT " + models.TransactionColumns + " FROM transactions WHERE id = $1 AND user_id = $2"
+	row := db.QueryRow(query, id, userID)
 
 	var transaction models.Transaction
-	if err := row.Scan(&transaction.ID, &transaction.UserID, &transaction.AccountID, &transaction.CategoryID, &transaction.BudgetID, &transaction.Description, &transaction.Amount, &transaction.Type, &transaction.TransactionDate, &transaction.Note, &transaction.CreatedAt, &transaction.UpdatedAt); err != nil {
+	if err := row.Scan(&transaction.ID, &transaction.UserID, &transaction.AccountID, &transaction.CategoryID, &transaction.BudgetID, &transaction.Description, &transaction.Amount, &transaction.Type, &transaction.TransactionDate, &transaction.Note, &transaction.CreatedAt, &transaction.UpdatedAt, &transaction.Currency, &transaction.DestinationAccountID, &transaction.DestinationAmount, &transaction.ExternalID); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Or a custom not found error
 		}
@@ -50,20 +52,26 @@ func GetTransactionByID(id uuid.UUID, db interfaces.SqlExecutor) (*models.Transa
 }
 
 func UpdateTransaction(transaction *models.Transaction, db interfaces.SqlExecutor) error {
-	query := "UPDATE transactions SET account_id = $1, category_id = $2, budget_id = $3, description = $4, amount = $5, type = $6, transaction_date = $7, note = $8, updated_at = $9 WHERE id = $10"
-	_, err := db.Exec(query, transaction.AccountID, transaction.CategoryID, transaction.BudgetID, transaction.Description, transaction.Amount, transaction.Type, transaction.TransactionDate, transaction.Note, transaction.UpdatedAt, transaction.ID)
+	query := "UPDATE transactions SET account_id = $1, category_id = $2, budget_id = $3, description = $4, amount = $5, type = $6, transaction_date = $7, note = $8, updated_at = $9, currency = $10, destination_account_id = $11, destination_amount = $12 WHERE id = $13 AND user_id = $14"
+	_, err := db.Exec(query, transaction.AccountID, transaction.CategoryID, transaction.BudgetID, transaction.Description, transaction.Amount, transaction.Type, transaction.TransactionDate, transaction.Note, transaction.UpdatedAt, transaction.Currency, transaction.DestinationAccountID, transaction.DestinationAmount, transaction.ID, transaction.UserID)
 	return err
 }
 
-func DeleteTransaction(id uuid.UUID, db interfaces.SqlExecutor) error {
-	query := "DELETE FROM transactions WHERE id = $1"
-	_, err := db.Exec(query, id)
+func DeleteTransaction(id uuid.UUID, userID uuid.UUID, db interfaces.SqlExecutor) error {
+	query := "DELETE FROM transactions WHERE id = $1 AND user_id = $2"
+	_, err := db.Exec(query, id, userID)
+	return err
+}
+
+// DeleteTransactionsByUserID removes all of the user's transactions.
+func DeleteTransactionsByUserID(userID uuid.UUID, db interfaces.SqlExecutor) error {
+	_, err := db.Exec("DELETE FROM transactions WHERE user_id = $1", userID)
 	return err
 }
 
 func GetTransactionsByUserIDWithFilters(userID uuid.UUID, page int, limit int, description string, categoryID string, accountID string, budgetID string, startDate string, endDate string, db interfaces.SqlExecutor) ([]models.Transaction, error) {
 	var query strings.Builder
-	query.WriteString("SELECT id, user_id, account_id, category_id, budget_id, description, amount, type, transaction_date, note, created_at, updated_at FROM transactions WHERE user_id = $1")
+	query.WriteString("SELECT " + models.TransactionColumns + " FROM transactions WHERE user_id = $1")
 
 	args := []interface{}{userID}
 	argCount := 2
@@ -81,7 +89,7 @@ func GetTransactionsByUserIDWithFilters(userID uuid.UUID, page int, limit int, d
 	}
 
 	if accountID != "" {
-		query.WriteString(fmt.Sprintf(" AND account_id = $%d", argCount))
+		query.WriteString(fmt.Sprintf(" AND (account_id = $%[1]d OR destination_account_id = $%[1]d)", argCount))
 		args = append(args, accountID)
 		argCount++
 	}
@@ -115,7 +123,7 @@ func GetTransactionsByUserIDWithFilters(userID uuid.UUID, page int, limit int, d
 	var transactions []models.Transaction
 	for rows.Next() {
 		var transaction models.Transaction
-		if err := rows.Scan(&transaction.ID, &transaction.UserID, &transaction.AccountID, &transaction.CategoryID, &transaction.BudgetID, &transaction.Description, &transaction.Amount, &transaction.Type, &transaction.TransactionDate, &transaction.Note, &transaction.CreatedAt, &transaction.UpdatedAt); err != nil {
+		if err := rows.Scan(&transaction.ID, &transaction.UserID, &transaction.AccountID, &transaction.CategoryID, &transaction.BudgetID, &transaction.Description, &transaction.Amount, &transaction.Type, &transaction.TransactionDate, &transaction.Note, &transaction.CreatedAt, &transaction.UpdatedAt, &transaction.Currency, &transaction.DestinationAccountID, &transaction.DestinationAmount, &transaction.ExternalID); err != nil {
 			return nil, err
 		}
 		transactions = append(transactions, transaction)
@@ -123,24 +131,27 @@ func GetTransactionsByUserIDWithFilters(userID uuid.UUID, page int, limit int, d
 	return transactions, nil
 }
 
-func GetAggregateDataByUserID(userID uuid.UUID, startDate string, endDate string, db interfaces.SqlExecutor) (map[string]interface{}, error) {
-	var totalIncome float64
-	var totalExpenses float64
+// GetAggregateDataByUserID totals income and expenses converted into baseCurrency at each transaction's date.
+func GetAggregateDataByUserID(userID uuid.UUID, baseCurrency models.Currency, startDate string, endDate string, db interfaces.SqlExecutor) (map[string]interface{}, error) {
+	var totalIncome models.Money
+	var totalExpenses models.Money
+
+	amount := convertedAmountSQL("$2")
 
 	var query strings.Builder
-	query.WriteString("SELECT COALESCE(SUM(CASE WHEN type = 'income' THEN amount ELSE 0 END), 0) as total_income, COALESCE(SUM(CASE WHEN type = 'expense' THEN amount ELSE 0 END), 0) as total_expenses FROM transactions WHERE user_id = $1")
+	query.WriteString(fmt.Sprintf("SELECT COALESCE(SUM(CASE WHEN t.type = 'income' THEN %[1]s ELSE 0 END), 0) as total_income, COALESCE(SUM(CASE WHEN t.type = 'expense' THEN %[1]s ELSE 0 END), 0) as total_expenses FROM transactions t WHERE t.user_id = $1", amount))
 
-	args := []interface{}{userID}
-	argCount := 2
+	args := []interface{}{userID, baseCurrency}
+	argCount := 3
 
 	if startDate != "" {
-		query.WriteString(fmt.Sprintf(" AND transaction_date >= $%d", argCount))
+		query.WriteString(fmt.Sprintf(" AND t.transaction_date >= $%d", argCount))
 		args = append(args, startDate)
 		argCount++
 	}
 
 	if endDate != "" {
-		query.WriteString(fmt.Sprintf(" AND transaction_date <= $%d", argCount))
+		query.WriteString(fmt.Sprintf(" AND t.transaction_date <= $%d", argCount))
 		args = append(args, endDate)
 		argCount++
 	}
@@ -150,18 +161,29 @@ func GetAggregateDataByUserID(userID uuid.UUID, startDate string, endDate string
 		return nil, err
 	}
 
-	netIncome := totalIncome - totalExpenses
+	netIncome := totalIncome.Sub(totalExpenses)
 
 	return map[string]interface{}{
+		"currency":      baseCurrency,
 		"totalIncome":   totalIncome,
 		"totalExpenses": totalExpenses,
 		"netIncome":     netIncome,
 	}, nil
 }
 
-func GetSpendingByCategory(userID uuid.UUID, db interfaces.SqlExecutor) ([]map[string]interface{}, error) {
-	query := "SELECT c.name as category, sum(t.amount) as amount FROM transactions t JOIN categories c ON c.id = t.category_id WHERE t.user_id = $1 AND t.type = 'expense' GROUP BY c.name"
-	rows, err := db.Query(query, userID)
+// GetSpendingByCategory totals expenses per category converted into baseCurrency.
+func GetSpendingByCategory(userID uuid.UUID, baseCurrency models.Currency, db interfaces.SqlExecutor) ([]map[string]interface{}, error) {
+	return getAmountByCategory(userID, baseCurrency, models.TransactionTypeExpense, db)
+}
+
+// GetEarningByCategory totals income per category converted into baseCurrency.
+func GetEarningByCategory(userID uuid.UUID, baseCurrency models.Currency, db interfaces.SqlExecutor) ([]map[string]interface{}, error) {
+	return getAmountByCategory(userID, baseCurrency, models.TransactionTypeIncome, db)
+}
+
+func getAmountByCategory(userID uuid.UUID, baseCurrency models.Currency, transactionType models.TransactionType, db interfaces.SqlExecutor) ([]map[string]interface{}, error) {
+	query := fmt.Sprintf("SELECT c.name as category, COALESCE(SUM(%s), 0) as amount FROM transactions t JOIN categories c ON c.id = t.category_id WHERE t.user_id = $1 AND t.type = $3 GROUP BY c.name", convertedAmountSQL("$2"))
+	rows, err := db.Query(query, userID, baseCurrency, transactionType)
 	if err != nil {
 		return nil, err
 	}
@@ -170,7 +192,7 @@ func GetSpendingByCategory(userID uuid.UUID, db interfaces.SqlExecutor) ([]map[s
 	var result []map[string]interface{}
 	for rows.Next() {
 		var category string
-		var amount float64
+		var amount models.Money
 		if err := rows.Scan(&category, &amount); err != nil {
 			return nil, err
 		}
@@ -180,23 +202,101 @@ func GetSpendingByCategory(userID uuid.UUID, db interfaces.SqlExecutor) ([]map[s
 	return result, nil
 }
 
-func GetEarningByCategory(userID uuid.UUID, db interfaces.SqlExecutor) ([]map[string]interface{}, error) {
-	query := "SELECT c.name as category, sum(t.amount) as amount FROM transactions t JOIN categories c ON c.id = t.category_id WHERE t.user_id = $1 AND t.type = 'income' GROUP BY c.name"
-	rows, err := db.Query(query, userID)
+// GetDailyIncomeAndExpenses totals the user's income and expenses per day between the two
+// YYYY-MM-DD dates, inclusive, converted into baseCurrency. With a groupBy the totals are also
+// split by account or category.
+func GetDailyIncomeAndExpenses(userID uuid.UUID, baseCurrency models.Currency, startDate string, endDate string, groupBy models.TimeSeriesGroupBy, db interfaces.SqlExecutor) ([]models.DailyIncomeExpense, error) {
+	group, join, groupColumns := "NULL::UUID, ''", "", ""
+	switch groupBy {
+	case models.TimeSeriesByAccount:
+		group, join = "t.account_id, a.name", " JOIN accounts a ON a.id = t.account_id"
+		groupColumns = ", " + group
+	case models.TimeSeriesByCategory:
+		group, join = "t.category_id, COALESCE(c.name, '')", " LEFT JOIN categories c ON c.id = t.category_id"
+		groupColumns = ", t.category_id, c.name"
+	}
+
+	amount := convertedAmountSQL("$2")
+	query := fmt.Sprintf("SELECT t.transaction_date, %[1]s, COALESCE(SUM(CASE WHEN t.type = 'income' THEN %[2]s ELSE 0 END), 0), COALESCE(SUM(CASE WHEN t.type = 'expense' THEN %[2]s ELSE 0 END), 0) FROM transactions t%[3]s WHERE t.user_id = $1 AND t.type IN ('income', 'expense') AND t.transaction_date BETWEEN $3 AND $4 GROUP BY t.transaction_date%[4]s ORDER BY t.transaction_date", group, amount, join, groupColumns)
+	rows, err := db.Query(query, userID, baseCurrency, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var result []map[string]interface{}
+	var totals []models.DailyIncomeExpense
 	for rows.Next() {
-		var category string
-		var amount float64
-		if err := rows.Scan(&category, &amount); err != nil {
+		var total models.DailyIncomeExpense
+		if err := rows.Scan(&total.Date, &total.GroupID, &total.GroupName, &total.Income, &total.Expense); err != nil {
 			return nil, err
 		}
-		result = append(result, map[string]interface{}{"category": category, "amount": amount})
+		totals = append(totals, total)
 	}
+	return totals, rows.Err()
+}
 
-	return result, nil
+// GetAccountTransactionsBetween returns the income and expenses booked on the account between
+// the two dates, inclusive.
+func GetAccountTransactionsBetween(accountID uuid.UUID, userID uuid.UUID, startDate time.Time, endDate time.Time, db interfaces.SqlExecutor) ([]models.Transaction, error) {
+	query := "SELECT " + models.TransactionColumns + " FROM transactions WHERE account_id = $1 AND user_id = $2 AND type IN ('income', 'expense') AND transaction_date BETWEEN $3 AND $4"
+	rows, err := db.Query(query, accountID, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []models.Transaction
+	for rows.Next() {
+		var transaction models.Transaction
+		if err := rows.Scan(&transaction.ID, &transaction.UserID, &transaction.AccountID, &transaction.CategoryID, &transaction.BudgetID, &transaction.Description, &transaction.Amount, &transaction.Type, &transaction.TransactionDate, &transaction.Note, &transaction.CreatedAt, &transaction.UpdatedAt, &transaction.Currency, &transaction.DestinationAccountID, &transaction.DestinationAmount, &transaction.ExternalID); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+	return transactions, rows.Err()
+}
+
+// GetUserTransactionsBetween returns the user's income and expenses dated between the two
+// YYYY-MM-DD dates, inclusive, oldest first.
+func GetUserTransactionsBetween(userID uuid.UUID, startDate string, endDate string, db interfaces.SqlExecutor) ([]models.Transaction, error) {
+	query := "SELECT " + models.TransactionColumns + " FROM transactions WHERE user_id = $1 AND type IN ('income', 'expense') AND transaction_date BETWEEN $2 AND $3 ORDER BY transaction_date, created_at"
+	rows, err := db.Query(query, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []models.Transaction
+	for rows.Next() {
+		var transaction models.Transaction
+		if err := rows.Scan(&transaction.ID, &transaction.UserID, &transaction.AccountID, &transaction.CategoryID, &transaction.BudgetID, &transaction.Description, &transaction.Amount, &transaction.Type, &transaction.TransactionDate, &transaction.Note, &transaction.CreatedAt, &transaction.UpdatedAt, &transaction.Currency, &transaction.DestinationAccountID, &transaction.DestinationAmount, &transaction.ExternalID); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+	return transactions, rows.Err()
+}
+
+// GetExistingExternalIDs returns which of the bank identifiers are already used by transactions
+// of the account.
+func GetExistingExternalIDs(accountID uuid.UUID, externalIDs []string, db interfaces.SqlExecutor) (map[string]bool, error) {
+	existing := map[string]bool{}
+	if len(externalIDs) == 0 {
+		return existing, nil
+	}
+
+	rows, err := db.Query("SELECT external_id FROM transactions WHERE account_id = $1 AND external_id = ANY($2)", accountID, pq.Array(externalIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var externalID string
+		if err := rows.Scan(&externalID); err != nil {
+			return nil, err
+		}
+		existing[externalID] = true
+	}
+	return existing, rows.Err()
 }
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+)
+
+// transactionRuleFields returns pointers to the rule's fields in the order of TransactionRuleColumns.
+func transactionRuleFields(rule *models.TransactionRule) []interface{} {
+	return []interface{}{&rule.ID, &rule.UserID, &rule.Name, &rule.Priority, &rule.IsActive, &rule.DescriptionContains, &rule.DescriptionPattern, &rule.MinAmount, &rule.MaxAmount, &rule.AccountID, &rule.NoteContains, &rule.CategoryID, &rule.BudgetID, &rule.Note, &rule.CreatedAt, &rule.UpdatedAt}
+}
+
+func CreateTransactionRule(rule *models.TransactionRule, db interfaces.SqlExecutor) error {
+	query := fmt.Sprintf("INSERT INTO transaction_rules (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)", models.TransactionRuleColumns)
+	_, err := db.Exec(query, rule.ID, rule.UserID, rule.Name, rule.Priority, rule.IsActive, rule.DescriptionContains, rule.DescriptionPattern, rule.MinAmount, rule.MaxAmount, rule.AccountID, rule.NoteContains, rule.CategoryID, rule.BudgetID, rule.Note, rule.CreatedAt, rule.UpdatedAt)
+	return err
+}
+
+// GetTransactionRulesByUserID returns the user's rules in the order they are tried.
+func GetTransactionRulesByUserID(userID uuid.UUID, db interfaces.SqlExecutor) ([]models.TransactionRule, error) {
+	return queryTransactionRules(db, "SELECT "+models.TransactionRuleColumns+" FROM transaction_rules WHERE user_id = $1 ORDER BY priority DESC, created_at, id", userID)
+}
+
+// GetActiveTransactionRules returns the user's active rules in the order they are tried.
+func GetActiveTransactionRules(userID uuid.UUID, db interfaces.SqlExecutor) ([]models.TransactionRule, error) {
+	return queryTransactionRules(db, "SELECT "+models.TransactionRuleColumns+" FROM transaction_rules WHERE user_id = $1 AND is_active ORDER BY priority DESC, created_at, id", userID)
+}
+
+func queryTransactionRules(db interfaces.SqlExecutor, query string, args ...interface{}) ([]models.TransactionRule, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []models.TransactionRule{}
+	for rows.Next() {
+		var rule models.TransactionRule
+		if err := rows.Scan(transactionRuleFields(&rule)...); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func GetTransactionRuleByID(id uuid.UUID, userID uuid.UUID, db interfaces.SqlExecutor) (*models.TransactionRule, error) {
+	query := "SELECT " + models.TransactionRuleColumns + " FROM transaction_rules WHERE id = $1 AND user_id = $2"
+	row := db.QueryRow(query, id, userID)
+
+	var rule models.TransactionRule
+	if err := row.Scan(transactionRuleFields(&rule)...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func UpdateTransactionRule(rule *models.TransactionRule, db interfaces.SqlExecutor) error {
+	query := "UPDATE transaction_rules SET name = $1, priority = $2, is_active = $3, description_contains = $4, description_pattern = $5, min_amount = $6, max_amount = $7, account_id = $8, note_contains = $9, category_id = $10, budget_id = $11, note = $12, updated_at = $13 WHERE id = $14 AND user_id = $15"
+	_, err := db.Exec(query, rule.Name, rule.Priority, rule.IsActive, rule.DescriptionContains, rule.DescriptionPattern, rule.MinAmount, rule.MaxAmount, rule.AccountID, rule.NoteContains, rule.CategoryID, rule.BudgetID, rule.Note, rule.UpdatedAt, rule.ID, rule.UserID)
+	return err
+}
+
+func DeleteTransactionRule(id uuid.UUID, userID uuid.UUID, db interfaces.SqlExecutor) error {
+	query := "DELETE FROM transaction_rules WHERE id = $1 AND user_id = $2"
+	_, err := db.Exec(query, id, userID)
+	return err
+}
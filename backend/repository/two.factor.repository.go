@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+)
+
+// SaveTwoFactorEnrollment stores a new pending secret for the user, replacing an unconfirmed one.
+// It returns false when two-factor authentication is already enabled.
+func SaveTwoFactorEnrollment(twoFactor *models.TwoFactor, db interfaces.SqlExecutor) (bool, error) {
+	query := fmt.Sprintf("INSERT INTO user_two_factor (%s) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_used_step = EXCLUDED.last_used_step, updated_at = EXCLUDED.updated_at WHERE user_two_factor.enabled_at IS NULL", models.TwoFactorColumns)
+	result, err := db.Exec(query, twoFactor.UserID, twoFactor.Secret, twoFactor.EnabledAt, twoFactor.LastUsedStep, twoFactor.CreatedAt, twoFactor.UpdatedAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func GetTwoFactorByUserID(userID uuid.UUID, db interfaces.SqlExecutor) (*models.TwoFactor, error) {
+	query := "SELECT " + models.TwoFactorColumns + " FROM user_two_factor WHERE user_id = $1"
+	row := db.QueryRow(query, userID)
+
+	var twoFactor models.TwoFactor
+	if err := row.Scan(&twoFactor.UserID, &twoFactor.Secret, &twoFactor.EnabledAt, &twoFactor.LastUsedStep, &twoFactor.CreatedAt, &twoFactor.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+// EnableTwoFactor confirms a pending enrollment. It returns false when there is none.
+func EnableTwoFactor(userID uuid.UUID, step int64, enabledAt time.Time, db interfaces.SqlExecutor) (bool, error) {
+	result, err := db.Exec("UPDATE user_two_factor SET enabled_at = $1, last_used_step = $2, updated_at = $1 WHERE user_id = $3 AND enabled_at IS NULL", enabledAt, step, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// UseTwoFactorStep records that a code for the time step was accepted. It returns false when a
+// code for the same or a later step was already used, which rejects replayed codes.
+func UseTwoFactorStep(userID uuid.UUID, step int64, usedAt time.Time, db interfaces.SqlExecutor) (bool, error) {
+	result, err := db.Exec("UPDATE user_two_factor SET last_used_step = $1, updated_at = $2 WHERE user_id = $3 AND last_used_step < $1", step, usedAt, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// DeleteTwoFactor removes the user's secret and recovery codes.
+func DeleteTwoFactor(userID uuid.UUID, db interfaces.SqlExecutor) error {
+	if _, err := db.Exec("DELETE FROM two_factor_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+
+	_, err := db.Exec("DELETE FROM user_two_factor WHERE user_id = $1", userID)
+	return err
+}
+
+// ReplaceRecoveryCodes deletes the user's recovery codes and stores a new set.
+func ReplaceRecoveryCodes(userID uuid.UUID, codeHashes []string, createdAt time.Time, db interfaces.SqlExecutor) error {
+	if _, err := db.Exec("DELETE FROM two_factor_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("INSERT INTO two_factor_recovery_codes (%s) VALUES ($1, $2, $3, $4, $5)", models.TwoFactorRecoveryCodeColumns)
+	for _, codeHash := range codeHashes {
+		if _, err := db.Exec(query, uuid.New(), userID, codeHash, nil, createdAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UseRecoveryCode consumes an unused recovery code. It returns false when none matched.
+func UseRecoveryCode(userID uuid.UUID, codeHash string, usedAt time.Time, db interfaces.SqlExecutor) (bool, error) {
+	result, err := db.Exec("UPDATE two_factor_recovery_codes SET used_at = $1 WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL", usedAt, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func CountUnusedRecoveryCodes(userID uuid.UUID, db interfaces.SqlExecutor) (int, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM two_factor_recovery_codes WHERE user_id = $1 AND used_at IS NULL", userID).Scan(&count)
+	return count, err
+}
+
+func CreateTwoFactorChallenge(challenge *models.TwoFactorChallenge, db interfaces.SqlExecutor) error {
+	query := fmt.Sprintf("INSERT INTO two_factor_challenges (%s) VALUES ($1, $2, $3, $4, $5, $6)", models.TwoFactorChallengeColumns)
+	_, err := db.Exec(query, challenge.ID, challenge.UserID, challenge.Attempts, challenge.ExpiresAt, challenge.UsedAt, challenge.CreatedAt)
+	return err
+}
+
+// AttemptTwoFactorChallenge counts an attempt against an open challenge and returns it. It
+// returns nil when the challenge is unknown, expired, completed or out of attempts.
+func AttemptTwoFactorChallenge(id uuid.UUID, now time.Time, maxAttempts int, db interfaces.SqlExecutor) (*models.TwoFactorChallenge, error) {
+	query := "UPDATE two_factor_challenges SET attempts = attempts + 1 WHERE id = $1 AND used_at IS NULL AND expires_at > $2 AND attempts < $3 RETURNING " + models.TwoFactorChallengeColumns
+	row := db.QueryRow(query, id, now, maxAttempts)
+
+	var challenge models.TwoFactorChallenge
+	if err := row.Scan(&challenge.ID, &challenge.UserID, &challenge.Attempts, &challenge.ExpiresAt, &challenge.UsedAt, &challenge.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// CompleteTwoFactorChallenge closes a challenge once its code was accepted. It returns false when
+// the challenge was already completed.
+func CompleteTwoFactorChallenge(id uuid.UUID, usedAt time.Time, db interfaces.SqlExecutor) (bool, error) {
+	result, err := db.Exec("UPDATE two_factor_challenges SET used_at = $1 WHERE id = $2 AND used_at IS NULL", usedAt, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// DeleteStaleTwoFactorChallenges removes challenges that expired before the given time.
+func DeleteStaleTwoFactorChallenges(before time.Time, db interfaces.SqlExecutor) (int64, error) {
+	result, err := db.Exec("DELETE FROM two_factor_challenges WHERE expires_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
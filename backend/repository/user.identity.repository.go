@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+)
+
+func CreateUserIdentity(identity *models.UserIdentity, db interfaces.SqlExecutor) error {
+	query := fmt.Sprintf("INSERT INTO user_identities (%s) VALUES ($1, $2, $3, $4, $5, $6)", models.UserIdentityColumns)
+	_, err := db.Exec(query, identity.ID, identity.UserID, identity.Provider, identity.Subject, identity.Email, identity.CreatedAt)
+	return err
+}
+
+// GetUserIdentityBySubject finds the identity a provider login belongs to. It returns nil when
+// the login is not linked to any account.
+func GetUserIdentityBySubject(provider models.AuthProvider, subject string, db interfaces.SqlExecutor) (*models.UserIdentity, error) {
+	query := "SELECT " + models.UserIdentityColumns + " FROM user_identities WHERE provider = $1 AND subject = $2"
+	row := db.QueryRow(query, provider, subject)
+
+	var identity models.UserIdentity
+	if err := row.Scan(&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email, &identity.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func GetUserIdentitiesByUserID(userID uuid.UUID, db interfaces.SqlExecutor) ([]models.UserIdentity, error) {
+	query := "SELECT " + models.UserIdentityColumns + " FROM user_identities WHERE user_id = $1 ORDER BY created_at"
+	rows, err := db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := []models.UserIdentity{}
+	for rows.Next() {
+		var identity models.UserIdentity
+		if err := rows.Scan(&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email, &identity.CreatedAt); err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+	return identities, nil
+}
+
+// DeleteUserIdentity unlinks the user's identity at a provider. It returns false when none was linked.
+func DeleteUserIdentity(userID uuid.UUID, provider models.AuthProvider, db interfaces.SqlExecutor) (bool, error) {
+	result, err := db.Exec("DELETE FROM user_identities WHERE user_id = $1 AND provider = $2", userID, provider)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+)
+
+// GetUserPreferences returns the user's stored preferences without the base currency, or nil
+// when they never changed any.
+func GetUserPreferences(userID uuid.UUID, db interfaces.SqlExecutor) (*models.UserPreferences, error) {
+	query := "SELECT " + models.UserPreferencesColumns + " FROM user_preferences WHERE user_id = $1"
+	row := db.QueryRow(query, userID)
+
+	var preferences models.UserPreferences
+	var id uuid.UUID
+	if err := row.Scan(&id, &preferences.Timezone, &preferences.Locale, &preferences.DateFormat, &preferences.WeekStart, &preferences.DefaultAccountID, &preferences.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &preferences, nil
+}
+
+// UpsertUserPreferences stores the user's preferences except the base currency.
+func UpsertUserPreferences(userID uuid.UUID, preferences *models.UserPreferences, db interfaces.SqlExecutor) error {
+	query := fmt.Sprintf("INSERT INTO user_preferences (%s) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (user_id) DO UPDATE SET timezone = EXCLUDED.timezone, locale = EXCLUDED.locale, date_format = EXCLUDED.date_format, week_start = EXCLUDED.week_start, default_account_id = EXCLUDED.default_account_id, updated_at = EXCLUDED.updated_at", models.UserPreferencesColumns)
+	_, err := db.Exec(query, userID, preferences.Timezone, preferences.Locale, preferences.DateFormat, preferences.WeekStart, preferences.DefaultAccountID, preferences.UpdatedAt)
+	return err
+}
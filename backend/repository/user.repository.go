@@ -3,6 +3,7 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
@@ -10,17 +11,17 @@ import (
 )
 
 func CreateUser(user *models.User, db interfaces.SqlExecutor) error {
-	query := fmt.Sprintf("INSERT INTO users (%s) VALUES ($1, $2, $3, $4, $5, $6)", models.UserColumns)
-	_, err := db.Exec(query, user.ID, user.Name, user.Email, user.Password, user.Provider, user.CreatedAt)
+	query := fmt.Sprintf("INSERT INTO users (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)", models.UserColumns)
+	_, err := db.Exec(query, user.ID, user.Name, user.Email, user.Password, user.Provider, user.CreatedAt, user.BaseCurrency, user.EmailVerifiedAt, user.DeletionScheduledAt)
 	return err
 }
 
 func GetUserByEmail(email string, db interfaces.SqlExecutor) (*models.User, error) {
-	query := "SELECT * FROM users WHERE email = $1"
+	query := "SELECT " + models.UserColumns + " FROM users WHERE email = $1"
 	row := db.QueryRow(query, email)
 	var user models.User
 
-	if err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.Provider, &user.CreatedAt); err != nil {
+	if err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.Provider, &user.CreatedAt, &user.BaseCurrency, &user.EmailVerifiedAt, &user.DeletionScheduledAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, err // Or a custom not found error
 		}
@@ -30,11 +31,11 @@ func GetUserByEmail(email string, db interfaces.SqlExecutor) (*models.User, erro
 }
 
 func GetUserByID(id uuid.UUID, db interfaces.SqlExecutor) (*models.User, error) {
-	query := "SELECT * FROM users WHERE id = $1"
+	query := "SELECT " + models.UserColumns + " FROM users WHERE id = $1"
 	row := db.QueryRow(query, id)
 
 	var user models.User
-	if err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.Provider, &user.CreatedAt); err != nil {
+	if err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.Provider, &user.CreatedAt, &user.BaseCurrency, &user.EmailVerifiedAt, &user.DeletionScheduledAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Or a custom not found error
 		}
@@ -44,7 +45,52 @@ func GetUserByID(id uuid.UUID, db interfaces.SqlExecutor) (*models.User, error)
 }
 
 func UpdateUser(user *models.User, db interfaces.SqlExecutor) error {
-	query := "UPDATE users SET name = $1, email = $2, password = $3 WHERE id = $4"
-	_, err := db.Exec(query, user.Name, user.Email, user.Password, user.ID)
+	query := "UPDATE users SET name = $1, email = $2, password = $3, base_currency = $4 WHERE id = $5"
+	_, err := db.Exec(query, user.Name, user.Email, user.Password, user.BaseCurrency, user.ID)
+	return err
+}
+
+// SetUserEmailVerified records when the user verified their email address.
+func SetUserEmailVerified(id uuid.UUID, verifiedAt time.Time, db interfaces.SqlExecutor) error {
+	_, err := db.Exec("UPDATE users SET email_verified_at = $1 WHERE id = $2", verifiedAt, id)
+	return err
+}
+
+// SetUserDeletionScheduledAt schedules the user's account for deletion, or cancels it when at is nil.
+func SetUserDeletionScheduledAt(id uuid.UUID, at *time.Time, db interfaces.SqlExecutor) error {
+	_, err := db.Exec("UPDATE users SET deletion_scheduled_at = $1 WHERE id = $2", at, id)
+	return err
+}
+
+// GetUsersDueForDeletion returns the users whose scheduled deletion time has passed.
+func GetUsersDueForDeletion(now time.Time, db interfaces.SqlExecutor) ([]models.User, error) {
+	query := "SELECT " + models.UserColumns + " FROM users WHERE deletion_scheduled_at <= $1"
+	rows, err := db.Query(query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.Provider, &user.CreatedAt, &user.BaseCurrency, &user.EmailVerifiedAt, &user.DeletionScheduledAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// DeleteUser removes the user. Everything they own goes with them through ON DELETE CASCADE,
+// except rows that reference categories with ON DELETE RESTRICT, which have to be deleted first.
+func DeleteUser(id uuid.UUID, db interfaces.SqlExecutor) error {
+	_, err := db.Exec("DELETE FROM users WHERE id = $1", id)
+	return err
+}
+
+// UpdateUserEmail changes the user's email address and marks it unverified.
+func UpdateUserEmail(id uuid.UUID, email string, db interfaces.SqlExecutor) error {
+	_, err := db.Exec("UPDATE users SET email = $1, email_verified_at = NULL WHERE id = $2", email, id)
 	return err
 }
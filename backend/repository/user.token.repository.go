@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+)
+
+func CreateUserToken(token *models.UserToken, db interfaces.SqlExecutor) error {
+	query := fmt.Sprintf("INSERT INTO user_tokens (%s) VALUES ($1, $2, $3, $4, $5, $6, $7)", models.UserTokenColumns)
+	_, err := db.Exec(query, token.ID, token.UserID, token.Purpose, token.TokenHash, token.ExpiresAt, token.UsedAt, token.CreatedAt)
+	return err
+}
+
+// InvalidateUserTokens marks the user's unused tokens for a purpose as used, so that only the
+// most recently issued one works.
+func InvalidateUserTokens(userID uuid.UUID, purpose models.UserTokenPurpose, usedAt time.Time, db interfaces.SqlExecutor) error {
+	_, err := db.Exec("UPDATE user_tokens SET used_at = $1 WHERE user_id = $2 AND purpose = $3 AND used_at IS NULL", usedAt, userID, purpose)
+	return err
+}
+
+// ConsumeUserToken marks an unused, unexpired token as used and returns it. It returns nil when
+// no such token exists, so a token can never be redeemed twice.
+func ConsumeUserToken(tokenHash string, purpose models.UserTokenPurpose, now time.Time, db interfaces.SqlExecutor) (*models.UserToken, error) {
+	query := "UPDATE user_tokens SET used_at = $1 WHERE token_hash = $2 AND purpose = $3 AND used_at IS NULL AND expires_at > $1 RETURNING " + models.UserTokenColumns
+	row := db.QueryRow(query, now, tokenHash, purpose)
+
+	var token models.UserToken
+	if err := row.Scan(&token.ID, &token.UserID, &token.Purpose, &token.TokenHash, &token.ExpiresAt, &token.UsedAt, &token.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// DeleteStaleUserTokens removes tokens that expired before the given time and returns how many were deleted.
+func DeleteStaleUserTokens(before time.Time, db interfaces.SqlExecutor) (int64, error) {
+	result, err := db.Exec("DELETE FROM user_tokens WHERE expires_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -3,14 +3,21 @@ package routes
 import (
 	"github.com/gofiber/fiber/v2"
 	v1 "github.com/rahulcodepython/finance-tracker-backend/api/v1"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/middleware"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/pkg/ratelimit"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
 )
 
-func Setup(app *fiber.App) {
+func Setup(app *fiber.App, cfg *config.Config) {
 	app.Use(middleware.Logger())
 
+	// Credential endpoints get a stricter limit per IP, everything else is limited per user.
+	rateLimitStore := middleware.NewRateLimitStore(cfg, database.DB)
+	authLimiter := middleware.RateLimiter("auth", ratelimit.Limit{Max: cfg.RateLimit.AuthMax, Period: cfg.RateLimit.AuthDuration}, rateLimitStore)
+	apiLimiter := middleware.RateLimiter("api", ratelimit.Limit{Max: cfg.RateLimit.Max, Period: cfg.RateLimit.Duration}, rateLimitStore)
+
 	api := app.Group("/api")
 
 	v1Api := api.Group("/v1")
@@ -30,52 +37,112 @@ func Setup(app *fiber.App) {
 	})
 
 	auth := v1Api.Group("/auth")
-	auth.Post("/register", v1.Register)
-	auth.Post("/login", v1.Login)
-	auth.Get("/profile", middleware.DeserializeUser, v1.GetProfile)
-	auth.Post("/change-password", middleware.DeserializeUser, v1.ChangePassword)
-	auth.Get("/google/login", v1.GoogleLogin)
-	auth.Get("/google/callback", v1.GoogleCallback)
-
-	accounts := v1Api.Group("/accounts", middleware.DeserializeUser)
+	auth.Post("/register", authLimiter, v1.Register)
+	auth.Post("/login", authLimiter, v1.Login)
+	auth.Post("/refresh", authLimiter, v1.RefreshToken)
+	auth.Get("/verify-email", authLimiter, v1.VerifyEmail)
+	auth.Post("/verify-email/resend", authLimiter, v1.ResendVerificationEmail)
+	auth.Post("/forgot-password", authLimiter, v1.ForgotPassword)
+	auth.Post("/reset-password", authLimiter, v1.ResetPassword)
+	auth.Post("/2fa/verify", authLimiter, v1.VerifyTwoFactorLogin)
+	auth.Get("/2fa", middleware.DeserializeUser, apiLimiter, v1.GetTwoFactorStatus)
+	auth.Post("/2fa/setup", middleware.DeserializeUser, apiLimiter, v1.SetupTwoFactor)
+	auth.Post("/2fa/enable", middleware.DeserializeUser, apiLimiter, v1.EnableTwoFactor)
+	auth.Post("/2fa/disable", middleware.DeserializeUser, apiLimiter, v1.DisableTwoFactor)
+	auth.Post("/2fa/recovery-codes", middleware.DeserializeUser, apiLimiter, v1.RegenerateRecoveryCodes)
+	auth.Post("/logout", middleware.DeserializeUser, apiLimiter, v1.Logout)
+	auth.Get("/sessions", middleware.DeserializeUser, apiLimiter, v1.GetSessions)
+	auth.Delete("/sessions", middleware.DeserializeUser, apiLimiter, v1.RevokeOtherSessions)
+	auth.Delete("/sessions/:id", middleware.DeserializeUser, apiLimiter, v1.RevokeSession)
+	auth.Get("/profile", middleware.DeserializeUser, apiLimiter, v1.GetProfile)
+	auth.Patch("/profile", middleware.DeserializeUser, apiLimiter, v1.UpdateProfile)
+	auth.Get("/preferences", middleware.DeserializeUser, apiLimiter, v1.GetPreferences)
+	auth.Patch("/preferences", middleware.DeserializeUser, apiLimiter, v1.UpdatePreferences)
+	auth.Get("/me/export", middleware.DeserializeUser, apiLimiter, v1.ExportUserData)
+	auth.Delete("/me", middleware.DeserializeUser, apiLimiter, v1.DeleteUserAccount)
+	auth.Post("/me/restore", middleware.DeserializeUser, apiLimiter, v1.RestoreUserAccount)
+	auth.Post("/change-password", middleware.DeserializeUser, apiLimiter, v1.ChangePassword)
+	auth.Patch("/base-currency", middleware.DeserializeUser, apiLimiter, v1.UpdateBaseCurrency)
+	auth.Get("/google/login", authLimiter, v1.GoogleLogin)
+	auth.Get("/google/callback", authLimiter, v1.GoogleCallback)
+	auth.Get("/google/link", middleware.DeserializeUser, apiLimiter, v1.GoogleLink)
+	auth.Get("/identities", middleware.DeserializeUser, apiLimiter, v1.GetIdentities)
+	auth.Delete("/identities/google", middleware.DeserializeUser, apiLimiter, v1.UnlinkGoogle)
+
+	accounts := v1Api.Group("/accounts", middleware.DeserializeUser, apiLimiter)
 	accounts.Post("/create", v1.CreateAccount)
 	accounts.Get("/", v1.GetAccounts)
 	accounts.Patch("/update/:id", v1.UpdateAccount)
 	accounts.Delete("/delete/:id", v1.DeleteAccount)
 	accounts.Get("/total-balance", v1.GetTotalBalance)
 
-	transactions := v1Api.Group("/transactions", middleware.DeserializeUser)
+	transactions := v1Api.Group("/transactions", middleware.DeserializeUser, apiLimiter)
 	transactions.Post("/create", v1.CreateTransaction)
 	transactions.Get("/", v1.GetTransactions)
 	transactions.Patch("/update/:id", v1.UpdateTransaction)
 	transactions.Delete("/delete/:id", v1.DeleteTransaction)
 	transactions.Get("/aggregate", v1.GetAggregateData)
-
-	dashboard := v1Api.Group("/dashboard", middleware.DeserializeUser)
+	transactions.Post("/transfers/create", v1.CreateTransfer)
+	transactions.Patch("/transfers/update/:id", v1.UpdateTransfer)
+	transactions.Post("/import", v1.ImportTransactions)
+	transactions.Post("/import/statements", v1.ImportStatements)
+	transactions.Get("/import/profiles", v1.GetImportProfiles)
+	transactions.Post("/import/profiles/create", v1.CreateImportProfile)
+	transactions.Patch("/import/profiles/update/:id", v1.UpdateImportProfile)
+	transactions.Delete("/import/profiles/delete/:id", v1.DeleteImportProfile)
+
+	rules := v1Api.Group("/rules", middleware.DeserializeUser, apiLimiter)
+	rules.Post("/create", v1.CreateTransactionRule)
+	rules.Get("/", v1.GetTransactionRules)
+	rules.Patch("/update/:id", v1.UpdateTransactionRule)
+	rules.Delete("/delete/:id", v1.DeleteTransactionRule)
+	rules.Post("/apply", v1.ApplyTransactionRules)
+	rules.Post("/test", v1.TestTransactionRules)
+
+	dashboard := v1Api.Group("/dashboard", middleware.DeserializeUser, apiLimiter)
 	dashboard.Get("/", v1.GetDashboardSummary)
 
-	reports := v1Api.Group("/reports", middleware.DeserializeUser)
+	reports := v1Api.Group("/reports", middleware.DeserializeUser, apiLimiter)
 	reports.Get("/", v1.GenerateReport)
 	reports.Get("/export", v1.ExportTransactions)
+	reports.Get("/income-vs-expense", v1.GetIncomeVsExpense)
 
-	categories := v1Api.Group("/categories", middleware.DeserializeUser)
+	categories := v1Api.Group("/categories", middleware.DeserializeUser, apiLimiter)
 	categories.Post("/create", v1.CreateCategory)
 	categories.Get("/", v1.GetCategories)
 	categories.Patch("/update/:id", v1.UpdateCategory)
 	categories.Delete("/delete/:id", v1.DeleteCategory)
 
-	budgets := v1Api.Group("/budgets", middleware.DeserializeUser)
+	budgets := v1Api.Group("/budgets", middleware.DeserializeUser, apiLimiter)
 	budgets.Post("/create", v1.CreateBudget)
 	budgets.Get("/", v1.GetBudgets)
+	budgets.Get("/:id", v1.GetBudget)
+	budgets.Get("/:id/history", v1.GetBudgetHistory)
 	budgets.Patch("/update/:id", v1.UpdateBudget)
 	budgets.Delete("/delete/:id", v1.DeleteBudget)
 
-	recurringTransactions := v1Api.Group("/recurring-transactions", middleware.DeserializeUser)
+	recurringTransactions := v1Api.Group("/recurring-transactions", middleware.DeserializeUser, apiLimiter)
 	recurringTransactions.Post("/create", v1.CreateRecurringTransaction)
 	recurringTransactions.Get("/", v1.GetRecurringTransactions)
+	recurringTransactions.Get("/:id/preview", v1.PreviewRecurringTransaction)
+	recurringTransactions.Post("/:id/skip", v1.SkipRecurringOccurrence)
+	recurringTransactions.Post("/:id/override", v1.OverrideRecurringOccurrence)
+	recurringTransactions.Delete("/:id/exceptions/:date", v1.RestoreRecurringOccurrence)
 	recurringTransactions.Patch("/update/:id", v1.UpdateRecurringTransaction)
+	recurringTransactions.Patch("/pause/:id", v1.PauseRecurringTransaction)
+	recurringTransactions.Patch("/resume/:id", v1.ResumeRecurringTransaction)
 	recurringTransactions.Delete("/delete/:id", v1.DeleteRecurringTransaction)
 
-	logs := v1Api.Group("/logs", middleware.DeserializeUser)
+	exchangeRates := v1Api.Group("/exchange-rates")
+	exchangeRates.Get("/", middleware.DeserializeUser, apiLimiter, v1.GetExchangeRates)
+	exchangeRates.Post("/create", apiLimiter, middleware.RequireAdminKey, v1.CreateExchangeRate)
+	exchangeRates.Post("/import", apiLimiter, middleware.RequireAdminKey, v1.ImportExchangeRates)
+
+	notifications := v1Api.Group("/notifications", middleware.DeserializeUser, apiLimiter)
+	notifications.Get("/", v1.GetNotifications)
+	notifications.Patch("/read-all", v1.MarkAllNotificationsRead)
+	notifications.Patch("/read/:id", v1.MarkNotificationRead)
+
+	logs := v1Api.Group("/logs", middleware.DeserializeUser, apiLimiter)
 	logs.Get("/", v1.GetLogs)
 }
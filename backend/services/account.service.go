@@ -11,13 +11,26 @@ import (
 	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
 )
 
-func CreateAccount(userID uuid.UUID, name string, accountType models.AccountType, balance float64, db *sql.DB) (*models.Account, error) {
+func CreateAccount(userID uuid.UUID, name string, accountType models.AccountType, balance models.Money, currency models.Currency, db *sql.DB) (*models.Account, error) {
+	if currency == "" {
+		baseCurrency, err := GetBaseCurrency(userID, db)
+		if err != nil {
+			return nil, err
+		}
+		currency = baseCurrency
+	}
+
+	if !currency.IsValid() {
+		return nil, ErrInvalidCurrency
+	}
+
 	account := &models.Account{
 		ID:       uuid.New(),
 		UserID:   userID,
 		Name:     name,
 		Type:     accountType,
 		Balance:  balance,
+		Currency: currency,
 		IsActive: true,
 	}
 
@@ -36,8 +49,8 @@ func GetAccounts(userID uuid.UUID, db *sql.DB) ([]models.Account, error) {
 	return repository.GetAccountsByUserID(userID, db)
 }
 
-func CheckAccountExistsById(id uuid.UUID, db *sql.DB) (bool, error) {
-	account, err := repository.GetAccountByID(id, db)
+func CheckAccountExistsById(id uuid.UUID, userID uuid.UUID, db *sql.DB) (bool, error) {
+	account, err := repository.GetAccountByID(id, userID, db)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return false, nil
@@ -52,8 +65,8 @@ func CheckAccountExistsById(id uuid.UUID, db *sql.DB) (bool, error) {
 	return false, nil
 }
 
-func UpdateAccount(id uuid.UUID, name string, accountType models.AccountType, isActive bool, db *sql.DB) (*models.Account, error) {
-	account, err := repository.GetAccountByID(id, db)
+func UpdateAccount(id uuid.UUID, userID uuid.UUID, name string, accountType models.AccountType, isActive bool, db *sql.DB) (*models.Account, error) {
+	account, err := repository.GetAccountByID(id, userID, db)
 	if err != nil {
 		return nil, err
 	}
@@ -78,8 +91,8 @@ func UpdateAccount(id uuid.UUID, name string, accountType models.AccountType, is
 	return account, nil
 }
 
-func DeleteAccount(id uuid.UUID, db *sql.DB) error {
-	account, err := repository.GetAccountByID(id, db)
+func DeleteAccount(id uuid.UUID, userID uuid.UUID, db *sql.DB) error {
+	account, err := repository.GetAccountByID(id, userID, db)
 	if err != nil {
 		return err
 	}
@@ -88,7 +101,7 @@ func DeleteAccount(id uuid.UUID, db *sql.DB) error {
 		return sql.ErrNoRows
 	}
 
-	err = repository.DeleteAccount(id, db)
+	err = repository.DeleteAccount(id, userID, db)
 	if err != nil {
 		return err
 	}
@@ -99,16 +112,33 @@ func DeleteAccount(id uuid.UUID, db *sql.DB) error {
 	return nil
 }
 
-func GetTotalBalance(userID uuid.UUID, db *sql.DB) (float64, error) {
+// GetTotalBalance sums the balances of the user's active accounts, converted into the user's base currency at the latest known rate.
+func GetTotalBalance(userID uuid.UUID, db *sql.DB) (models.Money, error) {
+	baseCurrency, err := GetBaseCurrency(userID, db)
+	if err != nil {
+		return 0, err
+	}
+
 	accounts, err := repository.GetAccountsByUserID(userID, db)
 	if err != nil {
 		return 0, err
 	}
 
-	var totalBalance float64
+	today := time.Now().In(utils.LOC)
+
+	var totalBalance models.Money
 	for _, account := range accounts {
-		if account.IsActive {
-			totalBalance += account.Balance
+		if !account.IsActive {
+			continue
+		}
+
+		balance, err := ConvertAmount(account.Balance, account.Currency, baseCurrency, today, db)
+		if err != nil {
+			return 0, err
+		}
+		totalBalance, err = totalBalance.AddChecked(balance)
+		if err != nil {
+			return 0, err
 		}
 	}
 
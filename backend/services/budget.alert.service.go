@@ -0,0 +1,97 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+// CheckBudgetAlerts compares the current period of every budget owned by the user against its
+// alert thresholds. Each threshold is recorded once per budget period; when several thresholds
+// are crossed at once a single notification is raised for the highest of them. A budget that
+// fails to be checked is logged and the remaining budgets are still checked.
+func CheckBudgetAlerts(userID uuid.UUID, db *sql.DB) error {
+	budgets, err := GetBudgets(userID, db)
+	if err != nil {
+		return err
+	}
+
+	today := UserToday(userID, db)
+
+	for i := range budgets {
+		if err := checkBudgetAlert(userID, &budgets[i], today, db); err != nil {
+			log.Printf("Error checking alerts of budget %s: %v", budgets[i].ID, err)
+		}
+	}
+
+	return nil
+}
+
+// checkBudgetAlert records the thresholds the budget's current period has crossed and raises the
+// notification in the same database transaction, so a threshold is never marked as sent without
+// its notification.
+func checkBudgetAlert(userID uuid.UUID, budget *models.BudgetWithStatus, today time.Time, db *sql.DB) error {
+	current := budget.Current
+	if today.Before(current.PeriodStart) || today.After(current.PeriodEnd) {
+		return nil
+	}
+
+	return utils.DBTransaction(db, func(tx *sql.Tx) error {
+		var crossed int64
+		for _, threshold := range budget.AlertThresholds {
+			if current.PercentUsed < float64(threshold) {
+				continue
+			}
+
+			recorded, err := repository.RecordBudgetAlert(budget.ID, current.PeriodStart, threshold, time.Now().In(utils.LOC), tx)
+			if err != nil {
+				return err
+			}
+
+			if recorded && threshold > crossed {
+				crossed = threshold
+			}
+		}
+
+		if crossed == 0 {
+			return nil
+		}
+
+		title := fmt.Sprintf("Budget '%s' reached %d%%", budget.Name, crossed)
+		message := fmt.Sprintf("You have spent %s of %s (%.2f%%) for the period %s to %s.", current.Spent, current.Limit.Add(current.CarriedOver), current.PercentUsed, current.PeriodStart.Format("2006-01-02"), current.PeriodEnd.Format("2006-01-02"))
+
+		_, err := CreateNotification(userID, models.NotificationTypeBudgetAlert, title, message, uuid.NullUUID{UUID: budget.ID, Valid: true}, tx)
+		return err
+	})
+}
+
+// checkBudgetAlertsAsync runs CheckBudgetAlerts in the background after a transaction changes.
+func checkBudgetAlertsAsync(userID uuid.UUID, db *sql.DB) {
+	go func() {
+		if err := CheckBudgetAlerts(userID, db); err != nil {
+			log.Println("Error checking budget alerts:", err)
+		}
+	}()
+}
+
+// CheckAllBudgetAlerts checks the budgets of every user. It is run by the scheduler so that alerts
+// also fire for changes that do not go through the transaction endpoints, such as new exchange rates.
+func CheckAllBudgetAlerts(db *sql.DB) {
+	userIDs, err := repository.GetUserIDsWithBudgets(db)
+	if err != nil {
+		log.Println("Error getting users with budgets:", err)
+		return
+	}
+
+	for _, userID := range userIDs {
+		if err := CheckBudgetAlerts(userID, db); err != nil {
+			log.Printf("Error checking budget alerts for user %s: %v", userID, err)
+		}
+	}
+}
@@ -2,7 +2,10 @@ package services
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"math"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,15 +14,104 @@ import (
 	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
 )
 
-func CreateBudget(userID uuid.UUID, name string, amount float64, db *sql.DB) (*models.Budget, error) {
+var (
+	ErrInvalidBudgetPeriod   = errors.New("period must be one of weekly, monthly, quarterly, yearly or custom")
+	ErrInvalidBudgetLimit    = errors.New("budget limit must be greater than zero")
+	ErrInvalidBudgetDates    = errors.New("custom budgets need an end date on or after the start date, other periods must not set one")
+	ErrInvalidBudgetCategory = errors.New("budgets can only be scoped to an expense category")
+	ErrInvalidAlertThreshold = errors.New("alert thresholds must be whole percentages between 1 and 1000")
+)
+
+// dateOnly strips the time of day so that period arithmetic works on whole calendar days.
+func dateOnly(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// normalizeAlertThresholds validates the thresholds and returns them sorted without duplicates.
+// A nil slice selects the default thresholds while an empty one disables alerts.
+func normalizeAlertThresholds(thresholds []int64) ([]int64, error) {
+	if thresholds == nil {
+		return models.DefaultBudgetAlertThresholds, nil
+	}
+
+	normalized := make([]int64, 0, len(thresholds))
+	for _, threshold := range thresholds {
+		if threshold < 1 || threshold > 1000 {
+			return nil, ErrInvalidAlertThreshold
+		}
+		if !slices.Contains(normalized, threshold) {
+			normalized = append(normalized, threshold)
+		}
+	}
+	slices.Sort(normalized)
+
+	return normalized, nil
+}
+
+func validateBudget(budget *models.Budget, db *sql.DB) error {
+	if !budget.Period.IsValid() {
+		return ErrInvalidBudgetPeriod
+	}
+
+	if !budget.Limit.IsPositive() {
+		return ErrInvalidBudgetLimit
+	}
+
+	if budget.Period == models.BudgetPeriodCustom {
+		if budget.EndDate == nil || budget.EndDate.Before(budget.StartDate) {
+			return ErrInvalidBudgetDates
+		}
+	} else if budget.EndDate != nil {
+		return ErrInvalidBudgetDates
+	}
+
+	if budget.CategoryID.Valid {
+		category, err := repository.GetCategoryByID(budget.CategoryID.UUID, budget.UserID, db)
+		if err != nil {
+			return err
+		}
+		if category == nil {
+			return sql.ErrNoRows
+		}
+		if category.Type != models.TransactionTypeExpense {
+			return ErrInvalidBudgetCategory
+		}
+	}
+
+	return nil
+}
+
+func CreateBudget(userID uuid.UUID, name string, limit models.Money, period models.BudgetPeriod, startDate time.Time, endDate *time.Time, categoryID uuid.NullUUID, rollover bool, alertThresholds []int64, db *sql.DB) (*models.Budget, error) {
+	thresholds, err := normalizeAlertThresholds(alertThresholds)
+	if err != nil {
+		return nil, err
+	}
+
 	budget := &models.Budget{
-		ID:     uuid.New(),
-		UserID: userID,
-		Name:   name,
-		Amount: amount,
+		ID:              uuid.New(),
+		UserID:          userID,
+		Name:            name,
+		Limit:           limit,
+		Period:          period,
+		StartDate:       dateOnly(startDate),
+		CategoryID:      categoryID,
+		Rollover:        rollover,
+		AlertThresholds: thresholds,
+		CreatedAt:       time.Now().In(utils.LOC),
+		UpdatedAt:       time.Now().In(utils.LOC),
+	}
+
+	if endDate != nil {
+		end := dateOnly(*endDate)
+		budget.EndDate = &end
+	}
+
+	if err := validateBudget(budget, db); err != nil {
+		return nil, err
 	}
 
-	err := repository.CreateBudget(budget, db)
+	err = repository.CreateBudget(budget, db)
 	if err != nil {
 		return nil, err
 	}
@@ -30,12 +122,172 @@ func CreateBudget(userID uuid.UUID, name string, amount float64, db *sql.DB) (*m
 	return budget, nil
 }
 
-func GetBudgets(userID uuid.UUID, db *sql.DB) ([]models.Budget, error) {
-	return repository.GetBudgetsByUserID(userID, db)
+func GetBudgets(userID uuid.UUID, db *sql.DB) ([]models.BudgetWithStatus, error) {
+	budgets, err := repository.GetBudgetsByUserID(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(budgets) == 0 {
+		return []models.BudgetWithStatus{}, nil
+	}
+
+	baseCurrency, err := GetBaseCurrency(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := EnsureExchangeRates(userID, baseCurrency, db); err != nil {
+		return nil, err
+	}
+
+	today := UserToday(userID, db)
+
+	result := make([]models.BudgetWithStatus, 0, len(budgets))
+	for i := range budgets {
+		statuses, err := getBudgetStatuses(&budgets[i], baseCurrency, today, db)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, models.BudgetWithStatus{Budget: budgets[i], Current: statuses[len(statuses)-1]})
+	}
+
+	return result, nil
+}
+
+func GetBudget(id uuid.UUID, userID uuid.UUID, db *sql.DB) (*models.BudgetWithStatus, error) {
+	budget, err := repository.GetBudgetByID(id, userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if budget == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	baseCurrency, err := GetBaseCurrency(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := EnsureExchangeRates(userID, baseCurrency, db); err != nil {
+		return nil, err
+	}
+
+	statuses, err := getBudgetStatuses(budget, baseCurrency, UserToday(userID, db), db)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.BudgetWithStatus{Budget: *budget, Current: statuses[len(statuses)-1]}, nil
+}
+
+// GetBudgetHistory returns up to limit periods of a budget, newest first, ending with the current period.
+func GetBudgetHistory(id uuid.UUID, userID uuid.UUID, limit int, db *sql.DB) ([]models.BudgetPeriodStatus, error) {
+	budget, err := repository.GetBudgetByID(id, userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if budget == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	baseCurrency, err := GetBaseCurrency(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := EnsureExchangeRates(userID, baseCurrency, db); err != nil {
+		return nil, err
+	}
+
+	statuses, err := getBudgetStatuses(budget, baseCurrency, UserToday(userID, db), db)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]models.BudgetPeriodStatus, 0, limit)
+	for i := len(statuses) - 1; i >= 0 && len(history) < limit; i-- {
+		history = append(history, statuses[i])
+	}
+
+	return history, nil
+}
+
+// getBudgetStatuses computes every period from the budget's start up to the current one, so that
+// unused amounts can be rolled forward. The current period is the one containing today in the
+// user's time zone. Budgets that have not started yet report their first period.
+func getBudgetStatuses(budget *models.Budget, baseCurrency models.Currency, today time.Time, db *sql.DB) ([]models.BudgetPeriodStatus, error) {
+	budget.StartDate = dateOnly(budget.StartDate)
+	if budget.EndDate != nil {
+		end := dateOnly(*budget.EndDate)
+		budget.EndDate = &end
+	}
+
+	current := budget.PeriodIndexAt(today)
+	if current < 0 {
+		current = 0
+	}
+
+	_, lastDay := budget.PeriodBounds(current)
+
+	spending, err := repository.GetBudgetSpendingByDate(budget, baseCurrency, budget.StartDate.Format("2006-01-02"), lastDay.Format("2006-01-02"), db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]models.BudgetPeriodStatus, 0, current+1)
+	var carriedOver models.Money
+
+	for index := 0; index <= current; index++ {
+		periodStart, periodEnd := budget.PeriodBounds(index)
+
+		var spent models.Money
+		for day := periodStart; !day.After(periodEnd); day = day.AddDate(0, 0, 1) {
+			spent = spent.Add(spending[day.Format("2006-01-02")])
+		}
+
+		available := budget.Limit.Add(carriedOver)
+		remaining := available.Sub(spent)
+
+		statuses = append(statuses, models.BudgetPeriodStatus{
+			PeriodStart: periodStart,
+			PeriodEnd:   periodEnd,
+			Limit:       budget.Limit,
+			CarriedOver: carriedOver,
+			Spent:       spent,
+			Remaining:   remaining,
+			PercentUsed: percentUsed(spent, available),
+		})
+
+		carriedOver = 0
+		if budget.Rollover && remaining.IsPositive() {
+			carriedOver = remaining
+		}
+	}
+
+	return statuses, nil
+}
+
+// percentUsed returns spent as a percentage of available, rounded to two decimals.
+func percentUsed(spent models.Money, available models.Money) float64 {
+	if !available.IsPositive() {
+		if spent.IsPositive() {
+			return 100
+		}
+		return 0
+	}
+	return math.Round(float64(spent)/float64(available)*10000) / 100
 }
 
-func UpdateBudget(id uuid.UUID, name string, amount float64, db *sql.DB) (*models.Budget, error) {
-	budget, err := repository.GetBudgetByID(id, db)
+func UpdateBudget(id uuid.UUID, userID uuid.UUID, name string, limit models.Money, period models.BudgetPeriod, startDate time.Time, endDate *time.Time, categoryID uuid.NullUUID, rollover bool, alertThresholds []int64, db *sql.DB) (*models.Budget, error) {
+	thresholds, err := normalizeAlertThresholds(alertThresholds)
+	if err != nil {
+		return nil, err
+	}
+
+	budget, err := repository.GetBudgetByID(id, userID, db)
 	if err != nil {
 		return nil, err
 	}
@@ -45,9 +297,23 @@ func UpdateBudget(id uuid.UUID, name string, amount float64, db *sql.DB) (*model
 	}
 
 	budget.Name = name
-	budget.Amount = amount
+	budget.Limit = limit
+	budget.Period = period
+	budget.StartDate = dateOnly(startDate)
+	budget.EndDate = nil
+	if endDate != nil {
+		end := dateOnly(*endDate)
+		budget.EndDate = &end
+	}
+	budget.CategoryID = categoryID
+	budget.Rollover = rollover
+	budget.AlertThresholds = thresholds
 	budget.UpdatedAt = time.Now().In(utils.LOC)
 
+	if err := validateBudget(budget, db); err != nil {
+		return nil, err
+	}
+
 	err = repository.UpdateBudget(budget, db)
 	if err != nil {
 		return nil, err
@@ -56,11 +322,13 @@ func UpdateBudget(id uuid.UUID, name string, amount float64, db *sql.DB) (*model
 	// Log the update
 	go CreateLog(budget.UserID, fmt.Sprintf("Budget '%s' updated", budget.Name), db)
 
+	checkBudgetAlertsAsync(budget.UserID, db)
+
 	return budget, nil
 }
 
-func DeleteBudget(id uuid.UUID, db *sql.DB) error {
-	budget, err := repository.GetBudgetByID(id, db)
+func DeleteBudget(id uuid.UUID, userID uuid.UUID, db *sql.DB) error {
+	budget, err := repository.GetBudgetByID(id, userID, db)
 	if err != nil {
 		return err
 	}
@@ -69,7 +337,7 @@ func DeleteBudget(id uuid.UUID, db *sql.DB) error {
 		return sql.ErrNoRows
 	}
 
-	err = repository.DeleteBudget(id, db)
+	err = repository.DeleteBudget(id, userID, db)
 	if err != nil {
 		return err
 	}
@@ -80,8 +348,8 @@ func DeleteBudget(id uuid.UUID, db *sql.DB) error {
 	return nil
 }
 
-func CheckBudgetExistsById(id uuid.UUID, db *sql.DB) (bool, error) {
-	budget, err := repository.GetBudgetByID(id, db)
+func CheckBudgetExistsById(id uuid.UUID, userID uuid.UUID, db *sql.DB) (bool, error) {
+	budget, err := repository.GetBudgetByID(id, userID, db)
 	if err != nil {
 		return false, err
 	}
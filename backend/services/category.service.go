@@ -2,6 +2,7 @@ package services
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -9,15 +10,46 @@ import (
 	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
 )
 
+var (
+	ErrSystemCategory      = errors.New("system categories are read-only")
+	ErrCategoryExists      = errors.New("a category with this name and type already exists")
+	ErrInvalidCategoryType = errors.New("category type must be either income or expense")
+)
+
+func validateCategoryType(categoryType models.TransactionType) error {
+	if categoryType != models.TransactionTypeIncome && categoryType != models.TransactionTypeExpense {
+		return ErrInvalidCategoryType
+	}
+	return nil
+}
+
 func CreateCategory(name string, categoryType models.TransactionType, userID uuid.UUID, db *sql.DB) (*models.Category, error) {
+	if err := validateCategoryType(categoryType); err != nil {
+		return nil, err
+	}
+
+	existing, err := repository.GetCategoryByNameAndType(name, categoryType, userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		return nil, ErrCategoryExists
+	}
+
 	category := &models.Category{
-		ID:   uuid.New(),
-		Name: name,
-		Type: categoryType,
+		ID:     uuid.New(),
+		Name:   name,
+		Type:   categoryType,
+		UserID: uuid.NullUUID{UUID: userID, Valid: true},
 	}
 
-	err := repository.CreateCategory(category, db)
+	err = repository.CreateCategory(category, db)
 	if err != nil {
+		// A concurrent request may have stored the same name after the check above.
+		if repository.IsUniqueViolation(err) {
+			return nil, ErrCategoryExists
+		}
 		return nil, err
 	}
 
@@ -27,12 +59,16 @@ func CreateCategory(name string, categoryType models.TransactionType, userID uui
 	return category, nil
 }
 
-func GetCategories(db *sql.DB) ([]models.Category, error) {
-	return repository.GetCategories(db)
+func GetCategories(userID uuid.UUID, db *sql.DB) ([]models.Category, error) {
+	return repository.GetCategoriesByUserID(userID, db)
 }
 
 func UpdateCategory(id uuid.UUID, name string, categoryType models.TransactionType, userID uuid.UUID, db *sql.DB) (*models.Category, error) {
-	category, err := repository.GetCategoryByID(id, db)
+	if err := validateCategoryType(categoryType); err != nil {
+		return nil, err
+	}
+
+	category, err := repository.GetCategoryByID(id, userID, db)
 	if err != nil {
 		return nil, err
 	}
@@ -41,11 +77,27 @@ func UpdateCategory(id uuid.UUID, name string, categoryType models.TransactionTy
 		return nil, sql.ErrNoRows
 	}
 
+	if category.IsSystem {
+		return nil, ErrSystemCategory
+	}
+
+	existing, err := repository.GetCategoryByNameAndType(name, categoryType, userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil && existing.ID != category.ID {
+		return nil, ErrCategoryExists
+	}
+
 	category.Name = name
 	category.Type = categoryType
 
 	err = repository.UpdateCategory(category, db)
 	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil, ErrCategoryExists
+		}
 		return nil, err
 	}
 
@@ -56,7 +108,7 @@ func UpdateCategory(id uuid.UUID, name string, categoryType models.TransactionTy
 }
 
 func DeleteCategory(id uuid.UUID, userID uuid.UUID, db *sql.DB) error {
-	category, err := repository.GetCategoryByID(id, db)
+	category, err := repository.GetCategoryByID(id, userID, db)
 	if err != nil {
 		return err
 	}
@@ -65,7 +117,11 @@ func DeleteCategory(id uuid.UUID, userID uuid.UUID, db *sql.DB) error {
 		return sql.ErrNoRows
 	}
 
-	err = repository.DeleteCategory(id, db)
+	if category.IsSystem {
+		return ErrSystemCategory
+	}
+
+	err = repository.DeleteCategory(id, userID, db)
 	if err != nil {
 		return err
 	}
@@ -76,8 +132,8 @@ func DeleteCategory(id uuid.UUID, userID uuid.UUID, db *sql.DB) error {
 	return nil
 }
 
-func CheckCategoryExistsById(id uuid.UUID, db *sql.DB) (bool, error) {
-	category, err := repository.GetCategoryByID(id, db)
+func CheckCategoryExistsById(id uuid.UUID, userID uuid.UUID, db *sql.DB) (bool, error) {
+	category, err := repository.GetCategoryByID(id, userID, db)
 	if err != nil {
 		return false, err
 	}
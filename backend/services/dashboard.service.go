@@ -4,10 +4,15 @@ import (
 	"database/sql"
 
 	"github.com/google/uuid"
-	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
 )
 
 func GetDashboardSummary(userID uuid.UUID, page int, limit int, description string, categoryID string, accountID string, budgetID string, startDate string, endDate string, db *sql.DB) (map[string]interface{}, error) {
+	baseCurrency, err := GetBaseCurrency(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get total balance
 	totalBalance, err := GetTotalBalance(userID, db)
 	if err != nil {
@@ -25,25 +30,32 @@ func GetDashboardSummary(userID uuid.UUID, page int, limit int, description stri
 		return nil, err
 	}
 
-	spendingByCategory, err := repository.GetSpendingByCategory(userID, db)
+	spendingByCategory, err := GetSpendingByCategory(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	earningByCategory, err := GetEarningByCategory(userID, db)
 	if err != nil {
 		return nil, err
 	}
 
-	earningByCategory, err := repository.GetEarningByCategory(userID, db)
+	// Income against expense over the last twelve months
+	incomeVsExpense, err := GetIncomeVsExpense(userID, models.TimeSeriesMonth, "", "", "", db)
 	if err != nil {
 		return nil, err
 	}
 
 	return map[string]interface{}{
 		"summary": map[string]interface{}{
+			"baseCurrency":    baseCurrency,
 			"totalBalance":    totalBalance,
 			"monthlyIncome":   aggregateData["totalIncome"],
 			"monthlyExpenses": aggregateData["totalExpenses"],
 			"monthlySavings":  aggregateData["netIncome"],
 		},
 		"graphs": map[string]interface{}{
-			"incomeVsExpense":    []map[string]interface{}{},
+			"incomeVsExpense":    incomeVsExpense.Points,
 			"spendingByCategory": spendingByCategory,
 			"earningByCategory":  earningByCategory,
 		},
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+var (
+	ErrInvalidCurrency     = errors.New("currency must be a three-letter ISO 4217 code")
+	ErrMissingExchangeRate = errors.New("no exchange rate available")
+	ErrInvalidRatesFile    = errors.New("invalid exchange rates file")
+)
+
+// ConvertAmount converts an amount between currencies using the rate closest to the given date.
+func ConvertAmount(amount models.Money, from models.Currency, to models.Currency, date time.Time, db *sql.DB) (models.Money, error) {
+	if from == to {
+		return amount, nil
+	}
+
+	rate, err := repository.GetExchangeRate(from, to, date.Format("2006-01-02"), db)
+	if err != nil {
+		return 0, err
+	}
+
+	if rate == nil {
+		return 0, fmt.Errorf("%w from %s to %s", ErrMissingExchangeRate, from, to)
+	}
+
+	return amount.Convert(*rate)
+}
+
+// EnsureExchangeRates fails when any currency used by the user cannot be converted into baseCurrency,
+// so that totals are never silently computed from a partial set of transactions.
+func EnsureExchangeRates(userID uuid.UUID, baseCurrency models.Currency, db *sql.DB) error {
+	missing, err := repository.GetCurrenciesWithoutExchangeRate(userID, baseCurrency, db)
+	if err != nil {
+		return err
+	}
+
+	if len(missing) > 0 {
+		codes := make([]string, len(missing))
+		for i, currency := range missing {
+			codes[i] = string(currency)
+		}
+		return fmt.Errorf("%w from %s to %s", ErrMissingExchangeRate, strings.Join(codes, ", "), baseCurrency)
+	}
+
+	return nil
+}
+
+func CreateExchangeRate(baseCurrency models.Currency, quoteCurrency models.Currency, rate models.Rate, rateDate time.Time, db *sql.DB) (*models.ExchangeRate, error) {
+	if !baseCurrency.IsValid() || !quoteCurrency.IsValid() || baseCurrency == quoteCurrency {
+		return nil, ErrInvalidCurrency
+	}
+
+	if !rate.IsPositive() {
+		return nil, models.ErrInvalidRate
+	}
+
+	exchangeRate := &models.ExchangeRate{
+		ID:            uuid.New(),
+		BaseCurrency:  baseCurrency,
+		QuoteCurrency: quoteCurrency,
+		Rate:          rate,
+		RateDate:      rateDate,
+		CreatedAt:     time.Now().In(utils.LOC),
+		UpdatedAt:     time.Now().In(utils.LOC),
+	}
+
+	if err := repository.UpsertExchangeRate(exchangeRate, db); err != nil {
+		return nil, err
+	}
+
+	return exchangeRate, nil
+}
+
+// ImportExchangeRates reads a CSV with the header `date,base,quote,rate` and upserts every row
+// in a single database transaction. Any invalid row aborts the whole import.
+func ImportExchangeRates(reader io.Reader, db *sql.DB) (int, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidRatesFile, err)
+	}
+
+	if len(records) < 2 {
+		return 0, fmt.Errorf("%w: the file must contain a header and at least one rate", ErrInvalidRatesFile)
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range []string{"date", "base", "quote", "rate"} {
+		if _, ok := columns[name]; !ok {
+			return 0, fmt.Errorf("%w: missing %q column", ErrInvalidRatesFile, name)
+		}
+	}
+
+	now := time.Now().In(utils.LOC)
+	rates := make([]*models.ExchangeRate, 0, len(records)-1)
+
+	for i, record := range records[1:] {
+		line := i + 2
+
+		rateDate, err := time.Parse("2006-01-02", strings.TrimSpace(record[columns["date"]]))
+		if err != nil {
+			return 0, fmt.Errorf("%w: line %d: invalid date: %s", ErrInvalidRatesFile, line, err)
+		}
+
+		baseCurrency := models.NormalizeCurrency(record[columns["base"]])
+		quoteCurrency := models.NormalizeCurrency(record[columns["quote"]])
+		if !baseCurrency.IsValid() || !quoteCurrency.IsValid() || baseCurrency == quoteCurrency {
+			return 0, fmt.Errorf("line %d: %w", line, ErrInvalidCurrency)
+		}
+
+		rate, err := models.ParseRate(record[columns["rate"]])
+		if err != nil || !rate.IsPositive() {
+			return 0, fmt.Errorf("line %d: %w", line, models.ErrInvalidRate)
+		}
+
+		rates = append(rates, &models.ExchangeRate{
+			ID:            uuid.New(),
+			BaseCurrency:  baseCurrency,
+			QuoteCurrency: quoteCurrency,
+			Rate:          rate,
+			RateDate:      rateDate,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+	}
+
+	err = utils.DBTransaction(db, func(tx *sql.Tx) error {
+		for _, rate := range rates {
+			if err := repository.UpsertExchangeRate(rate, tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(rates), nil
+}
+
+func GetExchangeRates(baseCurrency string, quoteCurrency string, page int, limit int, db *sql.DB) ([]models.ExchangeRate, error) {
+	return repository.GetExchangeRates(string(models.NormalizeCurrency(baseCurrency)), string(models.NormalizeCurrency(quoteCurrency)), page, limit, db)
+}
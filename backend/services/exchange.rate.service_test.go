@@ -0,0 +1,45 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+func TestMain(m *testing.M) {
+	utils.LoadTimezone("UTC")
+	os.Exit(m.Run())
+}
+
+func TestImportExchangeRatesInvalidFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{"empty", "", ErrInvalidRatesFile},
+		{"header only", "date,base,quote,rate\n", ErrInvalidRatesFile},
+		{"missing column", "date,base,rate\n2025-01-01,USD,83\n", ErrInvalidRatesFile},
+		{"unbalanced quotes", "date,base,quote,rate\n\"2025-01-01,USD,INR,83\n", ErrInvalidRatesFile},
+		{"wrong field count", "date,base,quote,rate\n2025-01-01,USD,INR\n", ErrInvalidRatesFile},
+		{"invalid date", "date,base,quote,rate\n01/02/2025,USD,INR,83\n", ErrInvalidRatesFile},
+		{"invalid currency", "date,base,quote,rate\n2025-01-01,USD,RUPEE,83\n", ErrInvalidCurrency},
+		{"same currency", "date,base,quote,rate\n2025-01-01,USD,usd,1\n", ErrInvalidCurrency},
+		{"invalid rate", "date,base,quote,rate\n2025-01-01,USD,INR,abc\n", models.ErrInvalidRate},
+		{"zero rate", "date,base,quote,rate\n2025-01-01,USD,INR,0\n", models.ErrInvalidRate},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Invalid files are rejected before the database is used.
+			imported, err := ImportExchangeRates(strings.NewReader(test.input), nil)
+			if !errors.Is(err, test.want) || imported != 0 {
+				t.Fatalf("ImportExchangeRates() = %d, %v, want %v", imported, err, test.want)
+			}
+		})
+	}
+}
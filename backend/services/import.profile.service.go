@@ -0,0 +1,218 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+var (
+	ErrInvalidImportProfile = errors.New("invalid import profile")
+	ErrImportProfileExists  = errors.New("an import profile with this name already exists")
+)
+
+const (
+	maxImportProfileName = 100
+	maxImportSkipRows    = 100
+)
+
+func invalidImportProfile(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidImportProfile, reason)
+}
+
+// validateImportMapping fills in the defaults of an import mapping and checks it. The default
+// categories have to be visible to the user and of the matching type.
+func validateImportMapping(mapping *models.ImportMapping, userID uuid.UUID, db *sql.DB) error {
+	if mapping.Delimiter == "" {
+		mapping.Delimiter = ","
+	}
+	if mapping.DateFormat == "" {
+		mapping.DateFormat = models.DateFormatISO
+	}
+	if mapping.AmountSign == "" {
+		mapping.AmountSign = models.AmountSignExpenseNegative
+	}
+	if mapping.DecimalSeparator == "" {
+		mapping.DecimalSeparator = "."
+	}
+
+	for _, column := range []*string{&mapping.DateColumn, &mapping.DescriptionColumn, &mapping.AmountColumn, &mapping.DebitColumn, &mapping.CreditColumn, &mapping.NoteColumn, &mapping.CategoryColumn} {
+		*column = strings.TrimSpace(*column)
+		if len(*column) > 100 {
+			return invalidImportProfile("column names must be at most 100 characters")
+		}
+	}
+
+	delimiter, _ := utf8.DecodeRuneInString(mapping.Delimiter)
+	if utf8.RuneCountInString(mapping.Delimiter) != 1 || delimiter == '"' || delimiter == '\r' || delimiter == '\n' || delimiter == utf8.RuneError {
+		return invalidImportProfile("delimiter must be a single character other than a quote or line break")
+	}
+
+	if mapping.SkipRows < 0 || mapping.SkipRows > maxImportSkipRows {
+		return invalidImportProfile(fmt.Sprintf("skipRows must be between 0 and %d", maxImportSkipRows))
+	}
+
+	if mapping.DateColumn == "" || mapping.DescriptionColumn == "" {
+		return invalidImportProfile("dateColumn and descriptionColumn are required")
+	}
+
+	if !mapping.DateFormat.IsValid() {
+		return invalidImportProfile("unsupported date format")
+	}
+
+	switch {
+	case mapping.AmountColumn != "" && (mapping.DebitColumn != "" || mapping.CreditColumn != ""):
+		return invalidImportProfile("use either amountColumn or debitColumn and creditColumn")
+	case mapping.AmountColumn == "" && (mapping.DebitColumn == "" || mapping.CreditColumn == ""):
+		return invalidImportProfile("amountColumn or both debitColumn and creditColumn are required")
+	}
+
+	if !mapping.AmountSign.IsValid() {
+		return invalidImportProfile("amountSign must be expense_negative or expense_positive")
+	}
+
+	if mapping.DecimalSeparator != "." && mapping.DecimalSeparator != "," {
+		return invalidImportProfile("decimalSeparator must be . or ,")
+	}
+
+	defaults := []struct {
+		id              uuid.NullUUID
+		transactionType models.TransactionType
+	}{
+		{mapping.IncomeCategoryID, models.TransactionTypeIncome},
+		{mapping.ExpenseCategoryID, models.TransactionTypeExpense},
+	}
+
+	for _, category := range defaults {
+		if !category.id.Valid {
+			continue
+		}
+
+		found, err := repository.GetCategoryByID(category.id.UUID, userID, db)
+		if err != nil {
+			return err
+		}
+		if found == nil {
+			return sql.ErrNoRows
+		}
+		if found.Type != category.transactionType {
+			return invalidImportProfile(fmt.Sprintf("the default %s category must be an %s category", category.transactionType, category.transactionType))
+		}
+	}
+
+	return nil
+}
+
+// checkImportProfileName trims the name and makes sure no other profile of the user has it.
+func checkImportProfileName(name string, id uuid.UUID, userID uuid.UUID, db *sql.DB) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxImportProfileName {
+		return "", invalidImportProfile(fmt.Sprintf("name is required and must be at most %d characters", maxImportProfileName))
+	}
+
+	existing, err := repository.GetImportProfileByName(name, userID, db)
+	if err != nil {
+		return "", err
+	}
+
+	if existing != nil && existing.ID != id {
+		return "", ErrImportProfileExists
+	}
+
+	return name, nil
+}
+
+func CreateImportProfile(userID uuid.UUID, name string, mapping models.ImportMapping, db *sql.DB) (*models.ImportProfile, error) {
+	name, err := checkImportProfileName(name, uuid.Nil, userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateImportMapping(&mapping, userID, db); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(utils.LOC)
+	profile := &models.ImportProfile{
+		ID:            uuid.New(),
+		UserID:        userID,
+		Name:          name,
+		ImportMapping: mapping,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if err := repository.CreateImportProfile(profile, db); err != nil {
+		return nil, err
+	}
+
+	// Log the creation
+	go CreateLog(userID, fmt.Sprintf("New import profile '%s' created", profile.Name), db)
+
+	return profile, nil
+}
+
+func GetImportProfiles(userID uuid.UUID, db *sql.DB) ([]models.ImportProfile, error) {
+	return repository.GetImportProfilesByUserID(userID, db)
+}
+
+func UpdateImportProfile(id uuid.UUID, userID uuid.UUID, name string, mapping models.ImportMapping, db *sql.DB) (*models.ImportProfile, error) {
+	profile, err := repository.GetImportProfileByID(id, userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	name, err = checkImportProfileName(name, id, userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateImportMapping(&mapping, userID, db); err != nil {
+		return nil, err
+	}
+
+	profile.Name = name
+	profile.ImportMapping = mapping
+	profile.UpdatedAt = time.Now().In(utils.LOC)
+
+	if err := repository.UpdateImportProfile(profile, db); err != nil {
+		return nil, err
+	}
+
+	// Log the update
+	go CreateLog(userID, fmt.Sprintf("Import profile '%s' updated", profile.Name), db)
+
+	return profile, nil
+}
+
+func DeleteImportProfile(id uuid.UUID, userID uuid.UUID, db *sql.DB) error {
+	profile, err := repository.GetImportProfileByID(id, userID, db)
+	if err != nil {
+		return err
+	}
+
+	if profile == nil {
+		return sql.ErrNoRows
+	}
+
+	if err := repository.DeleteImportProfile(id, userID, db); err != nil {
+		return err
+	}
+
+	// Log the deletion
+	go CreateLog(userID, fmt.Sprintf("Import profile '%s' deleted", profile.Name), db)
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+)
+
+var ErrInvalidTimeSeries = errors.New("invalid time series")
+
+const (
+	// defaultTimeSeriesPeriods is how many periods up to the end date are shown without a start date.
+	defaultTimeSeriesPeriods = 12
+	maxTimeSeriesPeriods     = 1000
+)
+
+func invalidTimeSeries(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidTimeSeries, reason)
+}
+
+// GetIncomeVsExpense buckets the user's income, expense and net into days, weeks, months or years
+// between the two dates, which are read in the user's time zone. The end date defaults to today
+// and the start date to twelve periods back. Weeks begin on the user's first day of the week,
+// periods without transactions are zero, and the first and last period can reach beyond the
+// range. Amounts are in the user's base currency. With a groupBy the totals are also split into
+// one series per account or category.
+func GetIncomeVsExpense(userID uuid.UUID, interval models.TimeSeriesInterval, startDate string, endDate string, groupBy models.TimeSeriesGroupBy, db *sql.DB) (*models.IncomeExpenseTimeSeries, error) {
+	if interval == "" {
+		interval = models.TimeSeriesMonth
+	}
+
+	if !interval.IsValid() {
+		return nil, invalidTimeSeries("interval must be day, week, month or year")
+	}
+
+	if groupBy != "" && !groupBy.IsValid() {
+		return nil, invalidTimeSeries("groupBy must be account or category")
+	}
+
+	preferences, err := GetUserPreferences(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := preferences.Location()
+	weekStart := time.Weekday(preferences.WeekStart)
+
+	end := todayIn(loc)
+	if endDate != "" {
+		if end, err = parseDateFilter(endDate, loc); err != nil {
+			return nil, err
+		}
+	}
+	end = dateOnly(end)
+
+	start := interval.AddPeriods(interval.PeriodStart(end, weekStart), 1-defaultTimeSeriesPeriods)
+	if startDate != "" {
+		if start, err = parseDateFilter(startDate, loc); err != nil {
+			return nil, err
+		}
+	}
+	start = dateOnly(start)
+
+	if start.After(end) {
+		return nil, invalidTimeSeries("startDate must not be after endDate")
+	}
+
+	var periods []time.Time
+	for period := interval.PeriodStart(start, weekStart); !period.After(end); period = interval.AddPeriods(period, 1) {
+		if len(periods) == maxTimeSeriesPeriods {
+			return nil, invalidTimeSeries(fmt.Sprintf("the range holds more than %d periods, use a longer interval", maxTimeSeriesPeriods))
+		}
+		periods = append(periods, period)
+	}
+
+	if err := EnsureExchangeRates(userID, preferences.BaseCurrency, db); err != nil {
+		return nil, err
+	}
+
+	totals, err := repository.GetDailyIncomeAndExpenses(userID, preferences.BaseCurrency, start.Format("2006-01-02"), end.Format("2006-01-02"), groupBy, db)
+	if err != nil {
+		return nil, err
+	}
+
+	periodIndex := make(map[time.Time]int, len(periods))
+	for i, period := range periods {
+		periodIndex[period] = i
+	}
+
+	series := &models.IncomeExpenseTimeSeries{
+		Currency:  preferences.BaseCurrency,
+		Interval:  interval,
+		GroupBy:   groupBy,
+		StartDate: start,
+		EndDate:   end,
+		Points:    emptyIncomeExpensePoints(periods, interval),
+	}
+
+	groups := map[uuid.NullUUID]*models.IncomeExpenseSeries{}
+	for _, total := range totals {
+		i, ok := periodIndex[interval.PeriodStart(dateOnly(total.Date), weekStart)]
+		if !ok {
+			continue
+		}
+
+		if err := addIncomeExpense(&series.Points[i], total); err != nil {
+			return nil, err
+		}
+
+		if groupBy == "" {
+			continue
+		}
+
+		group, ok := groups[total.GroupID]
+		if !ok {
+			group = &models.IncomeExpenseSeries{
+				ID:     total.GroupID,
+				Name:   total.GroupName,
+				Points: emptyIncomeExpensePoints(periods, interval),
+			}
+			groups[total.GroupID] = group
+		}
+		if err := addIncomeExpense(&group.Points[i], total); err != nil {
+			return nil, err
+		}
+	}
+
+	if groupBy != "" {
+		series.Series = make([]models.IncomeExpenseSeries, 0, len(groups))
+		for _, group := range groups {
+			series.Series = append(series.Series, *group)
+		}
+		sort.Slice(series.Series, func(i, j int) bool {
+			return series.Series[i].Name < series.Series[j].Name
+		})
+	}
+
+	return series, nil
+}
+
+func emptyIncomeExpensePoints(periods []time.Time, interval models.TimeSeriesInterval) []models.IncomeExpensePoint {
+	points := make([]models.IncomeExpensePoint, len(periods))
+	for i, period := range periods {
+		points[i] = models.IncomeExpensePoint{
+			PeriodStart: period,
+			PeriodEnd:   interval.AddPeriods(period, 1).AddDate(0, 0, -1),
+		}
+	}
+	return points
+}
+
+// addIncomeExpense adds a day's totals to the point. Totals beyond models.MaxMoney fail with
+// models.ErrMoneyOverflow instead of wrapping around.
+func addIncomeExpense(point *models.IncomeExpensePoint, total models.DailyIncomeExpense) error {
+	income, err := point.Income.AddChecked(total.Income)
+	if err != nil {
+		return err
+	}
+	expense, err := point.Expense.AddChecked(total.Expense)
+	if err != nil {
+		return err
+	}
+	net, err := income.SubChecked(expense)
+	if err != nil {
+		return err
+	}
+
+	point.Income, point.Expense, point.Net = income, expense, net
+	return nil
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+)
+
+func TestAddIncomeExpense(t *testing.T) {
+	tests := []struct {
+		name  string
+		point models.IncomeExpensePoint
+		total models.DailyIncomeExpense
+		want  models.IncomeExpensePoint
+		err   error
+	}{
+		{
+			name:  "adds to the totals",
+			point: models.IncomeExpensePoint{Income: 10000, Expense: 4000, Net: 6000},
+			total: models.DailyIncomeExpense{Income: 5000, Expense: 20000},
+			want:  models.IncomeExpensePoint{Income: 15000, Expense: 24000, Net: -9000},
+		},
+		{
+			name:  "income beyond the maximum",
+			point: models.IncomeExpensePoint{Income: models.MaxMoney},
+			total: models.DailyIncomeExpense{Income: 1},
+			err:   models.ErrMoneyOverflow,
+		},
+		{
+			name:  "expense beyond the maximum",
+			point: models.IncomeExpensePoint{Expense: models.MaxMoney - 1},
+			total: models.DailyIncomeExpense{Expense: 2},
+			err:   models.ErrMoneyOverflow,
+		},
+		{
+			name:  "income and expense at the maximum",
+			point: models.IncomeExpensePoint{},
+			total: models.DailyIncomeExpense{Income: models.MaxMoney, Expense: models.MaxMoney},
+			want:  models.IncomeExpensePoint{Income: models.MaxMoney, Expense: models.MaxMoney},
+		},
+		{
+			name:  "net below the minimum",
+			point: models.IncomeExpensePoint{Expense: models.MaxMoney},
+			total: models.DailyIncomeExpense{Income: -1},
+			err:   models.ErrMoneyOverflow,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			point := test.point
+			err := addIncomeExpense(&point, test.total)
+			if !errors.Is(err, test.err) || (test.err == nil && err != nil) {
+				t.Fatalf("addIncomeExpense() error = %v, want %v", err, test.err)
+			}
+			if test.err != nil {
+				if point != test.point {
+					t.Fatalf("point changed to %+v on error", point)
+				}
+				return
+			}
+			if point != test.want {
+				t.Fatalf("point = %+v, want %+v", point, test.want)
+			}
+		})
+	}
+}
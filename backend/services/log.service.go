@@ -20,6 +20,29 @@ func CreateLog(userID uuid.UUID, message string, db *sql.DB) error {
 	return repository.CreateLog(log, db)
 }
 
+// GetLogs returns a page of the user's logs between two dates, both inclusive, where a day runs
+// from midnight to midnight in the user's time zone. The range defaults to the last month.
 func GetLogs(userID uuid.UUID, startDate string, endDate string, page int, limit int, db *sql.DB) ([]models.Log, error) {
-	return repository.GetLogsByUserID(userID, startDate, endDate, page, limit, db)
+	loc := userLocation(userID, db)
+
+	end := todayIn(loc)
+	if endDate != "" {
+		var err error
+		if end, err = parseDateFilter(endDate, loc); err != nil {
+			return nil, err
+		}
+	}
+
+	start := end.AddDate(0, -1, 0)
+	if startDate != "" {
+		var err error
+		if start, err = parseDateFilter(startDate, loc); err != nil {
+			return nil, err
+		}
+	}
+
+	from := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	to := time.Date(end.Year(), end.Month(), end.Day()+1, 0, 0, 0, 0, loc)
+
+	return repository.GetLogsByUserID(userID, from, to, page, limit, db)
 }
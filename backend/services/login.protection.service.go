@@ -0,0 +1,152 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+// TooManyAttemptsError is returned while an email address or IP is held back after failed logins.
+type TooManyAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *TooManyAttemptsError) Error() string {
+	return "too many failed login attempts, try again later"
+}
+
+// loginAttemptKeys lists the counters a login from an email and IP is checked against.
+func loginAttemptKeys(email string, ipAddress string) map[models.LoginAttemptScope]string {
+	return map[models.LoginAttemptScope]string{
+		models.LoginAttemptScopeEmail: strings.ToLower(strings.TrimSpace(email)),
+		models.LoginAttemptScopeIP:    ipAddress,
+	}
+}
+
+// maxLoginFailures returns how many failures lock out a key of the scope.
+func maxLoginFailures(scope models.LoginAttemptScope, cfg *config.Config) int {
+	if scope == models.LoginAttemptScopeIP {
+		return cfg.LoginProtection.MaxIPFailures
+	}
+	return cfg.LoginProtection.MaxEmailFailures
+}
+
+// loginBlockDuration returns how long a key is blocked after its nth failure: nothing for the
+// first few failures, then a delay that doubles with every failure, and the full lockout once
+// the scope's limit is reached.
+func loginBlockDuration(scope models.LoginAttemptScope, failures int, cfg *config.Config) time.Duration {
+	limits := cfg.LoginProtection
+
+	if failures >= maxLoginFailures(scope, cfg) {
+		return limits.LockoutDuration
+	}
+
+	if failures < limits.BackoffAfter {
+		return 0
+	}
+
+	delay := limits.BackoffBase
+	for i := limits.BackoffAfter; i < failures && delay < limits.LockoutDuration; i++ {
+		delay *= 2
+	}
+
+	return min(delay, limits.LockoutDuration)
+}
+
+// checkLoginAllowed fails with a TooManyAttemptsError while the email or IP is blocked.
+func checkLoginAllowed(email string, ipAddress string, db *sql.DB) error {
+	now := time.Now().In(utils.LOC)
+	var retryAfter time.Duration
+
+	for scope, key := range loginAttemptKeys(email, ipAddress) {
+		attempt, err := repository.GetLoginAttempt(scope, key, db)
+		if err != nil {
+			return err
+		}
+
+		if attempt != nil && attempt.BlockedUntil != nil && attempt.BlockedUntil.After(now) {
+			retryAfter = max(retryAfter, attempt.BlockedUntil.Sub(now))
+		}
+	}
+
+	if retryAfter > 0 {
+		return &TooManyAttemptsError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+// recordLoginFailure counts a failed login against the email and the IP and blocks them once
+// they cross the backoff or lockout thresholds. Locking an account is written to its security log.
+func recordLoginFailure(email string, ipAddress string, db *sql.DB, cfg *config.Config) {
+	now := time.Now().In(utils.LOC)
+
+	for scope, key := range loginAttemptKeys(email, ipAddress) {
+		failures, err := repository.RecordLoginFailure(scope, key, now, now.Add(-cfg.LoginProtection.FailureWindow), db)
+		if err != nil {
+			log.Printf("Error recording failed login for %s %s: %v", scope, key, err)
+			continue
+		}
+
+		block := loginBlockDuration(scope, failures, cfg)
+		if block == 0 {
+			continue
+		}
+
+		if err := repository.SetLoginAttemptBlockedUntil(scope, key, now.Add(block), db); err != nil {
+			log.Printf("Error blocking %s %s: %v", scope, key, err)
+			continue
+		}
+
+		if failures == maxLoginFailures(scope, cfg) {
+			logLockout(scope, key, email, failures, block, db)
+		}
+	}
+}
+
+// logLockout records a lockout in the application log and, for accounts, in the user's log.
+func logLockout(scope models.LoginAttemptScope, key string, email string, failures int, duration time.Duration, db *sql.DB) {
+	log.Printf("Locked %s %s for %s after %d failed login attempts", scope, key, duration, failures)
+
+	if scope != models.LoginAttemptScopeEmail {
+		return
+	}
+
+	user, err := repository.GetUserByEmail(email, db)
+	if errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		log.Println("Error getting locked user:", err)
+		return
+	}
+
+	CreateLog(user.ID, fmt.Sprintf("Account locked for %s after %d failed login attempts", duration, failures), db)
+}
+
+// clearLoginFailures lifts the backoff and lockout of an email address after a successful login
+// or password reset. IP counters are left to expire so that one good login cannot reset them.
+func clearLoginFailures(email string, db *sql.DB) {
+	if err := repository.ClearLoginAttempts(models.LoginAttemptScopeEmail, strings.ToLower(strings.TrimSpace(email)), db); err != nil {
+		log.Println("Error clearing failed logins:", err)
+	}
+}
+
+// CleanupLoginAttempts deletes counters that have not seen a failure for a day.
+func CleanupLoginAttempts(db *sql.DB) {
+	deleted, err := repository.DeleteStaleLoginAttempts(time.Now().In(utils.LOC).AddDate(0, 0, -1), db)
+	if err != nil {
+		log.Println("Error deleting stale login attempts:", err)
+		return
+	}
+
+	log.Printf("Deleted %d stale login attempts", deleted)
+}
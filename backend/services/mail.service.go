@@ -0,0 +1,44 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/pkg/mailer"
+)
+
+// newMailer returns the mailer selected by MAIL_DRIVER. The driver is checked when the
+// configuration is loaded, so an unknown one only gets here with a hand-built config.
+func newMailer(cfg *config.Config) (mailer.Mailer, error) {
+	switch cfg.Mail.Driver {
+	case "smtp":
+		return &mailer.SMTPMailer{
+			Host:     cfg.Mail.SMTPHost,
+			Port:     cfg.Mail.SMTPPort,
+			Username: cfg.Mail.SMTPUsername,
+			Password: cfg.Mail.SMTPPassword,
+			From:     cfg.Mail.From,
+			Sender:   cfg.Mail.FromAddress,
+		}, nil
+	case "file":
+		return &mailer.FileMailer{Dir: cfg.Mail.OutboxDir, From: cfg.Mail.From}, nil
+	case "log":
+		return &mailer.LogMailer{From: cfg.Mail.From}, nil
+	}
+	return nil, fmt.Errorf("unknown mail driver %q", cfg.Mail.Driver)
+}
+
+// sendMail delivers a message in the background. Failures are logged, the request that triggered
+// the email has already succeeded.
+func sendMail(msg mailer.Message, cfg *config.Config) {
+	go func() {
+		m, err := newMailer(cfg)
+		if err == nil {
+			err = m.Send(msg)
+		}
+		if err != nil {
+			log.Printf("Error sending email to %s: %v", msg.To, err)
+		}
+	}()
+}
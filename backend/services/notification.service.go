@@ -0,0 +1,64 @@
+package services
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+func CreateNotification(userID uuid.UUID, notificationType models.NotificationType, title string, message string, referenceID uuid.NullUUID, db interfaces.SqlExecutor) (*models.Notification, error) {
+	notification := &models.Notification{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Type:        notificationType,
+		Title:       title,
+		Message:     message,
+		ReferenceID: referenceID,
+		CreatedAt:   time.Now().In(utils.LOC),
+	}
+
+	if err := repository.CreateNotification(notification, db); err != nil {
+		return nil, err
+	}
+
+	return notification, nil
+}
+
+func GetNotifications(userID uuid.UUID, unreadOnly bool, page int, limit int, db *sql.DB) (map[string]interface{}, error) {
+	notifications, err := repository.GetNotificationsByUserID(userID, unreadOnly, page, limit, db)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := repository.CountUnreadNotifications(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"notifications": notifications,
+		"unreadCount":   unread,
+	}, nil
+}
+
+func MarkNotificationRead(id uuid.UUID, userID uuid.UUID, db *sql.DB) error {
+	found, err := repository.MarkNotificationRead(id, userID, time.Now().In(utils.LOC), db)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func MarkAllNotificationsRead(userID uuid.UUID, db *sql.DB) (int64, error) {
+	return repository.MarkAllNotificationsRead(userID, time.Now().In(utils.LOC), db)
+}
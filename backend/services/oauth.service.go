@@ -0,0 +1,322 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrEmailNotVerified      = errors.New("google account email is not verified")
+	ErrIncompleteProfile     = errors.New("google did not return an account id and email")
+	ErrAccountExists         = errors.New("an account with this email already exists, log in with your password and link Google from your profile")
+	ErrIdentityInUse         = errors.New("this google account is linked to another user")
+	ErrIdentityAlreadyLinked = errors.New("a google account is already linked to this user")
+	ErrLastLoginMethod       = errors.New("google is the only way to log in to this account, set a password before unlinking it")
+)
+
+const (
+	oauthStateTTL       = 10 * time.Minute
+	oauthRequestTimeout = 10 * time.Second
+)
+
+// StartGoogleOAuth stores a single-use state with a fresh PKCE verifier and returns the Google
+// authorization URL to redirect to, together with the binding the browser has to keep until the
+// callback. Linking flows carry the user that started them.
+func StartGoogleOAuth(purpose models.OAuthPurpose, userID uuid.NullUUID, db *sql.DB, cfg *config.Config) (*models.OAuthRedirect, error) {
+	nonce, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	state := &models.OAuthState{
+		Nonce:        nonce,
+		CodeVerifier: oauth2.GenerateVerifier(),
+		Purpose:      purpose,
+		UserID:       userID,
+		ExpiresAt:    time.Now().In(utils.LOC).Add(oauthStateTTL),
+		CreatedAt:    time.Now().In(utils.LOC),
+	}
+
+	if err := repository.CreateOAuthState(state, db); err != nil {
+		return nil, err
+	}
+
+	return &models.OAuthRedirect{
+		URL:       cfg.GoogleOauthConfig.AuthCodeURL(utils.SignOAuthState(nonce, state.ExpiresAt, cfg), oauth2.S256ChallengeOption(state.CodeVerifier)),
+		Binding:   utils.OAuthStateBinding(nonce),
+		ExpiresAt: state.ExpiresAt,
+	}, nil
+}
+
+// ConsumeGoogleOAuthState verifies the state Google sent back and consumes the stored flow it
+// belongs to. The binding has to come from the browser that started the flow, so a login or link
+// URL handed to someone else cannot be finished in their browser.
+func ConsumeGoogleOAuthState(signedState string, binding string, db *sql.DB, cfg *config.Config) (*models.OAuthState, error) {
+	now := time.Now().In(utils.LOC)
+
+	nonce, err := utils.VerifyOAuthState(signedState, now, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if !utils.VerifyOAuthStateBinding(nonce, binding) {
+		return nil, utils.ErrInvalidOAuthState
+	}
+
+	state, err := repository.ConsumeOAuthState(nonce, now, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if state == nil {
+		return nil, utils.ErrInvalidOAuthState
+	}
+
+	return state, nil
+}
+
+// ExchangeGoogleCode redeems an authorization code with the flow's PKCE verifier and fetches the
+// Google profile it grants access to. Only profiles with a verified email are accepted.
+func ExchangeGoogleCode(code string, state *models.OAuthState, cfg *config.Config) (*models.OAuthProfile, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), oauthRequestTimeout)
+	defer cancel()
+
+	token, err := cfg.GoogleOauthConfig.Exchange(ctx, code, oauth2.VerifierOption(state.CodeVerifier))
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := cfg.GoogleOauthConfig.Client(ctx, token).Get(cfg.OAuth.UserInfoURL)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("userinfo request failed with status %d", response.StatusCode)
+	}
+
+	var profile models.OAuthProfile
+	if err := json.NewDecoder(response.Body).Decode(&profile); err != nil {
+		return nil, err
+	}
+
+	if profile.Subject == "" || profile.Email == "" {
+		return nil, ErrIncompleteProfile
+	}
+
+	if !profile.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	return &profile, nil
+}
+
+// hasIdentity reports whether the user already has an identity at the provider.
+func hasIdentity(userID uuid.UUID, provider models.AuthProvider, db *sql.DB) (bool, error) {
+	identities, err := repository.GetUserIdentitiesByUserID(userID, db)
+	if err != nil {
+		return false, err
+	}
+
+	for _, identity := range identities {
+		if identity.Provider == provider {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func newGoogleIdentity(userID uuid.UUID, profile *models.OAuthProfile) *models.UserIdentity {
+	return &models.UserIdentity{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Provider:  models.AuthProviderGoogle,
+		Subject:   profile.Subject,
+		Email:     profile.Email,
+		CreatedAt: time.Now().In(utils.LOC),
+	}
+}
+
+// googleLoginUser resolves the account a Google profile logs in to. Logins are matched by Google
+// account id. An email match is only trusted for accounts that were created through Google sign
+// in and have no identity yet, an email/password account has to link Google explicitly.
+func googleLoginUser(profile *models.OAuthProfile, db *sql.DB) (*models.User, error) {
+	identity, err := repository.GetUserIdentityBySubject(models.AuthProviderGoogle, profile.Subject, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if identity != nil {
+		user, err := repository.GetUserByID(identity.UserID, db)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, sql.ErrNoRows
+		}
+		return user, nil
+	}
+
+	user, err := repository.GetUserByEmail(profile.Email, db)
+	if errors.Is(err, sql.ErrNoRows) {
+		now := time.Now().In(utils.LOC)
+
+		// Google only hands out verified emails, so the new account needs no verification link.
+		user = &models.User{
+			ID:              uuid.New(),
+			Name:            profile.Name,
+			Email:           profile.Email,
+			Provider:        models.AuthProviderGoogle,
+			BaseCurrency:    models.DefaultCurrency,
+			CreatedAt:       now,
+			EmailVerifiedAt: &now,
+		}
+
+		err := utils.DBTransaction(db, func(tx *sql.Tx) error {
+			if err := repository.CreateUser(user, tx); err != nil {
+				return err
+			}
+			return repository.CreateUserIdentity(newGoogleIdentity(user.ID, profile), tx)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Log the registration
+		go CreateLog(user.ID, "User registered with Google", db)
+
+		return user, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Provider != models.AuthProviderGoogle {
+		return nil, ErrAccountExists
+	}
+
+	linked, err := hasIdentity(user.ID, models.AuthProviderGoogle, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if linked {
+		return nil, ErrAccountExists
+	}
+
+	if err := repository.CreateUserIdentity(newGoogleIdentity(user.ID, profile), db); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// GoogleLogin logs in the account linked to a Google profile, registering a new one when neither
+// the Google account nor its email is known. Two-factor authentication applies like it does to
+// password logins.
+func GoogleLogin(profile *models.OAuthProfile, userAgent, ipAddress string, db *sql.DB, cfg *config.Config) (*models.LoginResult, error) {
+	user, err := googleLoginUser(profile, db)
+	if err != nil {
+		return nil, err
+	}
+
+	// Log the login
+	go CreateLog(user.ID, "User logged in with Google", db)
+
+	return startLogin(user, userAgent, ipAddress, db, cfg)
+}
+
+// LinkGoogleIdentity links a Google profile to the user that started the linking flow.
+func LinkGoogleIdentity(userID uuid.UUID, profile *models.OAuthProfile, db *sql.DB) (*models.UserIdentity, error) {
+	existing, err := repository.GetUserIdentityBySubject(models.AuthProviderGoogle, profile.Subject, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		if existing.UserID == userID {
+			return nil, ErrIdentityAlreadyLinked
+		}
+		return nil, ErrIdentityInUse
+	}
+
+	linked, err := hasIdentity(userID, models.AuthProviderGoogle, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if linked {
+		return nil, ErrIdentityAlreadyLinked
+	}
+
+	identity := newGoogleIdentity(userID, profile)
+	if err := repository.CreateUserIdentity(identity, db); err != nil {
+		return nil, err
+	}
+
+	// Log the link
+	go CreateLog(userID, fmt.Sprintf("Google account %s linked", profile.Email), db)
+
+	return identity, nil
+}
+
+func GetUserIdentities(userID uuid.UUID, db *sql.DB) ([]models.UserIdentity, error) {
+	return repository.GetUserIdentitiesByUserID(userID, db)
+}
+
+// UnlinkGoogleIdentity removes the user's Google identity. Accounts without a password would be
+// locked out, so they have to keep it.
+func UnlinkGoogleIdentity(userID uuid.UUID, db *sql.DB) error {
+	user, err := repository.GetUserByID(userID, db)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return sql.ErrNoRows
+	}
+
+	if user.Password == "" {
+		return ErrLastLoginMethod
+	}
+
+	unlinked, err := repository.DeleteUserIdentity(userID, models.AuthProviderGoogle, db)
+	if err != nil {
+		return err
+	}
+
+	if !unlinked {
+		return sql.ErrNoRows
+	}
+
+	// Log the unlink
+	go CreateLog(userID, "Google account unlinked", db)
+
+	return nil
+}
+
+// CleanupOAuthStates deletes the states of OAuth flows that were never completed.
+func CleanupOAuthStates(db *sql.DB) {
+	deleted, err := repository.DeleteExpiredOAuthStates(time.Now().In(utils.LOC), db)
+	if err != nil {
+		log.Println("Error deleting expired OAuth states:", err)
+		return
+	}
+
+	log.Printf("Deleted %d expired OAuth states", deleted)
+}
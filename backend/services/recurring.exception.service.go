@@ -0,0 +1,170 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/pkg/recurrence"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+var (
+	ErrNotScheduledOccurrence     = errors.New("date is not a scheduled occurrence of this recurring transaction")
+	ErrOccurrenceAlreadyProcessed = errors.New("occurrence has already been processed")
+	ErrInvalidRecurringAmount     = errors.New("amount must be greater than zero")
+	ErrExceptionNotFound          = errors.New("occurrence has no skip or override")
+)
+
+// getUpcomingOccurrence loads a rule and checks that date is one of its occurrences that the
+// processor has not handled yet.
+func getUpcomingOccurrence(id uuid.UUID, userID uuid.UUID, date time.Time, db *sql.DB) (*models.RecurringTransaction, time.Time, error) {
+	recurringTransaction, err := repository.GetRecurringTransactionByID(id, userID, db)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	if recurringTransaction == nil {
+		return nil, time.Time{}, sql.ErrNoRows
+	}
+
+	date = recurrence.Date(date)
+	if date.Before(recurringWindowStart(recurringTransaction, userLocation(userID, db))) {
+		return nil, time.Time{}, ErrOccurrenceAlreadyProcessed
+	}
+
+	occurrence, ok := recurringTransaction.Rule().Next(date)
+	if !ok || !occurrence.Date.Equal(date) {
+		return nil, time.Time{}, ErrNotScheduledOccurrence
+	}
+
+	return recurringTransaction, date, nil
+}
+
+// SkipRecurringOccurrence stops the processor from posting a single upcoming occurrence.
+func SkipRecurringOccurrence(id uuid.UUID, userID uuid.UUID, date time.Time, db *sql.DB) (*models.RecurringTransactionException, error) {
+	recurringTransaction, occurrenceDate, err := getUpcomingOccurrence(id, userID, date, db)
+	if err != nil {
+		return nil, err
+	}
+
+	exception := &models.RecurringTransactionException{
+		RecurringTransactionID: id,
+		OccurrenceDate:         occurrenceDate,
+		Skip:                   true,
+		CreatedAt:              time.Now().In(utils.LOC),
+		UpdatedAt:              time.Now().In(utils.LOC),
+	}
+
+	if err := repository.UpsertRecurringTransactionException(exception, db); err != nil {
+		return nil, err
+	}
+
+	// Log the skip
+	go CreateLog(userID, fmt.Sprintf("Recurring transaction '%s' skipped on %s", recurringTransaction.Description, occurrenceDate.Format("2006-01-02")), db)
+
+	return exception, nil
+}
+
+// OverrideRecurringOccurrence posts a single upcoming occurrence with a different amount.
+func OverrideRecurringOccurrence(id uuid.UUID, userID uuid.UUID, date time.Time, amount models.Money, db *sql.DB) (*models.RecurringTransactionException, error) {
+	if !amount.IsPositive() {
+		return nil, ErrInvalidRecurringAmount
+	}
+
+	recurringTransaction, occurrenceDate, err := getUpcomingOccurrence(id, userID, date, db)
+	if err != nil {
+		return nil, err
+	}
+
+	exception := &models.RecurringTransactionException{
+		RecurringTransactionID: id,
+		OccurrenceDate:         occurrenceDate,
+		Amount:                 &amount,
+		CreatedAt:              time.Now().In(utils.LOC),
+		UpdatedAt:              time.Now().In(utils.LOC),
+	}
+
+	if err := repository.UpsertRecurringTransactionException(exception, db); err != nil {
+		return nil, err
+	}
+
+	// Log the override
+	go CreateLog(userID, fmt.Sprintf("Recurring transaction '%s' amount changed for %s", recurringTransaction.Description, occurrenceDate.Format("2006-01-02")), db)
+
+	return exception, nil
+}
+
+// RestoreRecurringOccurrence removes a skip or override so the occurrence is posted as scheduled.
+func RestoreRecurringOccurrence(id uuid.UUID, userID uuid.UUID, date time.Time, db *sql.DB) error {
+	recurringTransaction, occurrenceDate, err := getUpcomingOccurrence(id, userID, date, db)
+	if err != nil {
+		return err
+	}
+
+	found, err := repository.DeleteRecurringTransactionException(id, occurrenceDate, db)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return ErrExceptionNotFound
+	}
+
+	// Log the restore
+	go CreateLog(userID, fmt.Sprintf("Recurring transaction '%s' restored for %s", recurringTransaction.Description, occurrenceDate.Format("2006-01-02")), db)
+
+	return nil
+}
+
+// PreviewRecurringTransaction returns the next count occurrences the processor will handle, with
+// skips and amount overrides applied. Paused rules have no upcoming occurrences.
+func PreviewRecurringTransaction(id uuid.UUID, userID uuid.UUID, count int, db *sql.DB) ([]models.RecurringOccurrencePreview, error) {
+	recurringTransaction, err := repository.GetRecurringTransactionByID(id, userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if recurringTransaction == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	previews := make([]models.RecurringOccurrencePreview, 0, count)
+	if recurringTransaction.IsPaused {
+		return previews, nil
+	}
+
+	occurrences := recurringTransaction.Rule().Upcoming(recurringWindowStart(recurringTransaction, userLocation(userID, db)), count)
+	if len(occurrences) == 0 {
+		return previews, nil
+	}
+
+	exceptions, err := repository.GetRecurringTransactionExceptions(id, occurrences[0].Date, occurrences[len(occurrences)-1].Date, db)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, occurrence := range occurrences {
+		preview := models.RecurringOccurrencePreview{
+			Index:  occurrence.Index,
+			Date:   occurrence.Date,
+			Amount: recurringTransaction.Amount,
+		}
+
+		if exception, ok := exceptions[occurrence.Date.Format("2006-01-02")]; ok {
+			preview.Skipped = exception.Skip
+			if exception.Amount != nil {
+				preview.Amount = *exception.Amount
+				preview.Overridden = true
+			}
+		}
+
+		previews = append(previews, preview)
+	}
+
+	return previews, nil
+}
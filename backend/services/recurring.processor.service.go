@@ -0,0 +1,189 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/pkg/recurrence"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+// errOccurrencePosted rolls back a posting when another run already recorded the occurrence.
+var errOccurrencePosted = errors.New("recurring occurrence already posted")
+
+// recurringWindowStart returns the first day the processor still has to handle for a rule: the
+// day after its last run, or the day it was created in the owner's time zone so that rules never
+// post for earlier dates.
+func recurringWindowStart(recurringTransaction *models.RecurringTransaction, loc *time.Location) time.Time {
+	if recurringTransaction.LastRunAt != nil {
+		return recurrence.Date(*recurringTransaction.LastRunAt).AddDate(0, 0, 1)
+	}
+	return recurrence.Date(recurringTransaction.CreatedAt.In(loc))
+}
+
+// ProcessRecurringTransactions posts every occurrence due up to today in the owner's time zone
+// that has not been posted yet, including ones missed while the server was down. Rules whose
+// owner has not reached the next day yet are left alone, so running it often posts each
+// occurrence shortly after the owner's local midnight. It is safe to run repeatedly or
+// concurrently since each occurrence is claimed in the run ledger.
+func ProcessRecurringTransactions(db *sql.DB) {
+	recurringTransactions, err := repository.GetRecurringTransactions(db)
+	if err != nil {
+		log.Println("Error getting recurring transactions:", err)
+		return
+	}
+
+	locations := make(map[uuid.UUID]*time.Location)
+
+	for i := range recurringTransactions {
+		recurringTransaction := &recurringTransactions[i]
+
+		// Paused rules keep their last run so that resuming decides where posting continues.
+		if recurringTransaction.IsPaused {
+			continue
+		}
+
+		loc, ok := locations[recurringTransaction.UserID]
+		if !ok {
+			loc = userLocation(recurringTransaction.UserID, db)
+			locations[recurringTransaction.UserID] = loc
+		}
+
+		processedAt := todayIn(loc)
+		if recurringWindowStart(recurringTransaction, loc).After(processedAt) {
+			continue
+		}
+
+		posted, err := processRecurringTransaction(recurringTransaction, processedAt, loc, db)
+		if err != nil {
+			log.Printf("Error processing recurring transaction %s: %v", recurringTransaction.ID, err)
+		}
+
+		if posted > 0 {
+			checkBudgetAlertsAsync(recurringTransaction.UserID, db)
+		}
+	}
+}
+
+// processRecurringTransaction posts the rule's due occurrences in order and returns how many were
+// posted. It stops at the first failure so that the failed occurrence is retried on the next run.
+func processRecurringTransaction(recurringTransaction *models.RecurringTransaction, processedAt time.Time, loc *time.Location, db *sql.DB) (int, error) {
+	windowStart := recurringWindowStart(recurringTransaction, loc)
+
+	exceptions, err := repository.GetRecurringTransactionExceptions(recurringTransaction.ID, windowStart, processedAt, db)
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+
+	for _, occurrence := range recurringTransaction.Rule().Between(windowStart, processedAt) {
+		var exception *models.RecurringTransactionException
+		if found, ok := exceptions[occurrence.Date.Format("2006-01-02")]; ok {
+			exception = &found
+		}
+
+		err := postRecurringOccurrence(recurringTransaction, occurrence, exception, db)
+		if errors.Is(err, errOccurrencePosted) {
+			continue
+		}
+		if err != nil {
+			return posted, err
+		}
+		if exception == nil || !exception.Skip {
+			posted++
+		}
+	}
+
+	return posted, repository.UpdateRecurringTransactionLastRun(recurringTransaction.ID, processedAt, db)
+}
+
+// skipRecurringOccurrence records a skipped occurrence in the ledger without posting a transaction.
+func skipRecurringOccurrence(recurringTransaction *models.RecurringTransaction, occurrence recurrence.Occurrence, db *sql.DB) error {
+	run := &models.RecurringTransactionRun{
+		RecurringTransactionID: recurringTransaction.ID,
+		OccurrenceDate:         occurrence.Date,
+		OccurrenceIndex:        occurrence.Index,
+		CreatedAt:              time.Now().In(utils.LOC),
+	}
+
+	return utils.DBTransaction(db, func(tx *sql.Tx) error {
+		claimed, err := repository.CreateRecurringTransactionRun(run, tx)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return errOccurrencePosted
+		}
+
+		return repository.UpdateRecurringTransactionLastRun(recurringTransaction.ID, occurrence.Date, tx)
+	})
+}
+
+// postRecurringOccurrence creates the transaction for one occurrence, updates the account balance
+// and records the occurrence in the ledger in a single database transaction. An exception for the
+// occurrence either skips it or replaces its amount.
+func postRecurringOccurrence(recurringTransaction *models.RecurringTransaction, occurrence recurrence.Occurrence, exception *models.RecurringTransactionException, db *sql.DB) error {
+	if exception != nil && exception.Skip {
+		return skipRecurringOccurrence(recurringTransaction, occurrence, db)
+	}
+
+	amount := recurringTransaction.Amount
+	if exception != nil && exception.Amount != nil {
+		amount = *exception.Amount
+	}
+
+	account, err := repository.GetAccountByID(recurringTransaction.AccountID, recurringTransaction.UserID, db)
+	if err != nil {
+		return err
+	}
+
+	if account == nil {
+		return sql.ErrNoRows
+	}
+
+	transaction := &models.Transaction{
+		ID:              uuid.New(),
+		UserID:          recurringTransaction.UserID,
+		AccountID:       recurringTransaction.AccountID,
+		CategoryID:      uuid.NullUUID{UUID: recurringTransaction.CategoryID, Valid: true},
+		BudgetID:        recurringTransaction.BudgetID,
+		Description:     recurringTransaction.Description,
+		Amount:          amount,
+		Currency:        account.Currency,
+		Type:            recurringTransaction.Type,
+		Note:            recurringTransaction.Note,
+		TransactionDate: occurrence.Date,
+		CreatedAt:       time.Now().In(utils.LOC),
+		UpdatedAt:       time.Now().In(utils.LOC),
+	}
+
+	run := &models.RecurringTransactionRun{
+		RecurringTransactionID: recurringTransaction.ID,
+		OccurrenceDate:         occurrence.Date,
+		OccurrenceIndex:        occurrence.Index,
+		TransactionID:          uuid.NullUUID{UUID: transaction.ID, Valid: true},
+		CreatedAt:              transaction.CreatedAt,
+	}
+
+	return utils.DBTransaction(db, func(tx *sql.Tx) error {
+		if err := postTransaction(transaction, tx); err != nil {
+			return err
+		}
+
+		claimed, err := repository.CreateRecurringTransactionRun(run, tx)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return errOccurrencePosted
+		}
+
+		return repository.UpdateRecurringTransactionLastRun(recurringTransaction.ID, occurrence.Date, tx)
+	})
+}
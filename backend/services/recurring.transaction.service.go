@@ -7,12 +7,61 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/pkg/recurrence"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
 )
 
-func CreateRecurringTransaction(userID uuid.UUID, accountID uuid.UUID, categoryID uuid.UUID, budgetID uuid.NullUUID, description string, amount float64, note sql.NullString, recurringFrequency models.RecurringFrequency, recurringDate int, db *sql.DB) (*models.RecurringTransaction, error) {
-	category, err := repository.GetCategoryByID(categoryID, db)
+// setRecurrenceRule validates the rule and stores it on the recurring transaction. Weekday and
+// day of month only apply to the frequencies that use them and are cleared otherwise.
+func setRecurrenceRule(recurringTransaction *models.RecurringTransaction, rule recurrence.Rule, loc *time.Location) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	recurringTransaction.RecurringFrequency = models.RecurringFrequency(rule.Frequency)
+	recurringTransaction.Interval = rule.Interval
+	recurringTransaction.StartDate = recurrence.Date(rule.StartDate)
+	recurringTransaction.MaxOccurrences = rule.MaxOccurrences
+	recurringTransaction.Weekday = nil
+	recurringTransaction.RecurringDate = 0
+	recurringTransaction.LastDayOfMonth = false
+	recurringTransaction.EndDate = nil
+
+	if rule.EndDate != nil {
+		end := recurrence.Date(*rule.EndDate)
+		recurringTransaction.EndDate = &end
+	}
+
+	switch rule.Frequency {
+	case recurrence.Weekly, recurrence.Biweekly:
+		if rule.Weekday != nil {
+			weekday := int(*rule.Weekday)
+			recurringTransaction.Weekday = &weekday
+		}
+	case recurrence.Monthly, recurrence.Quarterly, recurrence.Yearly:
+		recurringTransaction.RecurringDate = rule.DayOfMonth
+		recurringTransaction.LastDayOfMonth = rule.LastDayOfMonth
+	}
+
+	setNextRunAt(recurringTransaction, loc)
+
+	return nil
+}
+
+// setNextRunAt fills in the next date the processor will create a transaction for.
+func setNextRunAt(recurringTransaction *models.RecurringTransaction, loc *time.Location) {
+	recurringTransaction.NextRunAt = nil
+	if recurringTransaction.IsPaused {
+		return
+	}
+	if next, ok := recurringTransaction.Rule().Next(recurringWindowStart(recurringTransaction, loc)); ok {
+		recurringTransaction.NextRunAt = &next.Date
+	}
+}
+
+func CreateRecurringTransaction(userID uuid.UUID, accountID uuid.UUID, categoryID uuid.UUID, budgetID uuid.NullUUID, description string, amount models.Money, note sql.NullString, rule recurrence.Rule, db *sql.DB) (*models.RecurringTransaction, error) {
+	category, err := repository.GetCategoryByID(categoryID, userID, db)
 	if err != nil {
 		return nil, err
 	}
@@ -24,7 +73,7 @@ func CreateRecurringTransaction(userID uuid.UUID, accountID uuid.UUID, categoryI
 	transactionType := models.TransactionType(category.Type)
 
 	// Update account balance
-	account, err := repository.GetAccountByID(accountID, db)
+	account, err := repository.GetAccountByID(accountID, userID, db)
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +84,7 @@ func CreateRecurringTransaction(userID uuid.UUID, accountID uuid.UUID, categoryI
 
 	// Update budget if provided
 	if budgetID.Valid {
-		budget, err := repository.GetBudgetByID(budgetID.UUID, db)
+		budget, err := repository.GetBudgetByID(budgetID.UUID, userID, db)
 		if err != nil {
 			return nil, err
 		}
@@ -46,19 +95,21 @@ func CreateRecurringTransaction(userID uuid.UUID, accountID uuid.UUID, categoryI
 	}
 
 	recurringTransaction := &models.RecurringTransaction{
-		ID:                 uuid.New(),
-		UserID:             userID,
-		AccountID:          accountID,
-		CategoryID:         categoryID,
-		BudgetID:           budgetID,
-		Description:        description,
-		Amount:             amount,
-		Type:               transactionType,
-		Note:               note,
-		RecurringFrequency: recurringFrequency,
-		RecurringDate:      recurringDate,
-		CreatedAt:          time.Now().In(utils.LOC),
-		UpdatedAt:          time.Now().In(utils.LOC),
+		ID:          uuid.New(),
+		UserID:      userID,
+		AccountID:   accountID,
+		CategoryID:  categoryID,
+		BudgetID:    budgetID,
+		Description: description,
+		Amount:      amount,
+		Type:        transactionType,
+		Note:        note,
+		CreatedAt:   time.Now().In(utils.LOC),
+		UpdatedAt:   time.Now().In(utils.LOC),
+	}
+
+	if err := setRecurrenceRule(recurringTransaction, rule, userLocation(userID, db)); err != nil {
+		return nil, err
 	}
 
 	if err := repository.CreateRecurringTransaction(recurringTransaction, db); err != nil {
@@ -72,11 +123,21 @@ func CreateRecurringTransaction(userID uuid.UUID, accountID uuid.UUID, categoryI
 }
 
 func GetRecurringTransactions(userID uuid.UUID, db *sql.DB) ([]models.RecurringTransaction, error) {
-	return repository.GetRecurringTransactionsByUserID(userID, db)
+	recurringTransactions, err := repository.GetRecurringTransactionsByUserID(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := userLocation(userID, db)
+	for i := range recurringTransactions {
+		setNextRunAt(&recurringTransactions[i], loc)
+	}
+
+	return recurringTransactions, nil
 }
 
-func UpdateRecurringTransaction(id uuid.UUID, accountID uuid.UUID, categoryID uuid.UUID, budgetID uuid.NullUUID, description string, amount float64, note sql.NullString, recurringFrequency models.RecurringFrequency, recurringDate int, db *sql.DB) (*models.RecurringTransaction, error) {
-	recurringTransaction, err := repository.GetRecurringTransactionByID(id, db)
+func UpdateRecurringTransaction(id uuid.UUID, userID uuid.UUID, accountID uuid.UUID, categoryID uuid.UUID, budgetID uuid.NullUUID, description string, amount models.Money, note sql.NullString, rule recurrence.Rule, db *sql.DB) (*models.RecurringTransaction, error) {
+	recurringTransaction, err := repository.GetRecurringTransactionByID(id, userID, db)
 	if err != nil {
 		return nil, err
 	}
@@ -85,7 +146,7 @@ func UpdateRecurringTransaction(id uuid.UUID, accountID uuid.UUID, categoryID uu
 		return nil, sql.ErrNoRows
 	}
 
-	category, err := repository.GetCategoryByID(categoryID, db)
+	category, err := repository.GetCategoryByID(categoryID, userID, db)
 	if err != nil {
 		return nil, err
 	}
@@ -96,7 +157,7 @@ func UpdateRecurringTransaction(id uuid.UUID, accountID uuid.UUID, categoryID uu
 
 	transactionType := models.TransactionType(category.Type)
 
-	account, err := repository.GetAccountByID(accountID, db)
+	account, err := repository.GetAccountByID(accountID, userID, db)
 	if err != nil {
 		return nil, err
 	}
@@ -106,7 +167,7 @@ func UpdateRecurringTransaction(id uuid.UUID, accountID uuid.UUID, categoryID uu
 	}
 
 	if budgetID.Valid {
-		budget, err := repository.GetBudgetByID(budgetID.UUID, db)
+		budget, err := repository.GetBudgetByID(budgetID.UUID, userID, db)
 		if err != nil {
 			return nil, err
 		}
@@ -123,10 +184,12 @@ func UpdateRecurringTransaction(id uuid.UUID, accountID uuid.UUID, categoryID uu
 	recurringTransaction.Amount = amount
 	recurringTransaction.Type = transactionType
 	recurringTransaction.Note = note
-	recurringTransaction.RecurringFrequency = recurringFrequency
-	recurringTransaction.RecurringDate = recurringDate
 	recurringTransaction.UpdatedAt = time.Now().In(utils.LOC)
 
+	if err := setRecurrenceRule(recurringTransaction, rule, userLocation(userID, db)); err != nil {
+		return nil, err
+	}
+
 	if err := repository.UpdateRecurringTransaction(recurringTransaction, db); err != nil {
 		return nil, err
 	}
@@ -137,8 +200,75 @@ func UpdateRecurringTransaction(id uuid.UUID, accountID uuid.UUID, categoryID uu
 	return recurringTransaction, nil
 }
 
-func DeleteRecurringTransaction(id uuid.UUID, db *sql.DB) error {
-	recurringTransaction, err := repository.GetRecurringTransactionByID(id, db)
+func PauseRecurringTransaction(id uuid.UUID, userID uuid.UUID, db *sql.DB) (*models.RecurringTransaction, error) {
+	recurringTransaction, err := repository.GetRecurringTransactionByID(id, userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if recurringTransaction == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	recurringTransaction.IsPaused = true
+	recurringTransaction.UpdatedAt = time.Now().In(utils.LOC)
+
+	if err := repository.SetRecurringTransactionPaused(id, userID, true, recurringTransaction.UpdatedAt, db); err != nil {
+		return nil, err
+	}
+
+	setNextRunAt(recurringTransaction, userLocation(userID, db))
+
+	// Log the pause
+	go CreateLog(userID, fmt.Sprintf("Recurring transaction '%s' paused", recurringTransaction.Description), db)
+
+	return recurringTransaction, nil
+}
+
+// ResumeRecurringTransaction restarts a paused rule from today in the user's time zone. Occurrences that fell due while
+// the rule was paused are not posted.
+func ResumeRecurringTransaction(id uuid.UUID, userID uuid.UUID, db *sql.DB) (*models.RecurringTransaction, error) {
+	recurringTransaction, err := repository.GetRecurringTransactionByID(id, userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if recurringTransaction == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	loc := userLocation(userID, db)
+	today := todayIn(loc)
+	yesterday := today.AddDate(0, 0, -1)
+	if recurringWindowStart(recurringTransaction, loc).Before(today) {
+		recurringTransaction.LastRunAt = &yesterday
+	}
+	recurringTransaction.IsPaused = false
+	recurringTransaction.UpdatedAt = time.Now().In(utils.LOC)
+
+	err = utils.DBTransaction(db, func(tx *sql.Tx) error {
+		if recurringTransaction.LastRunAt != nil {
+			if err := repository.UpdateRecurringTransactionLastRun(id, *recurringTransaction.LastRunAt, tx); err != nil {
+				return err
+			}
+		}
+
+		return repository.SetRecurringTransactionPaused(id, userID, false, recurringTransaction.UpdatedAt, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	setNextRunAt(recurringTransaction, loc)
+
+	// Log the resume
+	go CreateLog(userID, fmt.Sprintf("Recurring transaction '%s' resumed", recurringTransaction.Description), db)
+
+	return recurringTransaction, nil
+}
+
+func DeleteRecurringTransaction(id uuid.UUID, userID uuid.UUID, db *sql.DB) error {
+	recurringTransaction, err := repository.GetRecurringTransactionByID(id, userID, db)
 	if err != nil {
 		return err
 	}
@@ -147,7 +277,7 @@ func DeleteRecurringTransaction(id uuid.UUID, db *sql.DB) error {
 		return sql.ErrNoRows
 	}
 
-	err = repository.DeleteRecurringTransaction(id, db)
+	err = repository.DeleteRecurringTransaction(id, userID, db)
 	if err != nil {
 		return err
 	}
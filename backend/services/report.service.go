@@ -2,13 +2,8 @@ package services
 
 import (
 	"database/sql"
-	"encoding/csv"
-	"fmt"
-	"io"
-	"log"
 
 	"github.com/google/uuid"
-	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
 )
 
 func GenerateReport(userID uuid.UUID, startDate string, endDate string, db *sql.DB) (map[string]interface{}, error) {
@@ -19,7 +14,7 @@ func GenerateReport(userID uuid.UUID, startDate string, endDate string, db *sql.
 	}
 
 	// Get spending by category
-	spendingByCategory, err := repository.GetSpendingByCategory(userID, db)
+	spendingByCategory, err := GetSpendingByCategory(userID, db)
 	if err != nil {
 		return nil, err
 	}
@@ -29,54 +24,3 @@ func GenerateReport(userID uuid.UUID, startDate string, endDate string, db *sql.
 		"spendingByCategory": spendingByCategory,
 	}, nil
 }
-
-func ExportTransactions(userID uuid.UUID, writer io.Writer, db *sql.DB) error {
-	transactions, err := repository.GetTransactionsByUserID(userID, db)
-	if err != nil {
-		return err
-	}
-
-	// Fetch category and account names for each transaction
-	categoryMap := make(map[uuid.UUID]string)
-
-	categories, err := repository.GetCategories(db)
-	if err != nil {
-		log.Printf("Error fetching categories: %v", err)
-	}
-	for _, cat := range categories {
-		categoryMap[cat.ID] = cat.Name
-	}
-
-	csvWriter := csv.NewWriter(writer)
-	defer csvWriter.Flush()
-
-	// Write header
-	header := []string{"ID", "Date", "Description", "Amount", "Type", "Category", "Account"}
-	if err := csvWriter.Write(header); err != nil {
-		return err
-	}
-
-	// Write rows
-	for _, transaction := range transactions {
-		row := []string{
-			transaction.ID.String(),
-			transaction.TransactionDate.Format("2006-01-02"),
-			transaction.Description,
-			transaction.CategoryID.String(),
-			fmt.Sprintf("%.2f", transaction.Amount),
-			string(transaction.Type),
-		}
-
-		account, err := repository.GetAccountByID(transaction.AccountID, db)
-		if err != nil {
-			log.Printf("Error fetching account %s: %v", transaction.AccountID, err)
-		} else {
-			row = append(row, account.Name)
-		}
-		if err := csvWriter.Write(row); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
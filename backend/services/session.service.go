@@ -0,0 +1,232 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, the session has been revoked")
+)
+
+// issueRefreshToken stores a new refresh token for the session and returns its plain value.
+func issueRefreshToken(sessionID uuid.UUID, tx *sql.Tx, cfg *config.Config) (string, time.Time, error) {
+	token, expiresAt, err := utils.GenerateRefreshToken(cfg)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	refreshToken := &models.RefreshToken{
+		ID:        uuid.New(),
+		SessionID: sessionID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now().In(utils.LOC),
+	}
+
+	if err := repository.CreateRefreshToken(refreshToken, tx); err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// createSession starts a session for a device and returns its first access and refresh tokens.
+func createSession(userID uuid.UUID, userAgent string, ipAddress string, db *sql.DB, cfg *config.Config) (*models.AuthTokens, error) {
+	session := &models.Session{
+		ID:         uuid.New(),
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		CreatedAt:  time.Now().In(utils.LOC),
+		LastUsedAt: time.Now().In(utils.LOC),
+	}
+
+	tokens := &models.AuthTokens{SessionID: session.ID}
+
+	err := utils.DBTransaction(db, func(tx *sql.Tx) error {
+		refreshToken, expiresAt, err := utils.GenerateRefreshToken(cfg)
+		if err != nil {
+			return err
+		}
+		session.ExpiresAt = expiresAt
+
+		if err := repository.CreateSession(session, tx); err != nil {
+			return err
+		}
+
+		if err := repository.CreateRefreshToken(&models.RefreshToken{
+			ID:        uuid.New(),
+			SessionID: session.ID,
+			TokenHash: utils.HashToken(refreshToken),
+			ExpiresAt: expiresAt,
+			CreatedAt: session.CreatedAt,
+		}, tx); err != nil {
+			return err
+		}
+
+		tokens.RefreshToken = refreshToken
+		tokens.RefreshTokenExpiresAt = expiresAt
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tokens.AccessToken, tokens.AccessTokenExpiresAt, err = utils.GenerateToken(userID.String(), session.ID.String(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Log the session
+	go CreateLog(userID, "New session started", db)
+
+	return tokens, nil
+}
+
+// RefreshSession exchanges a refresh token for a new access and refresh token. Each refresh token
+// works once: presenting a used one means it leaked, so the whole session is revoked.
+func RefreshSession(refreshToken string, db *sql.DB, cfg *config.Config) (*models.AuthTokens, error) {
+	token, err := repository.GetRefreshTokenByHash(utils.HashToken(refreshToken), db)
+	if err != nil {
+		return nil, err
+	}
+
+	if token == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	session, err := repository.GetSessionByID(token.SessionID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(utils.LOC)
+	if session == nil || !session.IsActive(now) || !token.ExpiresAt.After(now) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if token.UsedAt != nil {
+		return nil, revokeReusedSession(session, db)
+	}
+
+	tokens := &models.AuthTokens{SessionID: session.ID}
+	reused := false
+
+	err = utils.DBTransaction(db, func(tx *sql.Tx) error {
+		claimed, err := repository.MarkRefreshTokenUsed(token.ID, now, tx)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			reused = true
+			return ErrRefreshTokenReused
+		}
+
+		tokens.RefreshToken, tokens.RefreshTokenExpiresAt, err = issueRefreshToken(session.ID, tx, cfg)
+		if err != nil {
+			return err
+		}
+
+		return repository.TouchSession(session.ID, now, tokens.RefreshTokenExpiresAt, tx)
+	})
+	if reused {
+		return nil, revokeReusedSession(session, db)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tokens.AccessToken, tokens.AccessTokenExpiresAt, err = utils.GenerateToken(session.UserID.String(), session.ID.String(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// revokeReusedSession ends a session whose refresh token was presented twice.
+func revokeReusedSession(session *models.Session, db *sql.DB) error {
+	if _, err := repository.RevokeSession(session.ID, session.UserID, time.Now().In(utils.LOC), db); err != nil {
+		return err
+	}
+
+	// Log the revocation
+	go CreateLog(session.UserID, "Session revoked after refresh token reuse", db)
+
+	return ErrRefreshTokenReused
+}
+
+// GetSessions lists the user's active sessions, flagging the one making the request.
+func GetSessions(userID uuid.UUID, currentSessionID uuid.UUID, db *sql.DB) ([]models.Session, error) {
+	sessions, err := repository.GetActiveSessionsByUserID(userID, time.Now().In(utils.LOC), db)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession ends one of the user's sessions. Its access tokens stop working immediately.
+func RevokeSession(id uuid.UUID, userID uuid.UUID, db *sql.DB) error {
+	revoked, err := repository.RevokeSession(id, userID, time.Now().In(utils.LOC), db)
+	if err != nil {
+		return err
+	}
+
+	if !revoked {
+		return sql.ErrNoRows
+	}
+
+	// Log the revocation
+	go CreateLog(userID, "Session revoked", db)
+
+	return nil
+}
+
+// RevokeOtherSessions ends every session of the user except the current one.
+func RevokeOtherSessions(userID uuid.UUID, currentSessionID uuid.UUID, db *sql.DB) error {
+	if err := repository.RevokeSessionsByUserID(userID, uuid.NullUUID{UUID: currentSessionID, Valid: true}, time.Now().In(utils.LOC), db); err != nil {
+		return err
+	}
+
+	// Log the revocation
+	go CreateLog(userID, "All other sessions revoked", db)
+
+	return nil
+}
+
+func Logout(sessionID uuid.UUID, userID uuid.UUID, db *sql.DB) error {
+	if _, err := repository.RevokeSession(sessionID, userID, time.Now().In(utils.LOC), db); err != nil {
+		return err
+	}
+
+	// Log the logout
+	go CreateLog(userID, "User logged out", db)
+
+	return nil
+}
+
+// CleanupSessions deletes sessions that expired or were revoked more than a week ago.
+func CleanupSessions(db *sql.DB) {
+	deleted, err := repository.DeleteStaleSessions(time.Now().In(utils.LOC).AddDate(0, 0, -7), db)
+	if err != nil {
+		log.Println("Error deleting stale sessions:", err)
+		return
+	}
+
+	log.Printf("Deleted %d stale sessions", deleted)
+}
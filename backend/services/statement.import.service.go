@@ -0,0 +1,370 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/pkg/ofx"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/pkg/qif"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+var ErrInvalidStatementCategory = errors.New("the default income category must be an income category and the default expense category an expense category")
+
+// Categories used for statement rows when no default is given.
+const (
+	defaultIncomeCategoryName  = "Other Income"
+	defaultExpenseCategoryName = "Other Expense"
+)
+
+const maxExternalIDLength = 255
+
+// NewStatementImportOptions checks the default categories of a statement import, falling back to
+// the system's Other Income and Other Expense categories. dateFormat gives the order of day and
+// month in QIF files and defaults to Quicken's MM/DD/YYYY.
+func NewStatementImportOptions(userID uuid.UUID, incomeCategoryID uuid.NullUUID, expenseCategoryID uuid.NullUUID, dateFormat models.DateFormat, db *sql.DB) (*models.StatementImportOptions, error) {
+	if dateFormat != "" && !dateFormat.IsValid() {
+		return nil, ErrInvalidDateFormat
+	}
+
+	options := &models.StatementImportOptions{
+		DayFirst: strings.HasPrefix(dateFormat.Layout(), "02"),
+	}
+
+	defaults := []struct {
+		id              uuid.NullUUID
+		name            string
+		transactionType models.TransactionType
+		target          *uuid.UUID
+	}{
+		{incomeCategoryID, defaultIncomeCategoryName, models.TransactionTypeIncome, &options.IncomeCategoryID},
+		{expenseCategoryID, defaultExpenseCategoryName, models.TransactionTypeExpense, &options.ExpenseCategoryID},
+	}
+
+	for _, category := range defaults {
+		var found *models.Category
+		var err error
+		if category.id.Valid {
+			found, err = repository.GetCategoryByID(category.id.UUID, userID, db)
+		} else {
+			found, err = repository.GetCategoryByNameAndType(category.name, category.transactionType, userID, db)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if found == nil {
+			return nil, sql.ErrNoRows
+		}
+
+		if found.Type != category.transactionType {
+			return nil, ErrInvalidStatementCategory
+		}
+
+		*category.target = found.ID
+	}
+
+	return options, nil
+}
+
+// ImportStatement imports an OFX, QFX or QIF file into the account. Rows carrying the bank's
+// transaction ID are skipped when the account already has that ID, so downloading overlapping
+// statements is safe, and QIF rows are skipped when they match a stored transaction by date,
+// amount and description. Rows are categorised by the user's rules, falling back to the default
+// categories of the options. Rows that cannot be read are reported and the rest are stored in one
+// database transaction. Problems with the file as a whole are reported in the result's Error.
+func ImportStatement(userID uuid.UUID, accountID uuid.UUID, name string, file io.Reader, options *models.StatementImportOptions, db *sql.DB) (*models.StatementImportResult, error) {
+	account, err := repository.GetAccountByID(accountID, userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if account == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	categories, err := repository.GetCategoriesByUserID(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.StatementImportResult{File: name}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []models.TransactionImportRow
+	result.Format, rows, err = parseStatement(name, data, account, options, categoryIndex(categories))
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	rules, err := loadRuleSet(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	categoriseImportRows(rows, accountID, rules, uuid.NullUUID{UUID: options.IncomeCategoryID, Valid: true}, uuid.NullUUID{UUID: options.ExpenseCategoryID, Valid: true})
+
+	for _, row := range rows {
+		if len(row.Errors) > 0 {
+			result.Failed++
+			result.Failures = append(result.Failures, models.StatementImportFailure{
+				Line:      row.Line,
+				Reference: row.ExternalID,
+				Error:     strings.Join(row.Errors, "; "),
+			})
+		}
+	}
+
+	now := time.Now().In(utils.LOC)
+
+	err = utils.DBTransaction(db, func(tx *sql.Tx) error {
+		// Imports into the same account run one after another, so each sees the IDs stored by
+		// the previous one.
+		if err := repository.LockAccount(accountID, userID, tx); err != nil {
+			return err
+		}
+
+		if _, err := markDuplicateRows(rows, accountID, userID, tx); err != nil {
+			return err
+		}
+
+		if err := markKnownExternalIDs(rows, accountID, tx); err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			if len(row.Errors) > 0 {
+				continue
+			}
+
+			if row.Duplicate {
+				result.Skipped++
+				continue
+			}
+
+			transaction := &models.Transaction{
+				ID:              uuid.New(),
+				UserID:          userID,
+				AccountID:       accountID,
+				CategoryID:      row.CategoryID,
+				BudgetID:        row.BudgetID,
+				Description:     row.Description,
+				Amount:          row.Amount,
+				Currency:        account.Currency,
+				Type:            row.Type,
+				TransactionDate: *row.TransactionDate,
+				Note:            sql.NullString{String: row.Note, Valid: row.Note != ""},
+				ExternalID:      sql.NullString{String: row.ExternalID, Valid: row.ExternalID != ""},
+				CreatedAt:       now,
+				UpdatedAt:       now,
+			}
+
+			if err := postTransaction(transaction, tx); err != nil {
+				return err
+			}
+			result.Imported++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Imported > 0 {
+		// Log the import
+		go CreateLog(userID, fmt.Sprintf("%d transactions imported into '%s' from %s", result.Imported, account.Name, name), db)
+
+		checkBudgetAlertsAsync(userID, db)
+	}
+
+	return result, nil
+}
+
+// parseStatement detects the format of the file from its content and reads its rows.
+func parseStatement(name string, data []byte, account *models.Account, options *models.StatementImportOptions, categoriesByName map[string]uuid.UUID) (models.StatementFormat, []models.TransactionImportRow, error) {
+	content := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \t\r\n")
+
+	switch {
+	case bytes.HasPrefix(content, []byte("!")):
+		rows, err := parseQIFStatement(data, options, categoriesByName)
+		return models.StatementFormatQIF, rows, err
+
+	case bytes.HasPrefix(bytes.ToUpper(content), []byte("OFXHEADER")), bytes.HasPrefix(content, []byte("<")):
+		format := models.StatementFormatOFX
+		if strings.EqualFold(filepath.Ext(name), ".qfx") {
+			format = models.StatementFormatQFX
+		}
+		rows, err := parseOFXStatement(data, account)
+		return format, rows, err
+
+	default:
+		return "", nil, errors.New("the file is neither OFX, QFX nor QIF")
+	}
+}
+
+func parseOFXStatement(data []byte, account *models.Account) ([]models.TransactionImportRow, error) {
+	statements, err := ofx.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(statements) == 0 {
+		return nil, errors.New("the file has no bank or credit card statement")
+	}
+
+	var rows []models.TransactionImportRow
+	for _, statement := range statements {
+		if statement.AccountID != statements[0].AccountID {
+			return nil, errors.New("the file holds statements of several accounts, import each account's file separately")
+		}
+
+		if currency := models.NormalizeCurrency(statement.Currency); statement.Currency != "" && currency != account.Currency {
+			return nil, fmt.Errorf("the statement is in %s but the account is in %s", currency, account.Currency)
+		}
+
+		for _, transaction := range statement.Transactions {
+			row := models.TransactionImportRow{
+				Description: strings.Join(strings.Fields(transaction.Name), " "),
+				ExternalID:  transaction.FITID,
+			}
+
+			if row.Description == "" {
+				row.Description = strings.Join(strings.Fields(transaction.Memo), " ")
+			} else if transaction.Memo != transaction.Name {
+				row.Note = transaction.Memo
+			}
+
+			if transaction.Posted.IsZero() {
+				row.Errors = append(row.Errors, "posting date is missing or invalid")
+			} else {
+				posted := transaction.Posted
+				row.TransactionDate = &posted
+			}
+
+			if utf8.RuneCountInString(row.ExternalID) > maxExternalIDLength {
+				row.Errors = append(row.Errors, fmt.Sprintf("transaction ID is longer than %d characters", maxExternalIDLength))
+			}
+
+			setStatementRow(&row, transaction.Amount, "", nil)
+			rows = append(rows, row)
+		}
+	}
+
+	return rows, nil
+}
+
+func parseQIFStatement(data []byte, options *models.StatementImportOptions, categoriesByName map[string]uuid.UUID) ([]models.TransactionImportRow, error) {
+	transactions, err := qif.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]models.TransactionImportRow, 0, len(transactions))
+	for _, transaction := range transactions {
+		row := models.TransactionImportRow{
+			Line:        transaction.Line,
+			Description: strings.Join(strings.Fields(transaction.Payee), " "),
+		}
+
+		if row.Description == "" {
+			row.Description = strings.Join(strings.Fields(transaction.Memo), " ")
+		} else {
+			row.Note = transaction.Memo
+		}
+
+		if date, err := qif.ParseDate(transaction.Date, options.DayFirst); err != nil {
+			row.Errors = append(row.Errors, fmt.Sprintf("date %q is not a valid date", transaction.Date))
+		} else {
+			row.TransactionDate = &date
+		}
+
+		setStatementRow(&row, transaction.Amount, transaction.Category, categoriesByName)
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// setStatementRow checks the description and sets the row's amount and type. Categories named in
+// the file are used when the user has one of that name and type, Quicken's subcategories and
+// classes falling back to their parent category.
+func setStatementRow(row *models.TransactionImportRow, amount string, category string, categoriesByName map[string]uuid.UUID) {
+	if row.Description == "" {
+		row.Errors = append(row.Errors, "description is empty")
+	} else if utf8.RuneCountInString(row.Description) > maxDescriptionLength {
+		row.Errors = append(row.Errors, fmt.Sprintf("description is longer than %d characters", maxDescriptionLength))
+	}
+
+	signed, err := parseImportAmount(amount, ".")
+	switch {
+	case err != nil:
+		row.Errors = append(row.Errors, fmt.Sprintf("amount %q is not a number", amount))
+		return
+	case signed.IsZero():
+		row.Errors = append(row.Errors, "amount is zero")
+		return
+	case signed.IsNegative():
+		row.Type = models.TransactionTypeExpense
+	default:
+		row.Type = models.TransactionTypeIncome
+	}
+	row.Amount = signed.Abs()
+
+	// Transfers are written as [Account] and are left to the rules and default categories.
+	if category == "" || strings.HasPrefix(category, "[") {
+		return
+	}
+
+	category, _, _ = strings.Cut(category, "/")
+	parent, _, _ := strings.Cut(category, ":")
+	for _, name := range []string{category, parent} {
+		if id, ok := categoriesByName[string(row.Type)+"|"+strings.ToLower(strings.TrimSpace(name))]; ok {
+			row.CategoryID = uuid.NullUUID{UUID: id, Valid: true}
+			return
+		}
+	}
+}
+
+// markKnownExternalIDs flags the valid rows whose bank ID is already stored on the account or
+// appears earlier in the file.
+func markKnownExternalIDs(rows []models.TransactionImportRow, accountID uuid.UUID, tx *sql.Tx) error {
+	var externalIDs []string
+	for _, row := range rows {
+		if len(row.Errors) == 0 && row.ExternalID != "" {
+			externalIDs = append(externalIDs, row.ExternalID)
+		}
+	}
+
+	known, err := repository.GetExistingExternalIDs(accountID, externalIDs, tx)
+	if err != nil {
+		return err
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		if len(row.Errors) > 0 || row.ExternalID == "" {
+			continue
+		}
+
+		row.Duplicate = known[row.ExternalID]
+		known[row.ExternalID] = true
+	}
+
+	return nil
+}
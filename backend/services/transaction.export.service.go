@@ -0,0 +1,274 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/pkg/ofx"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/pkg/xlsx"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+var (
+	ErrInvalidExportFormat = errors.New("format must be one of csv, jsonl, xlsx or ofx")
+	ErrInvalidExportFilter = errors.New("account and category must be IDs and type one of income, expense or transfer")
+)
+
+var transactionExportHeader = []string{"ID", "Date", "Description", "Amount", "Currency", "Type", "Category", "Account", "Destination Account", "Destination Amount", "Note"}
+
+// NewTransactionExportFilter checks the export filters and resolves the dates in the user's time
+// zone. The account and category have to be visible to the user.
+func NewTransactionExportFilter(userID uuid.UUID, startDate string, endDate string, accountID string, categoryID string, transactionType string, format models.ExportFormat, db *sql.DB) (*models.TransactionExportFilter, error) {
+	if !format.IsValid() {
+		return nil, ErrInvalidExportFormat
+	}
+
+	filter := &models.TransactionExportFilter{
+		Type: models.TransactionType(transactionType),
+		Legs: format == models.ExportFormatOFX,
+	}
+
+	var err error
+	filter.StartDate, filter.EndDate, err = resolveDateFilters(userID, startDate, endDate, db)
+	if err != nil {
+		return nil, err
+	}
+
+	switch filter.Type {
+	case "", models.TransactionTypeIncome, models.TransactionTypeExpense, models.TransactionTypeTransfer:
+	default:
+		return nil, ErrInvalidExportFilter
+	}
+
+	if accountID != "" {
+		id, err := uuid.Parse(accountID)
+		if err != nil {
+			return nil, ErrInvalidExportFilter
+		}
+
+		account, err := repository.GetAccountByID(id, userID, db)
+		if err != nil {
+			return nil, err
+		}
+		if account == nil {
+			return nil, sql.ErrNoRows
+		}
+		filter.AccountID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	if categoryID != "" {
+		id, err := uuid.Parse(categoryID)
+		if err != nil {
+			return nil, ErrInvalidExportFilter
+		}
+
+		category, err := repository.GetCategoryByID(id, userID, db)
+		if err != nil {
+			return nil, err
+		}
+		if category == nil {
+			return nil, sql.ErrNoRows
+		}
+		filter.CategoryID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	return filter, nil
+}
+
+// ExportTransactions streams the user's transactions matching the filter to w in the given format.
+func ExportTransactions(userID uuid.UUID, filter *models.TransactionExportFilter, format models.ExportFormat, w io.Writer, db *sql.DB) error {
+	switch format {
+	case models.ExportFormatCSV:
+		return exportTransactionsCSV(userID, filter, w, db)
+	case models.ExportFormatJSONL:
+		return exportTransactionsJSONL(userID, filter, w, db)
+	case models.ExportFormatXLSX:
+		return exportTransactionsXLSX(userID, filter, w, db)
+	case models.ExportFormatOFX:
+		return exportTransactionsOFX(userID, filter, w, db)
+	default:
+		return ErrInvalidExportFormat
+	}
+}
+
+// transactionExportRecord returns the row's values in the order of transactionExportHeader.
+func transactionExportRecord(row *models.TransactionExportRow) []string {
+	destinationAmount := ""
+	if row.DestinationAmount != nil {
+		destinationAmount = row.DestinationAmount.String()
+	}
+
+	return []string{
+		row.ID.String(),
+		row.TransactionDate.Format("2006-01-02"),
+		row.Description,
+		row.Amount.String(),
+		string(row.Currency),
+		string(row.Type),
+		row.Category,
+		row.Account,
+		row.DestinationAccount,
+		destinationAmount,
+		row.Note,
+	}
+}
+
+func exportTransactionsCSV(userID uuid.UUID, filter *models.TransactionExportFilter, w io.Writer, db *sql.DB) error {
+	csvWriter := csv.NewWriter(w)
+
+	if err := csvWriter.Write(transactionExportHeader); err != nil {
+		return err
+	}
+
+	err := repository.StreamTransactionsForExport(userID, filter, func(row *models.TransactionExportRow) error {
+		return csvWriter.Write(transactionExportRecord(row))
+	}, db)
+	if err != nil {
+		return err
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func exportTransactionsJSONL(userID uuid.UUID, filter *models.TransactionExportFilter, w io.Writer, db *sql.DB) error {
+	encoder := json.NewEncoder(w)
+
+	return repository.StreamTransactionsForExport(userID, filter, func(row *models.TransactionExportRow) error {
+		return encoder.Encode(row)
+	}, db)
+}
+
+func exportTransactionsXLSX(userID uuid.UUID, filter *models.TransactionExportFilter, w io.Writer, db *sql.DB) error {
+	sheet, err := xlsx.NewWriter(w, "Transactions")
+	if err != nil {
+		return err
+	}
+
+	if err := sheet.WriteHeader(transactionExportHeader...); err != nil {
+		return err
+	}
+
+	err = repository.StreamTransactionsForExport(userID, filter, func(row *models.TransactionExportRow) error {
+		destinationAmount := xlsx.String("")
+		if row.DestinationAmount != nil {
+			destinationAmount = xlsx.Number(row.DestinationAmount.String())
+		}
+
+		return sheet.WriteRow(
+			xlsx.String(row.ID.String()),
+			xlsx.Date(row.TransactionDate),
+			xlsx.String(row.Description),
+			xlsx.Number(row.Amount.String()),
+			xlsx.String(string(row.Currency)),
+			xlsx.String(string(row.Type)),
+			xlsx.String(row.Category),
+			xlsx.String(row.Account),
+			xlsx.String(row.DestinationAccount),
+			destinationAmount,
+			xlsx.String(row.Note),
+		)
+	}, db)
+	if err != nil {
+		return err
+	}
+
+	return sheet.Close()
+}
+
+// ofxAccountTypes maps account types to OFX bank account types. Others are reported as checking.
+var ofxAccountTypes = map[models.AccountType]string{
+	models.AccountTypeSavings:    ofx.AccountSavings,
+	models.AccountTypeCreditCard: ofx.AccountCreditLine,
+	models.AccountTypeLoan:       ofx.AccountCreditLine,
+	models.AccountTypeInvestment: ofx.AccountMoneyMarket,
+}
+
+// exportTransactionsOFX writes one bank statement per account. Income is a credit, expenses are
+// debits and each transfer appears as an outgoing and an incoming transfer in its two accounts.
+func exportTransactionsOFX(userID uuid.UUID, filter *models.TransactionExportFilter, w io.Writer, db *sql.DB) error {
+	now := time.Now().In(utils.LOC)
+
+	writer, err := ofx.NewWriter(w, now)
+	if err != nil {
+		return err
+	}
+
+	end := todayIn(userLocation(userID, db))
+	if filter.EndDate != "" {
+		end, _ = time.Parse("2006-01-02", filter.EndDate)
+	}
+
+	var current uuid.NullUUID
+
+	err = repository.StreamTransactionsForExport(userID, filter, func(row *models.TransactionExportRow) error {
+		if !current.Valid || current.UUID != row.AccountID {
+			if current.Valid {
+				if err := writer.EndStatement(); err != nil {
+					return err
+				}
+			}
+
+			start := row.TransactionDate
+			if filter.StartDate != "" {
+				start, _ = time.Parse("2006-01-02", filter.StartDate)
+			}
+
+			accountType, ok := ofxAccountTypes[row.AccountType]
+			if !ok {
+				accountType = ofx.AccountChecking
+			}
+
+			statement := ofx.Statement{
+				AccountID:   strings.ReplaceAll(row.AccountID.String(), "-", ""),
+				AccountType: accountType,
+				Currency:    string(row.Currency),
+				Start:       start,
+				End:         end,
+				Balance:     row.AccountBalance.String(),
+				BalanceAt:   now,
+			}
+			if err := writer.StartStatement(statement); err != nil {
+				return err
+			}
+			current = uuid.NullUUID{UUID: row.AccountID, Valid: true}
+		}
+
+		transaction := ofx.Transaction{
+			Posted: row.TransactionDate,
+			FITID:  row.ID.String(),
+			Name:   row.Description,
+			Memo:   row.Note,
+		}
+
+		switch {
+		case row.Type == models.TransactionTypeIncome:
+			transaction.Type = ofx.TypeCredit
+			transaction.Amount = row.Amount.String()
+		case row.Type == models.TransactionTypeTransfer && row.Incoming:
+			transaction.Type = ofx.TypeTransfer
+			transaction.Amount = row.Amount.String()
+		case row.Type == models.TransactionTypeTransfer:
+			transaction.Type = ofx.TypeTransfer
+			transaction.Amount = row.Amount.Neg().String()
+		default:
+			transaction.Type = ofx.TypeDebit
+			transaction.Amount = row.Amount.Neg().String()
+		}
+
+		return writer.WriteTransaction(transaction)
+	}, db)
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
@@ -0,0 +1,479 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+var (
+	ErrInvalidImportFile    = errors.New("invalid import file")
+	ErrImportHasInvalidRows = errors.New("the file has rows with errors, preview the import to see them")
+)
+
+const (
+	maxImportRows        = 10000
+	maxDescriptionLength = 255
+)
+
+// ImportTransactions reads a CSV file into the account using the column mapping of the user's
+// import profile. Rows are categorised by the user's rules, falling back to the profile's default
+// categories. A dry run only returns the parsed rows. Otherwise the rows are posted in one
+// database transaction, skipping duplicates of existing transactions, and nothing is stored when
+// any row has errors.
+func ImportTransactions(userID uuid.UUID, accountID uuid.UUID, profileID uuid.UUID, file io.Reader, dryRun bool, db *sql.DB) (*models.TransactionImportResult, error) {
+	profile, err := repository.GetImportProfileByID(profileID, userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	account, err := repository.GetAccountByID(accountID, userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if account == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	categories, err := repository.GetCategoriesByUserID(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := parseImportFile(file, &profile.ImportMapping, categories)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := loadRuleSet(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	categoriseImportRows(rows, accountID, rules, profile.IncomeCategoryID, profile.ExpenseCategoryID)
+	for i := range rows {
+		row := &rows[i]
+		if len(row.Errors) == 0 && !row.CategoryID.Valid {
+			row.Errors = append(row.Errors, fmt.Sprintf("no category given, no rule matched and the profile has no default %s category", row.Type))
+		}
+	}
+
+	result := &models.TransactionImportResult{
+		DryRun:    dryRun,
+		AccountID: accountID,
+		Total:     len(rows),
+		Rows:      rows,
+	}
+
+	for _, row := range rows {
+		if len(row.Errors) > 0 {
+			result.Invalid++
+		}
+	}
+
+	if dryRun {
+		result.Duplicates, err = markDuplicateRows(result.Rows, accountID, userID, db)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	if result.Invalid > 0 {
+		return nil, fmt.Errorf("%w (%d of %d rows)", ErrImportHasInvalidRows, result.Invalid, result.Total)
+	}
+
+	now := time.Now().In(utils.LOC)
+
+	err = utils.DBTransaction(db, func(tx *sql.Tx) error {
+		// Concurrent imports into the account wait here, so each one sees the rows stored by
+		// the previous one when looking for duplicates.
+		if err := repository.LockAccount(accountID, userID, tx); err != nil {
+			return err
+		}
+
+		duplicates, err := markDuplicateRows(result.Rows, accountID, userID, tx)
+		if err != nil {
+			return err
+		}
+		result.Duplicates = duplicates
+
+		for _, row := range result.Rows {
+			if row.Duplicate {
+				continue
+			}
+
+			transaction := &models.Transaction{
+				ID:              uuid.New(),
+				UserID:          userID,
+				AccountID:       accountID,
+				CategoryID:      row.CategoryID,
+				BudgetID:        row.BudgetID,
+				Description:     row.Description,
+				Amount:          row.Amount,
+				Currency:        account.Currency,
+				Type:            row.Type,
+				TransactionDate: *row.TransactionDate,
+				Note:            sql.NullString{String: row.Note, Valid: row.Note != ""},
+				CreatedAt:       now,
+				UpdatedAt:       now,
+			}
+
+			if err := postTransaction(transaction, tx); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Imported = result.Total - result.Duplicates
+
+	if result.Imported > 0 {
+		// Log the import
+		go CreateLog(userID, fmt.Sprintf("%d transactions imported into '%s'", result.Imported, account.Name), db)
+
+		checkBudgetAlertsAsync(userID, db)
+	}
+
+	return result, nil
+}
+
+// importColumns holds the positions of the mapped columns in the file, -1 when unused.
+type importColumns struct {
+	date, description, amount, debit, credit, note, category int
+}
+
+// parseImportFile reads every data row of the file. Problems with single rows are reported on the
+// row, problems with the file as a whole are returned as ErrInvalidImportFile.
+func parseImportFile(file io.Reader, mapping *models.ImportMapping, categories []models.Category) ([]models.TransactionImportRow, error) {
+	reader := csv.NewReader(file)
+	reader.Comma, _ = utf8.DecodeRuneInString(mapping.Delimiter)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	for i := 0; i < mapping.SkipRows; i++ {
+		if _, err := reader.Read(); err != nil {
+			return nil, fmt.Errorf("%w: the file ends before the header row", ErrInvalidImportFile)
+		}
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: the header row is missing", ErrInvalidImportFile)
+	}
+
+	positions := map[string]int{}
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := positions[name]; !ok {
+			positions[name] = i
+		}
+	}
+
+	var missing []string
+	column := func(name string) int {
+		if name == "" {
+			return -1
+		}
+		position, ok := positions[strings.ToLower(name)]
+		if !ok {
+			missing = append(missing, fmt.Sprintf("%q", name))
+			return -1
+		}
+		return position
+	}
+
+	columns := importColumns{
+		date:        column(mapping.DateColumn),
+		description: column(mapping.DescriptionColumn),
+		amount:      column(mapping.AmountColumn),
+		debit:       column(mapping.DebitColumn),
+		credit:      column(mapping.CreditColumn),
+		note:        column(mapping.NoteColumn),
+		category:    column(mapping.CategoryColumn),
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: the header has no %s column", ErrInvalidImportFile, strings.Join(missing, ", "))
+	}
+
+	categoriesByName := categoryIndex(categories)
+
+	rows := []models.TransactionImportRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+		}
+
+		if isBlankRecord(record) {
+			continue
+		}
+
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("%w: at most %d rows can be imported at once", ErrInvalidImportFile, maxImportRows)
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, parseImportRecord(record, line, columns, mapping, categoriesByName))
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: the file has no transactions", ErrInvalidImportFile)
+	}
+
+	return rows, nil
+}
+
+// categoryIndex maps "type|lowercase name" to the category ID, preferring the user's own
+// categories over system categories of the same name.
+func categoryIndex(categories []models.Category) map[string]uuid.UUID {
+	index := map[string]uuid.UUID{}
+	for _, category := range categories {
+		key := string(category.Type) + "|" + strings.ToLower(category.Name)
+		if _, ok := index[key]; !ok || category.UserID.Valid {
+			index[key] = category.ID
+		}
+	}
+	return index
+}
+
+// parseImportRecord turns one record into a row, collecting every problem with it. The category is
+// only set when the file names one.
+func parseImportRecord(record []string, line int, columns importColumns, mapping *models.ImportMapping, categoriesByName map[string]uuid.UUID) models.TransactionImportRow {
+	value := func(position int) string {
+		if position < 0 || position >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[position])
+	}
+
+	row := models.TransactionImportRow{
+		Line:        line,
+		Description: strings.Join(strings.Fields(value(columns.description)), " "),
+		Note:        value(columns.note),
+	}
+
+	if date := value(columns.date); date == "" {
+		row.Errors = append(row.Errors, "date is empty")
+	} else if transactionDate, err := time.Parse(mapping.DateFormat.Layout(), date); err != nil {
+		row.Errors = append(row.Errors, fmt.Sprintf("date %q does not match %s", date, mapping.DateFormat))
+	} else {
+		row.TransactionDate = &transactionDate
+	}
+
+	if row.Description == "" {
+		row.Errors = append(row.Errors, "description is empty")
+	} else if utf8.RuneCountInString(row.Description) > maxDescriptionLength {
+		row.Errors = append(row.Errors, fmt.Sprintf("description is longer than %d characters", maxDescriptionLength))
+	}
+
+	if err := setImportAmount(&row, value(columns.amount), value(columns.debit), value(columns.credit), mapping); err != nil {
+		row.Errors = append(row.Errors, err.Error())
+		return row
+	}
+
+	if name := value(columns.category); name != "" {
+		id, ok := categoriesByName[string(row.Type)+"|"+strings.ToLower(name)]
+		if !ok {
+			row.Errors = append(row.Errors, fmt.Sprintf("there is no %s category named %q", row.Type, name))
+			return row
+		}
+		row.CategoryID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	return row
+}
+
+// setImportAmount sets the row's type and positive amount from either the signed amount column or
+// the debit and credit columns.
+func setImportAmount(row *models.TransactionImportRow, amount string, debit string, credit string, mapping *models.ImportMapping) error {
+	if mapping.AmountColumn != "" {
+		if amount == "" {
+			return errors.New("amount is empty")
+		}
+
+		signed, err := parseImportAmount(amount, mapping.DecimalSeparator)
+		if err != nil {
+			return fmt.Errorf("amount %q is not a number", amount)
+		}
+
+		if mapping.AmountSign == models.AmountSignExpensePositive {
+			signed = signed.Neg()
+		}
+
+		switch {
+		case signed.IsZero():
+			return errors.New("amount is zero")
+		case signed.IsNegative():
+			row.Type = models.TransactionTypeExpense
+		default:
+			row.Type = models.TransactionTypeIncome
+		}
+		row.Amount = signed.Abs()
+		return nil
+	}
+
+	var debitAmount, creditAmount models.Money
+	var err error
+
+	if debit != "" {
+		if debitAmount, err = parseImportAmount(debit, mapping.DecimalSeparator); err != nil {
+			return fmt.Errorf("debit %q is not a number", debit)
+		}
+	}
+
+	if credit != "" {
+		if creditAmount, err = parseImportAmount(credit, mapping.DecimalSeparator); err != nil {
+			return fmt.Errorf("credit %q is not a number", credit)
+		}
+	}
+
+	switch {
+	case !debitAmount.IsZero() && !creditAmount.IsZero():
+		return errors.New("both debit and credit are set")
+	case !debitAmount.IsZero():
+		row.Type = models.TransactionTypeExpense
+		row.Amount = debitAmount.Abs()
+	case !creditAmount.IsZero():
+		row.Type = models.TransactionTypeIncome
+		row.Amount = creditAmount.Abs()
+	default:
+		return errors.New("neither debit nor credit is set")
+	}
+
+	return nil
+}
+
+// parseImportAmount reads an amount the way banks write them, allowing currency symbols,
+// thousands separators and negative amounts in parentheses.
+func parseImportAmount(value string, decimalSeparator string) (models.Money, error) {
+	negative := strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")")
+	if negative {
+		value = value[1 : len(value)-1]
+	}
+
+	thousandsSeparator := ','
+	if decimalSeparator == "," {
+		thousandsSeparator = '.'
+	}
+
+	value = strings.Map(func(r rune) rune {
+		switch {
+		case string(r) == decimalSeparator:
+			return '.'
+		case r == thousandsSeparator, r == '\'', unicode.IsSpace(r), unicode.Is(unicode.Sc, r):
+			return -1
+		default:
+			return r
+		}
+	}, value)
+
+	amount, err := models.ParseMoney(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if negative {
+		amount = amount.Neg()
+	}
+	return amount, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// importFingerprint identifies a transaction by its date, type, amount and description. Case and
+// repeated spaces in the description are ignored.
+func importFingerprint(transactionDate time.Time, transactionType models.TransactionType, amount models.Money, description string) string {
+	return strings.Join([]string{
+		transactionDate.Format("2006-01-02"),
+		string(transactionType),
+		amount.String(),
+		strings.ToLower(strings.Join(strings.Fields(description), " ")),
+	}, "|")
+}
+
+// markDuplicateRows flags the valid rows whose fingerprint matches a transaction already booked
+// on the account and returns how many there are. Each existing transaction matches one row only,
+// so a file with two identical rows against one stored transaction still imports one of them.
+// Rows with a bank ID are left to markKnownExternalIDs.
+func markDuplicateRows(rows []models.TransactionImportRow, accountID uuid.UUID, userID uuid.UUID, db interfaces.SqlExecutor) (int, error) {
+	var first, last time.Time
+	for _, row := range rows {
+		if len(row.Errors) > 0 || row.ExternalID != "" {
+			continue
+		}
+		if first.IsZero() || row.TransactionDate.Before(first) {
+			first = *row.TransactionDate
+		}
+		if last.IsZero() || row.TransactionDate.After(last) {
+			last = *row.TransactionDate
+		}
+	}
+
+	if first.IsZero() {
+		return 0, nil
+	}
+
+	existing, err := repository.GetAccountTransactionsBetween(accountID, userID, first, last, db)
+	if err != nil {
+		return 0, err
+	}
+
+	unmatched := map[string]int{}
+	for _, transaction := range existing {
+		unmatched[importFingerprint(transaction.TransactionDate, transaction.Type, transaction.Amount, transaction.Description)]++
+	}
+
+	duplicates := 0
+	for i := range rows {
+		row := &rows[i]
+		if len(row.Errors) > 0 || row.ExternalID != "" {
+			continue
+		}
+
+		row.Duplicate = false
+
+		fingerprint := importFingerprint(*row.TransactionDate, row.Type, row.Amount, row.Description)
+		if unmatched[fingerprint] > 0 {
+			unmatched[fingerprint]--
+			row.Duplicate = true
+			duplicates++
+		}
+	}
+
+	return duplicates, nil
+}
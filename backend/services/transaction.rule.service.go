@@ -0,0 +1,479 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+var (
+	ErrInvalidTransactionRule = errors.New("invalid transaction rule")
+	ErrCategoryRequired       = errors.New("categoryId is required when no rule sets a category")
+	ErrInvalidRuleDateRange   = errors.New("startDate and endDate are required and startDate must not be after endDate")
+)
+
+const (
+	maxTransactionRuleName = 100
+	maxRuleConditionLength = 255
+)
+
+func invalidTransactionRule(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidTransactionRule, reason)
+}
+
+const rulePatternFlags = "(?i)"
+
+// compileRulePattern compiles a rule's description pattern, which is matched without regard to case.
+// Patterns are validated and run through it, so a saved pattern always compiles the same way.
+func compileRulePattern(pattern string) (*regexp.Regexp, error) {
+	compiled, err := regexp.Compile(rulePatternFlags + pattern)
+	if err != nil {
+		// Report the error against the pattern as the user wrote it.
+		var syntaxErr *syntax.Error
+		if errors.As(err, &syntaxErr) {
+			return nil, &syntax.Error{Code: syntaxErr.Code, Expr: strings.TrimPrefix(syntaxErr.Expr, rulePatternFlags)}
+		}
+		return nil, err
+	}
+	return compiled, nil
+}
+
+// validateTransactionRule trims and checks the rule. It needs at least one condition and one
+// action, and the account, category and budget it names have to be visible to the user.
+func validateTransactionRule(rule *models.TransactionRule, userID uuid.UUID, db *sql.DB) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" || utf8.RuneCountInString(rule.Name) > maxTransactionRuleName {
+		return invalidTransactionRule(fmt.Sprintf("name is required and must be at most %d characters", maxTransactionRuleName))
+	}
+
+	for _, condition := range []*string{&rule.DescriptionContains, &rule.DescriptionPattern, &rule.NoteContains} {
+		*condition = strings.TrimSpace(*condition)
+		if utf8.RuneCountInString(*condition) > maxRuleConditionLength {
+			return invalidTransactionRule(fmt.Sprintf("text conditions must be at most %d characters", maxRuleConditionLength))
+		}
+	}
+	rule.Note = strings.TrimSpace(rule.Note)
+
+	if rule.DescriptionPattern != "" {
+		if _, err := compileRulePattern(rule.DescriptionPattern); err != nil {
+			return invalidTransactionRule(fmt.Sprintf("descriptionPattern is not a valid regular expression: %v", err))
+		}
+	}
+
+	switch {
+	case rule.MinAmount != nil && rule.MinAmount.IsNegative(), rule.MaxAmount != nil && rule.MaxAmount.IsNegative():
+		return invalidTransactionRule("minAmount and maxAmount must not be negative")
+	case rule.MinAmount != nil && rule.MaxAmount != nil && rule.MinAmount.Cmp(*rule.MaxAmount) > 0:
+		return invalidTransactionRule("minAmount must not be greater than maxAmount")
+	}
+
+	conditions := rule.RuleConditions
+	if conditions.DescriptionContains == "" && conditions.DescriptionPattern == "" && conditions.MinAmount == nil && conditions.MaxAmount == nil && !conditions.AccountID.Valid && conditions.NoteContains == "" {
+		return invalidTransactionRule("at least one condition is required")
+	}
+
+	if !rule.CategoryID.Valid && !rule.BudgetID.Valid && rule.Note == "" {
+		return invalidTransactionRule("at least one of categoryId, budgetId and note is required")
+	}
+
+	if rule.AccountID.Valid {
+		account, err := repository.GetAccountByID(rule.AccountID.UUID, userID, db)
+		if err != nil {
+			return err
+		}
+		if account == nil {
+			return sql.ErrNoRows
+		}
+	}
+
+	if rule.CategoryID.Valid {
+		category, err := repository.GetCategoryByID(rule.CategoryID.UUID, userID, db)
+		if err != nil {
+			return err
+		}
+		if category == nil {
+			return sql.ErrNoRows
+		}
+	}
+
+	return ensureBudgetExists(rule.BudgetID, userID, db)
+}
+
+// CreateTransactionRule saves a rule. Rules are active unless isActive is false.
+func CreateTransactionRule(userID uuid.UUID, name string, priority int, isActive *bool, conditions models.RuleConditions, actions models.RuleActions, db *sql.DB) (*models.TransactionRule, error) {
+	now := time.Now().In(utils.LOC)
+	rule := &models.TransactionRule{
+		ID:             uuid.New(),
+		UserID:         userID,
+		Name:           name,
+		Priority:       priority,
+		IsActive:       isActive == nil || *isActive,
+		RuleConditions: conditions,
+		RuleActions:    actions,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err := validateTransactionRule(rule, userID, db); err != nil {
+		return nil, err
+	}
+
+	if err := repository.CreateTransactionRule(rule, db); err != nil {
+		return nil, err
+	}
+
+	// Log the creation
+	go CreateLog(userID, fmt.Sprintf("New transaction rule '%s' created", rule.Name), db)
+
+	return rule, nil
+}
+
+func GetTransactionRules(userID uuid.UUID, db *sql.DB) ([]models.TransactionRule, error) {
+	return repository.GetTransactionRulesByUserID(userID, db)
+}
+
+// UpdateTransactionRule replaces the rule's settings. The rule stays active or inactive when
+// isActive is nil.
+func UpdateTransactionRule(id uuid.UUID, userID uuid.UUID, name string, priority int, isActive *bool, conditions models.RuleConditions, actions models.RuleActions, db *sql.DB) (*models.TransactionRule, error) {
+	rule, err := repository.GetTransactionRuleByID(id, userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if rule == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	rule.Name = name
+	rule.Priority = priority
+	if isActive != nil {
+		rule.IsActive = *isActive
+	}
+	rule.RuleConditions = conditions
+	rule.RuleActions = actions
+	rule.UpdatedAt = time.Now().In(utils.LOC)
+
+	if err := validateTransactionRule(rule, userID, db); err != nil {
+		return nil, err
+	}
+
+	if err := repository.UpdateTransactionRule(rule, db); err != nil {
+		return nil, err
+	}
+
+	// Log the update
+	go CreateLog(userID, fmt.Sprintf("Transaction rule '%s' updated", rule.Name), db)
+
+	return rule, nil
+}
+
+func DeleteTransactionRule(id uuid.UUID, userID uuid.UUID, db *sql.DB) error {
+	rule, err := repository.GetTransactionRuleByID(id, userID, db)
+	if err != nil {
+		return err
+	}
+
+	if rule == nil {
+		return sql.ErrNoRows
+	}
+
+	if err := repository.DeleteTransactionRule(id, userID, db); err != nil {
+		return err
+	}
+
+	// Log the deletion
+	go CreateLog(userID, fmt.Sprintf("Transaction rule '%s' deleted", rule.Name), db)
+
+	return nil
+}
+
+// ruleSet holds a user's active rules in the order they are tried, with their patterns compiled.
+type ruleSet struct {
+	rules         []models.TransactionRule
+	patterns      map[uuid.UUID]*regexp.Regexp
+	categoryTypes map[uuid.UUID]models.TransactionType
+}
+
+func loadRuleSet(userID uuid.UUID, db interfaces.SqlExecutor) (*ruleSet, error) {
+	rules, err := repository.GetActiveTransactionRules(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rules) == 0 {
+		return newRuleSet(nil, nil), nil
+	}
+
+	categories, err := repository.GetCategoriesByUserID(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRuleSet(rules, categories), nil
+}
+
+// newRuleSet orders the rules by descending priority, keeping the order of rules with the same
+// priority, and compiles their patterns. categories are the ones the rules' categories are
+// looked up in.
+func newRuleSet(rules []models.TransactionRule, categories []models.Category) *ruleSet {
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Priority > rules[j].Priority
+	})
+
+	set := &ruleSet{
+		rules:         rules,
+		patterns:      map[uuid.UUID]*regexp.Regexp{},
+		categoryTypes: map[uuid.UUID]models.TransactionType{},
+	}
+
+	for _, category := range categories {
+		set.categoryTypes[category.ID] = category.Type
+	}
+
+	for _, rule := range rules {
+		if rule.DescriptionPattern == "" {
+			continue
+		}
+
+		pattern, err := compileRulePattern(rule.DescriptionPattern)
+		if err != nil {
+			// Patterns are checked when saved, so this rule is skipped rather than failing every transaction.
+			log.Printf("Error compiling the pattern of transaction rule %s: %v", rule.ID, err)
+			continue
+		}
+		set.patterns[rule.ID] = pattern
+	}
+
+	return set
+}
+
+// match returns the first rule the transaction matches, or nil. transactionType is empty when the
+// type is not known yet, otherwise rules setting a category of the other type are passed over.
+func (s *ruleSet) match(accountID uuid.UUID, transactionType models.TransactionType, description string, amount models.Money, note string) *models.TransactionRule {
+	description = strings.ToLower(description)
+	note = strings.ToLower(note)
+
+	for i := range s.rules {
+		rule := &s.rules[i]
+
+		if rule.CategoryID.Valid {
+			categoryType, ok := s.categoryTypes[rule.CategoryID.UUID]
+			if !ok || (transactionType != "" && categoryType != transactionType) {
+				continue
+			}
+		}
+
+		if rule.AccountID.Valid && rule.AccountID.UUID != accountID {
+			continue
+		}
+
+		if rule.DescriptionContains != "" && !strings.Contains(description, strings.ToLower(rule.DescriptionContains)) {
+			continue
+		}
+
+		if rule.DescriptionPattern != "" {
+			pattern, ok := s.patterns[rule.ID]
+			if !ok || !pattern.MatchString(description) {
+				continue
+			}
+		}
+
+		if rule.MinAmount != nil && amount.Cmp(*rule.MinAmount) < 0 {
+			continue
+		}
+
+		if rule.MaxAmount != nil && amount.Cmp(*rule.MaxAmount) > 0 {
+			continue
+		}
+
+		if rule.NoteContains != "" && !strings.Contains(note, strings.ToLower(rule.NoteContains)) {
+			continue
+		}
+
+		return rule
+	}
+
+	return nil
+}
+
+// categoriseImportRows runs the rules over the valid rows of an import. A rule's category is used
+// unless the file names one, its budget is set and its note fills in an empty note. Rows still
+// without a category get the default category of their type.
+func categoriseImportRows(rows []models.TransactionImportRow, accountID uuid.UUID, rules *ruleSet, incomeCategoryID uuid.NullUUID, expenseCategoryID uuid.NullUUID) {
+	for i := range rows {
+		row := &rows[i]
+		if len(row.Errors) > 0 {
+			continue
+		}
+
+		if rule := rules.match(accountID, row.Type, row.Description, row.Amount, row.Note); rule != nil {
+			row.RuleID = uuid.NullUUID{UUID: rule.ID, Valid: true}
+			if !row.CategoryID.Valid {
+				row.CategoryID = rule.CategoryID
+			}
+			if rule.BudgetID.Valid {
+				row.BudgetID = rule.BudgetID
+			}
+			if row.Note == "" {
+				row.Note = rule.Note
+			}
+		}
+
+		if !row.CategoryID.Valid {
+			if row.Type == models.TransactionTypeIncome {
+				row.CategoryID = incomeCategoryID
+			} else {
+				row.CategoryID = expenseCategoryID
+			}
+		}
+	}
+}
+
+// MatchTransactionRule shows what the rules would set on a transaction with the given details.
+func MatchTransactionRule(userID uuid.UUID, accountID uuid.UUID, transactionType models.TransactionType, description string, amount models.Money, note string, db *sql.DB) (*models.TransactionRuleMatch, error) {
+	rules, err := loadRuleSet(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := rules.match(accountID, transactionType, description, amount, note)
+	if rule == nil {
+		return &models.TransactionRuleMatch{}, nil
+	}
+
+	match := &models.TransactionRuleMatch{
+		Matched:    true,
+		Rule:       rule,
+		CategoryID: rule.CategoryID,
+		BudgetID:   rule.BudgetID,
+		Note:       note,
+	}
+	if match.Note == "" {
+		match.Note = rule.Note
+	}
+
+	return match, nil
+}
+
+// ApplyTransactionRules re-runs the rules over the income and expenses dated in the range, given
+// as days in the user's time zone. A matching rule's category and budget replace the stored ones
+// and its note fills in empty notes. Balances are not affected, since a rule's category always
+// has the transaction's type. A dry run only lists the changes.
+func ApplyTransactionRules(userID uuid.UUID, startDate string, endDate string, dryRun bool, db *sql.DB) (*models.TransactionRuleApplyResult, error) {
+	if startDate == "" || endDate == "" {
+		return nil, ErrInvalidRuleDateRange
+	}
+
+	startDate, endDate, err := resolveDateFilters(userID, startDate, endDate, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if startDate > endDate {
+		return nil, ErrInvalidRuleDateRange
+	}
+
+	rules, err := loadRuleSet(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.TransactionRuleApplyResult{
+		DryRun:  dryRun,
+		Changes: []models.TransactionRuleChange{},
+	}
+
+	apply := func(db interfaces.SqlExecutor) error {
+		transactions, err := repository.GetUserTransactionsBetween(userID, startDate, endDate, db)
+		if err != nil {
+			return err
+		}
+		result.Checked = len(transactions)
+
+		now := time.Now().In(utils.LOC)
+
+		for i := range transactions {
+			transaction := &transactions[i]
+
+			rule := rules.match(transaction.AccountID, transaction.Type, transaction.Description, transaction.Amount, transaction.Note.String)
+			if rule == nil {
+				continue
+			}
+			result.Matched++
+
+			changed := false
+			if rule.CategoryID.Valid && transaction.CategoryID != rule.CategoryID {
+				transaction.CategoryID = rule.CategoryID
+				changed = true
+			}
+			if rule.BudgetID.Valid && transaction.BudgetID != rule.BudgetID {
+				transaction.BudgetID = rule.BudgetID
+				changed = true
+			}
+			if rule.Note != "" && transaction.Note.String == "" {
+				transaction.Note = sql.NullString{String: rule.Note, Valid: true}
+				changed = true
+			}
+
+			if !changed {
+				continue
+			}
+
+			result.Changes = append(result.Changes, models.TransactionRuleChange{
+				TransactionID: transaction.ID,
+				RuleID:        rule.ID,
+				CategoryID:    transaction.CategoryID,
+				BudgetID:      transaction.BudgetID,
+				Note:          transaction.Note.String,
+			})
+
+			if dryRun {
+				continue
+			}
+
+			transaction.UpdatedAt = now
+			if err := repository.UpdateTransaction(transaction, db); err != nil {
+				return err
+			}
+		}
+
+		result.Updated = len(result.Changes)
+		return nil
+	}
+
+	if dryRun {
+		if err := apply(db); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	err = utils.DBTransaction(db, func(tx *sql.Tx) error {
+		return apply(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Updated > 0 {
+		// Log the run
+		go CreateLog(userID, fmt.Sprintf("Transaction rules updated %d transactions from %s to %s", result.Updated, startDate, endDate), db)
+
+		checkBudgetAlertsAsync(userID, db)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+)
+
+func money(t *testing.T, value string) *models.Money {
+	t.Helper()
+
+	amount, err := models.ParseMoney(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &amount
+}
+
+func TestRuleSetMatch(t *testing.T) {
+	groceries := models.Category{ID: uuid.New(), Name: "Groceries", Type: models.TransactionTypeExpense}
+	salary := models.Category{ID: uuid.New(), Name: "Salary", Type: models.TransactionTypeIncome}
+	unknownCategory := uuid.New()
+	checking := uuid.New()
+	savings := uuid.New()
+
+	rule := func(name string, priority int, conditions models.RuleConditions, categoryID uuid.UUID) models.TransactionRule {
+		return models.TransactionRule{
+			ID:             uuid.New(),
+			Name:           name,
+			Priority:       priority,
+			IsActive:       true,
+			RuleConditions: conditions,
+			RuleActions:    models.RuleActions{CategoryID: uuid.NullUUID{UUID: categoryID, Valid: categoryID != uuid.Nil}, Note: "set by " + name},
+		}
+	}
+
+	rules := []models.TransactionRule{
+		rule("low priority shop", 1, models.RuleConditions{DescriptionContains: "shop"}, groceries.ID),
+		rule("high priority shop", 10, models.RuleConditions{DescriptionContains: "SHOP"}, groceries.ID),
+		rule("same priority, loaded later", 10, models.RuleConditions{DescriptionContains: "shop"}, groceries.ID),
+		rule("unknown category", 100, models.RuleConditions{DescriptionContains: "payroll"}, unknownCategory),
+		rule("salary", 5, models.RuleConditions{DescriptionPattern: `^acme\s+(ltd|inc)\b`}, salary.ID),
+		rule("expense named salary", 50, models.RuleConditions{DescriptionContains: "acme"}, groceries.ID),
+		rule("bounded", 3, models.RuleConditions{MinAmount: money(t, "10"), MaxAmount: money(t, "20.5")}, uuid.Nil),
+		rule("savings only", 4, models.RuleConditions{AccountID: uuid.NullUUID{UUID: savings, Valid: true}, NoteContains: "Interest"}, uuid.Nil),
+		rule("broken pattern", 1000, models.RuleConditions{DescriptionPattern: `(`}, uuid.Nil),
+	}
+
+	set := newRuleSet(rules, []models.Category{groceries, salary})
+
+	tests := []struct {
+		name            string
+		accountID       uuid.UUID
+		transactionType models.TransactionType
+		description     string
+		amount          string
+		note            string
+		want            string
+	}{
+		{"highest priority first, ties keep their order", checking, models.TransactionTypeExpense, "Corner Shop", "99", "", "high priority shop"},
+		{"contains ignores case", checking, models.TransactionTypeExpense, "WORKSHOP TOOLS", "99", "", "high priority shop"},
+		{"rule with an unknown category is skipped", checking, models.TransactionTypeIncome, "Payroll run", "99", "", ""},
+		{"rule of the other type is skipped", checking, models.TransactionTypeIncome, "ACME Ltd wages", "99", "", "salary"},
+		{"either type without one", checking, "", "ACME Ltd wages", "99", "", "expense named salary"},
+		{"expense rule matches expenses", checking, models.TransactionTypeExpense, "ACME Ltd wages", "99", "", "expense named salary"},
+		{"pattern ignores case", checking, models.TransactionTypeIncome, "acme INC", "99", "", "salary"},
+		{"pattern is anchored as written", checking, models.TransactionTypeIncome, "paid by Acme Ltd", "99", "", ""},
+		{"pattern needs the word boundary", checking, models.TransactionTypeIncome, "Acme Incorporated", "99", "", ""},
+		{"minimum is inclusive", checking, models.TransactionTypeExpense, "Fuel", "10", "", "bounded"},
+		{"maximum is inclusive", checking, models.TransactionTypeExpense, "Fuel", "20.5", "", "bounded"},
+		{"below the minimum", checking, models.TransactionTypeExpense, "Fuel", "9.9999", "", ""},
+		{"above the maximum", checking, models.TransactionTypeExpense, "Fuel", "20.5001", "", ""},
+		{"account and note match", savings, models.TransactionTypeIncome, "Transfer", "1", "Monthly INTEREST", "savings only"},
+		{"other account", checking, models.TransactionTypeIncome, "Transfer", "1", "Monthly interest", ""},
+		{"note does not match", savings, models.TransactionTypeIncome, "Transfer", "1", "Monthly fee", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matched := set.match(test.accountID, test.transactionType, test.description, *money(t, test.amount), test.note)
+
+			got := ""
+			if matched != nil {
+				got = matched.Name
+			}
+			if got != test.want {
+				t.Fatalf("match() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewRuleSetSkipsInvalidPatterns(t *testing.T) {
+	rules := []models.TransactionRule{
+		{ID: uuid.New(), Name: "broken", RuleConditions: models.RuleConditions{DescriptionPattern: `[a-`}},
+		{ID: uuid.New(), Name: "valid", RuleConditions: models.RuleConditions{DescriptionPattern: `coffee`}},
+	}
+
+	set := newRuleSet(rules, nil)
+
+	if _, ok := set.patterns[rules[0].ID]; ok {
+		t.Fatal("the broken pattern was compiled")
+	}
+	if matched := set.match(uuid.New(), models.TransactionTypeExpense, "COFFEE", 1, ""); matched == nil || matched.Name != "valid" {
+		t.Fatalf("match() = %+v, want the valid rule", matched)
+	}
+}
+
+func TestValidateTransactionRulePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		valid   bool
+	}{
+		{`^acme\s+ltd$`, true},
+		{`(?s)line.+break`, true},
+		{`(?-i)Exact`, true},
+		{`caf\x{e9}`, true},
+		{`(`, false},
+		{`[a-`, false},
+		{`*`, false},
+		{`a{2,1}`, false},
+		{`(?z)`, false},
+	}
+
+	for _, test := range tests {
+		rule := &models.TransactionRule{
+			Name:           "Pattern",
+			RuleConditions: models.RuleConditions{DescriptionPattern: test.pattern},
+			RuleActions:    models.RuleActions{Note: "matched"},
+		}
+
+		err := validateTransactionRule(rule, uuid.New(), nil)
+		if test.valid {
+			if err != nil {
+				t.Errorf("validateTransactionRule(%q) = %v, want no error", test.pattern, err)
+			}
+			if _, err := compileRulePattern(rule.DescriptionPattern); err != nil {
+				t.Errorf("a validated pattern %q does not compile when run: %v", test.pattern, err)
+			}
+			continue
+		}
+
+		if !errors.Is(err, ErrInvalidTransactionRule) {
+			t.Errorf("validateTransactionRule(%q) = %v, want %v", test.pattern, err, ErrInvalidTransactionRule)
+			continue
+		}
+		if strings.Contains(err.Error(), rulePatternFlags) {
+			t.Errorf("validateTransactionRule(%q) = %q, mentions the flags added to the pattern", test.pattern, err)
+		}
+	}
+}
@@ -11,48 +11,77 @@ import (
 	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
 )
 
-func CreateTransaction(userID uuid.UUID, accountID uuid.UUID, categoryID uuid.UUID, budgetID uuid.NullUUID, description string, amount float64, transactionDate time.Time, note sql.NullString, db *sql.DB) (*models.Transaction, error) {
-	category, err := repository.GetCategoryByID(categoryID, db)
-	if err != nil {
-		return nil, err
-	}
+// CreateTransaction books an income or expense. The user's rules fill in the category, budget and
+// note when they are not given, and the category is required when no rule sets one.
+func CreateTransaction(userID uuid.UUID, accountID uuid.UUID, categoryID uuid.NullUUID, budgetID uuid.NullUUID, description string, amount models.Money, transactionDate time.Time, note sql.NullString, db *sql.DB) (*models.Transaction, error) {
+	var category *models.Category
+	var err error
+
+	if categoryID.Valid {
+		category, err = repository.GetCategoryByID(categoryID.UUID, userID, db)
+		if err != nil {
+			return nil, err
+		}
 
-	if category == nil {
-		return nil, sql.ErrNoRows
+		if category == nil {
+			return nil, sql.ErrNoRows
+		}
 	}
 
-	transactionType := models.TransactionType(category.Type)
+	if !categoryID.Valid || !budgetID.Valid || !note.Valid {
+		rules, err := loadRuleSet(userID, db)
+		if err != nil {
+			return nil, err
+		}
 
-	// Update account balance
-	account, err := repository.GetAccountByID(accountID, db)
-	if err != nil {
-		return nil, err
-	}
+		var transactionType models.TransactionType
+		if category != nil {
+			transactionType = category.Type
+		}
 
-	if account == nil {
-		return nil, sql.ErrNoRows
+		if rule := rules.match(accountID, transactionType, description, amount, note.String); rule != nil {
+			if !categoryID.Valid {
+				categoryID = rule.CategoryID
+			}
+			if !budgetID.Valid {
+				budgetID = rule.BudgetID
+			}
+			if !note.Valid && rule.Note != "" {
+				note = sql.NullString{String: rule.Note, Valid: true}
+			}
+		}
 	}
 
-	if transactionType == models.TransactionTypeIncome {
-		account.Balance += amount
-	} else {
-		account.Balance -= amount
+	if !categoryID.Valid {
+		return nil, ErrCategoryRequired
 	}
 
-	// Update budget if provided
-	var budget *models.Budget
-
-	if budgetID.Valid {
-		budget, err = repository.GetBudgetByID(budgetID.UUID, db)
+	if category == nil {
+		category, err = repository.GetCategoryByID(categoryID.UUID, userID, db)
 		if err != nil {
 			return nil, err
 		}
 
-		if budget == nil {
+		if category == nil {
 			return nil, sql.ErrNoRows
 		}
+	}
+
+	transactionType := models.TransactionType(category.Type)
+
+	// Update account balance
+	account, err := repository.GetAccountByID(accountID, userID, db)
+	if err != nil {
+		return nil, err
+	}
 
-		budget.Amount -= amount // Deduct new transaction amount from new budget
+	if account == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	// Budget spending is derived from transactions, so the budget only has to exist
+	if err := ensureBudgetExists(budgetID, userID, db); err != nil {
+		return nil, err
 	}
 
 	transaction := &models.Transaction{
@@ -63,6 +92,7 @@ func CreateTransaction(userID uuid.UUID, accountID uuid.UUID, categoryID uuid.UU
 		BudgetID:        budgetID,
 		Description:     description,
 		Amount:          amount,
+		Currency:        account.Currency,
 		Type:            transactionType,
 		TransactionDate: transactionDate,
 		Note:            note,
@@ -71,19 +101,7 @@ func CreateTransaction(userID uuid.UUID, accountID uuid.UUID, categoryID uuid.UU
 	}
 
 	err = utils.DBTransaction(db, func(tx *sql.Tx) error {
-		if err := repository.UpdateAccount(account, tx); err != nil {
-			return err
-		}
-
-		if err := repository.UpdateBudget(budget, tx); err != nil {
-			return err
-		}
-
-		// Create the transaction
-		if err := repository.CreateTransaction(transaction, tx); err != nil {
-			return err
-		}
-		return nil
+		return postTransaction(transaction, tx)
 	})
 	if err != nil {
 		return nil, err
@@ -92,15 +110,60 @@ func CreateTransaction(userID uuid.UUID, accountID uuid.UUID, categoryID uuid.UU
 	// Log the creation
 	go CreateLog(userID, fmt.Sprintf("New transaction '%s' created", transaction.Description), db)
 
+	checkBudgetAlertsAsync(userID, db)
+
 	return transaction, nil
 }
 
+// postTransaction applies an income or expense to its account balance and stores it. Both
+// manually created and recurring transactions are posted through it.
+func postTransaction(transaction *models.Transaction, tx *sql.Tx) error {
+	delta := balanceDelta(transaction.Type, transaction.Amount)
+	if err := repository.AdjustAccountBalance(transaction.AccountID, transaction.UserID, delta, transaction.UpdatedAt, tx); err != nil {
+		return err
+	}
+
+	return repository.CreateTransaction(transaction, tx)
+}
+
+// balanceDelta is the signed change an income or expense of amount makes to its account balance.
+func balanceDelta(transactionType models.TransactionType, amount models.Money) models.Money {
+	if transactionType == models.TransactionTypeIncome {
+		return amount
+	}
+	return amount.Neg()
+}
+
+func ensureBudgetExists(budgetID uuid.NullUUID, userID uuid.UUID, db *sql.DB) error {
+	if !budgetID.Valid {
+		return nil
+	}
+
+	budget, err := repository.GetBudgetByID(budgetID.UUID, userID, db)
+	if err != nil {
+		return err
+	}
+
+	if budget == nil {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetTransactions returns a page of the user's transactions. Date filters are read as days in the
+// user's time zone.
 func GetTransactions(userID uuid.UUID, page int, limit int, description string, categoryID string, accountID string, budgetID string, startDate string, endDate string, db *sql.DB) ([]models.Transaction, error) {
+	startDate, endDate, err := resolveDateFilters(userID, startDate, endDate, db)
+	if err != nil {
+		return nil, err
+	}
+
 	return repository.GetTransactionsByUserIDWithFilters(userID, page, limit, description, categoryID, accountID, budgetID, startDate, endDate, db)
 }
 
-func UpdateTransaction(id uuid.UUID, accountID uuid.UUID, categoryID uuid.UUID, budgetID uuid.NullUUID, description string, amount float64, transactionDate time.Time, note sql.NullString, db *sql.DB) (*models.Transaction, error) {
-	transaction, err := repository.GetTransactionByID(id, db)
+func UpdateTransaction(id uuid.UUID, userID uuid.UUID, accountID uuid.UUID, categoryID uuid.UUID, budgetID uuid.NullUUID, description string, amount models.Money, transactionDate time.Time, note sql.NullString, db *sql.DB) (*models.Transaction, error) {
+	transaction, err := repository.GetTransactionByID(id, userID, db)
 	if err != nil {
 		return nil, err
 	}
@@ -109,8 +172,12 @@ func UpdateTransaction(id uuid.UUID, accountID uuid.UUID, categoryID uuid.UUID,
 		return nil, sql.ErrNoRows
 	}
 
-	if transaction.CategoryID != categoryID {
-		category, err := repository.GetCategoryByID(categoryID, db)
+	if transaction.Type == models.TransactionTypeTransfer {
+		return nil, ErrTransferTransaction
+	}
+
+	if !transaction.CategoryID.Valid || transaction.CategoryID.UUID != categoryID {
+		category, err := repository.GetCategoryByID(categoryID, userID, db)
 		if err != nil {
 			return nil, err
 		}
@@ -119,119 +186,45 @@ func UpdateTransaction(id uuid.UUID, accountID uuid.UUID, categoryID uuid.UUID,
 		}
 	}
 
+	// Balances are moved by signed deltas inside the database transaction, so a transfer, import or
+	// recurring post committed in the meantime is not overwritten.
 	var (
-		oldAccountToUpdate *models.Account
-		newAccountToUpdate *models.Account
-		accountToUpdate    *models.Account
-		oldBudgetToUpdate  *models.Budget
-		newBudgetToUpdate  *models.Budget
+		oldAccountID    uuid.UUID
+		oldAccountDelta models.Money
+		newAccountDelta models.Money
 	)
 
 	if transaction.AccountID != accountID {
-		oldAccountID := transaction.AccountID
-		oldAmount := transaction.Amount
-		oldType := transaction.Type
-
-		// Get old account
-		oldAccount, err := repository.GetAccountByID(oldAccountID, db)
-		if err != nil {
-			return nil, err
-		}
-		if oldAccount == nil {
-			return nil, sql.ErrNoRows
-		}
-		// revert old transaction amount from old account balance
-		if oldType == models.TransactionTypeIncome {
-			oldAccount.Balance -= oldAmount
-		} else {
-			oldAccount.Balance += oldAmount
-		}
-		oldAccount.UpdatedAt = time.Now().In(utils.LOC)
-		oldAccountToUpdate = oldAccount
-
-		// Get new account
-		newAccount, err := repository.GetAccountByID(accountID, db)
+		newAccount, err := repository.GetAccountByID(accountID, userID, db)
 		if err != nil {
 			return nil, err
 		}
 		if newAccount == nil {
 			return nil, sql.ErrNoRows
 		}
-		// apply new amount to new account according to transaction.Type
-		if transaction.Type == models.TransactionTypeIncome {
-			newAccount.Balance += amount
-		} else {
-			newAccount.Balance -= amount
-		}
-		newAccount.UpdatedAt = time.Now().In(utils.LOC)
-		newAccountToUpdate = newAccount
+
+		// revert the old amount from the old account and apply the new amount to the new one
+		oldAccountID = transaction.AccountID
+		oldAccountDelta = balanceDelta(transaction.Type, transaction.Amount).Neg()
+		newAccountDelta = balanceDelta(transaction.Type, amount)
+		transaction.Currency = newAccount.Currency
 	} else if transaction.Amount != amount {
 		// Same account; only adjust by difference
-		difference := amount - transaction.Amount
-		account, err := repository.GetAccountByID(transaction.AccountID, db)
+		difference, err := amount.SubChecked(transaction.Amount)
 		if err != nil {
 			return nil, err
 		}
-		if account == nil {
-			return nil, sql.ErrNoRows
-		}
-		if transaction.Type == models.TransactionTypeIncome {
-			account.Balance += difference
-		} else {
-			account.Balance -= difference
-		}
-		account.UpdatedAt = time.Now().In(utils.LOC)
-		accountToUpdate = account
+		newAccountDelta = balanceDelta(transaction.Type, difference)
 	}
 
-	if transaction.BudgetID.Valid && (!budgetID.Valid || transaction.BudgetID.UUID != budgetID.UUID) {
-		oldBudgetID := transaction.BudgetID
-		oldAmount := transaction.Amount
-
-		// Revert old budget amount
-		if oldBudgetID.Valid {
-			oldBudget, err := repository.GetBudgetByID(oldBudgetID.UUID, db)
-			if err != nil {
-				return nil, err
-			}
-			if oldBudget == nil {
-				return nil, sql.ErrNoRows
-			}
-			oldBudget.Amount += oldAmount
-			oldBudget.UpdatedAt = time.Now().In(utils.LOC)
-			oldBudgetToUpdate = oldBudget
-		}
-
-		if budgetID.Valid {
-			newBudget, err := repository.GetBudgetByID(budgetID.UUID, db)
-			if err != nil {
-				return nil, err
-			}
-			if newBudget == nil {
-				return nil, sql.ErrNoRows
-			}
-			newBudget.Amount -= amount
-			newBudget.UpdatedAt = time.Now().In(utils.LOC)
-			newBudgetToUpdate = newBudget
-		}
-	} else if transaction.Amount != amount {
-		if budgetID.Valid {
-			budget, err := repository.GetBudgetByID(transaction.BudgetID.UUID, db)
-			if err != nil {
-				return nil, err
-			}
-			if budget == nil {
-				return nil, sql.ErrNoRows
-			}
-			difference := amount - transaction.Amount
-			budget.Amount -= difference
-			budget.UpdatedAt = time.Now().In(utils.LOC)
-			oldBudgetToUpdate = budget
+	if transaction.BudgetID != budgetID {
+		if err := ensureBudgetExists(budgetID, userID, db); err != nil {
+			return nil, err
 		}
 	}
 
 	transaction.AccountID = accountID
-	transaction.CategoryID = categoryID
+	transaction.CategoryID = uuid.NullUUID{UUID: categoryID, Valid: true}
 	transaction.BudgetID = budgetID
 	transaction.Description = description
 	transaction.Amount = amount
@@ -240,29 +233,13 @@ func UpdateTransaction(id uuid.UUID, accountID uuid.UUID, categoryID uuid.UUID,
 	transaction.UpdatedAt = time.Now().In(utils.LOC)
 
 	err = utils.DBTransaction(db, func(tx *sql.Tx) error {
-		if oldAccountToUpdate != nil {
-			if err := repository.UpdateAccount(oldAccountToUpdate, tx); err != nil {
-				return err
-			}
-		}
-		if newAccountToUpdate != nil {
-			if err := repository.UpdateAccount(newAccountToUpdate, tx); err != nil {
+		if oldAccountDelta != 0 {
+			if err := repository.AdjustAccountBalance(oldAccountID, userID, oldAccountDelta, transaction.UpdatedAt, tx); err != nil {
 				return err
 			}
 		}
-		if accountToUpdate != nil {
-			if err := repository.UpdateAccount(accountToUpdate, tx); err != nil {
-				return err
-			}
-		}
-
-		if oldBudgetToUpdate != nil {
-			if err := repository.UpdateBudget(oldBudgetToUpdate, tx); err != nil {
-				return err
-			}
-		}
-		if newBudgetToUpdate != nil {
-			if err := repository.UpdateBudget(newBudgetToUpdate, tx); err != nil {
+		if newAccountDelta != 0 {
+			if err := repository.AdjustAccountBalance(accountID, userID, newAccountDelta, transaction.UpdatedAt, tx); err != nil {
 				return err
 			}
 		}
@@ -281,12 +258,14 @@ func UpdateTransaction(id uuid.UUID, accountID uuid.UUID, categoryID uuid.UUID,
 	// Log the update
 	go CreateLog(transaction.UserID, fmt.Sprintf("Transaction '%s' updated", transaction.Description), db)
 
+	checkBudgetAlertsAsync(transaction.UserID, db)
+
 	return transaction, nil
 
 }
 
-func DeleteTransaction(id uuid.UUID, db *sql.DB) error {
-	transaction, err := repository.GetTransactionByID(id, db)
+func DeleteTransaction(id uuid.UUID, userID uuid.UUID, db *sql.DB) error {
+	transaction, err := repository.GetTransactionByID(id, userID, db)
 	if err != nil {
 		return err
 	}
@@ -295,41 +274,18 @@ func DeleteTransaction(id uuid.UUID, db *sql.DB) error {
 		return sql.ErrNoRows
 	}
 
-	account, err := repository.GetAccountByID(transaction.AccountID, db)
-	if err != nil {
-		return err
-	}
-
-	if transaction.Type == models.TransactionTypeIncome {
-		account.Balance -= transaction.Amount
-	} else {
-		account.Balance += transaction.Amount
-	}
-	account.UpdatedAt = time.Now().In(utils.LOC)
-
-	// Revert budget if it existed
-	var budget *models.Budget
-
-	if transaction.BudgetID.Valid {
-		budget, err = repository.GetBudgetByID(transaction.BudgetID.UUID, db)
-		if err != nil {
-			return err
-		}
-		budget.Amount += transaction.Amount // Add back the transaction amount
-		budget.UpdatedAt = time.Now().In(utils.LOC)
+	if transaction.Type == models.TransactionTypeTransfer {
+		return deleteTransfer(transaction, db)
 	}
 
 	err = utils.DBTransaction(db, func(tx *sql.Tx) error {
-		if err := repository.UpdateAccount(account, tx); err != nil {
-			return err
-		}
-
-		if err := repository.UpdateBudget(budget, tx); err != nil {
+		delta := balanceDelta(transaction.Type, transaction.Amount).Neg()
+		if err := repository.AdjustAccountBalance(transaction.AccountID, userID, delta, time.Now().In(utils.LOC), tx); err != nil {
 			return err
 		}
 
-		// Create the transaction
-		if err := repository.DeleteTransaction(id, db); err != nil {
+		// Delete the transaction
+		if err := repository.DeleteTransaction(id, userID, tx); err != nil {
 			return err
 		}
 
@@ -345,10 +301,48 @@ func DeleteTransaction(id uuid.UUID, db *sql.DB) error {
 	return nil
 }
 
+// GetAggregateData totals the user's income and expenses in their base currency. Date filters are
+// read as days in the user's time zone.
 func GetAggregateData(userID uuid.UUID, startDate string, endDate string, db *sql.DB) (map[string]interface{}, error) {
-	return repository.GetAggregateDataByUserID(userID, startDate, endDate, db)
+	startDate, endDate, err := resolveDateFilters(userID, startDate, endDate, db)
+	if err != nil {
+		return nil, err
+	}
+
+	baseCurrency, err := GetBaseCurrency(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := EnsureExchangeRates(userID, baseCurrency, db); err != nil {
+		return nil, err
+	}
+
+	return repository.GetAggregateDataByUserID(userID, baseCurrency, startDate, endDate, db)
 }
 
 func GetSpendingByCategory(userID uuid.UUID, db *sql.DB) ([]map[string]interface{}, error) {
-	return repository.GetSpendingByCategory(userID, db)
+	baseCurrency, err := GetBaseCurrency(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := EnsureExchangeRates(userID, baseCurrency, db); err != nil {
+		return nil, err
+	}
+
+	return repository.GetSpendingByCategory(userID, baseCurrency, db)
+}
+
+func GetEarningByCategory(userID uuid.UUID, db *sql.DB) ([]map[string]interface{}, error) {
+	baseCurrency, err := GetBaseCurrency(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := EnsureExchangeRates(userID, baseCurrency, db); err != nil {
+		return nil, err
+	}
+
+	return repository.GetEarningByCategory(userID, baseCurrency, db)
 }
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+var (
+	ErrTransferTransaction       = errors.New("transfers must be changed through the transfer endpoints")
+	ErrNotTransfer               = errors.New("transaction is not a transfer")
+	ErrSameAccountTransfer       = errors.New("source and destination accounts must be different")
+	ErrInvalidTransferAmount     = errors.New("transfer amount must be greater than zero")
+	ErrDestinationAmountRequired = errors.New("destinationAmount is required when the accounts use different currencies")
+)
+
+// resolveTransfer validates both accounts and works out how much the destination receives.
+// When both accounts share a currency the destination amount always equals the source amount.
+func resolveTransfer(userID uuid.UUID, fromAccountID uuid.UUID, toAccountID uuid.UUID, amount models.Money, destinationAmount *models.Money, db *sql.DB) (*models.Account, models.Money, error) {
+	if fromAccountID == toAccountID {
+		return nil, 0, ErrSameAccountTransfer
+	}
+
+	if !amount.IsPositive() {
+		return nil, 0, ErrInvalidTransferAmount
+	}
+
+	fromAccount, err := repository.GetAccountByID(fromAccountID, userID, db)
+	if err != nil {
+		return nil, 0, err
+	}
+	if fromAccount == nil {
+		return nil, 0, sql.ErrNoRows
+	}
+
+	toAccount, err := repository.GetAccountByID(toAccountID, userID, db)
+	if err != nil {
+		return nil, 0, err
+	}
+	if toAccount == nil {
+		return nil, 0, sql.ErrNoRows
+	}
+
+	if fromAccount.Currency == toAccount.Currency {
+		return fromAccount, amount, nil
+	}
+
+	if destinationAmount == nil {
+		return nil, 0, ErrDestinationAmountRequired
+	}
+
+	if !destinationAmount.IsPositive() {
+		return nil, 0, ErrInvalidTransferAmount
+	}
+
+	return fromAccount, *destinationAmount, nil
+}
+
+// applyTransferLegs debits the source account and credits the destination account.
+// With reverse set it undoes a previously applied transfer instead.
+func applyTransferLegs(transfer *models.Transaction, reverse bool, updatedAt time.Time, tx *sql.Tx) error {
+	sent := transfer.Amount.Neg()
+	received := *transfer.DestinationAmount
+	if reverse {
+		sent = sent.Neg()
+		received = received.Neg()
+	}
+
+	if err := repository.AdjustAccountBalance(transfer.AccountID, transfer.UserID, sent, updatedAt, tx); err != nil {
+		return err
+	}
+
+	return repository.AdjustAccountBalance(transfer.DestinationAccountID.UUID, transfer.UserID, received, updatedAt, tx)
+}
+
+func CreateTransfer(userID uuid.UUID, fromAccountID uuid.UUID, toAccountID uuid.UUID, amount models.Money, destinationAmount *models.Money, description string, transactionDate time.Time, note sql.NullString, db *sql.DB) (*models.Transaction, error) {
+	fromAccount, received, err := resolveTransfer(userID, fromAccountID, toAccountID, amount, destinationAmount, db)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := &models.Transaction{
+		ID:                   uuid.New(),
+		UserID:               userID,
+		AccountID:            fromAccountID,
+		Description:          description,
+		Amount:               amount,
+		Currency:             fromAccount.Currency,
+		Type:                 models.TransactionTypeTransfer,
+		TransactionDate:      transactionDate,
+		Note:                 note,
+		DestinationAccountID: uuid.NullUUID{UUID: toAccountID, Valid: true},
+		DestinationAmount:    &received,
+		CreatedAt:            time.Now().In(utils.LOC),
+		UpdatedAt:            time.Now().In(utils.LOC),
+	}
+
+	err = utils.DBTransaction(db, func(tx *sql.Tx) error {
+		if err := applyTransferLegs(transfer, false, transfer.UpdatedAt, tx); err != nil {
+			return err
+		}
+
+		return repository.CreateTransaction(transfer, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Log the creation
+	go CreateLog(userID, fmt.Sprintf("New transfer '%s' created", transfer.Description), db)
+
+	return transfer, nil
+}
+
+func UpdateTransfer(id uuid.UUID, userID uuid.UUID, fromAccountID uuid.UUID, toAccountID uuid.UUID, amount models.Money, destinationAmount *models.Money, description string, transactionDate time.Time, note sql.NullString, db *sql.DB) (*models.Transaction, error) {
+	transfer, err := repository.GetTransactionByID(id, userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if transfer == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	if transfer.Type != models.TransactionTypeTransfer {
+		return nil, ErrNotTransfer
+	}
+
+	fromAccount, received, err := resolveTransfer(userID, fromAccountID, toAccountID, amount, destinationAmount, db)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := *transfer
+
+	transfer.AccountID = fromAccountID
+	transfer.DestinationAccountID = uuid.NullUUID{UUID: toAccountID, Valid: true}
+	transfer.Amount = amount
+	transfer.DestinationAmount = &received
+	transfer.Currency = fromAccount.Currency
+	transfer.Description = description
+	transfer.TransactionDate = transactionDate
+	transfer.Note = note
+	transfer.UpdatedAt = time.Now().In(utils.LOC)
+
+	err = utils.DBTransaction(db, func(tx *sql.Tx) error {
+		// Reverse both legs of the stored transfer before applying the new one.
+		if err := applyTransferLegs(&previous, true, transfer.UpdatedAt, tx); err != nil {
+			return err
+		}
+
+		if err := applyTransferLegs(transfer, false, transfer.UpdatedAt, tx); err != nil {
+			return err
+		}
+
+		return repository.UpdateTransaction(transfer, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Log the update
+	go CreateLog(userID, fmt.Sprintf("Transfer '%s' updated", transfer.Description), db)
+
+	return transfer, nil
+}
+
+func deleteTransfer(transfer *models.Transaction, db *sql.DB) error {
+	err := utils.DBTransaction(db, func(tx *sql.Tx) error {
+		if err := applyTransferLegs(transfer, true, time.Now().In(utils.LOC), tx); err != nil {
+			return err
+		}
+
+		return repository.DeleteTransaction(transfer.ID, transfer.UserID, tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Log the deletion
+	go CreateLog(transfer.UserID, fmt.Sprintf("Transfer '%s' removed", transfer.Description), db)
+
+	return nil
+}
@@ -0,0 +1,419 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/pkg/totp"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotSetUp       = errors.New("start two-factor setup before enabling it")
+	ErrInvalidTwoFactorCode    = errors.New("two-factor code is invalid")
+	ErrInvalidChallenge        = errors.New("login challenge is invalid, expired or out of attempts, log in again")
+	ErrIncorrectPassword       = errors.New("password is incorrect")
+	ErrPasswordNotSet          = errors.New("account has no password, set one with a password reset first")
+)
+
+const (
+	twoFactorIssuer       = "Finance Tracker"
+	twoFactorSkew         = 1
+	challengeTTL          = 5 * time.Minute
+	maxChallengeAttempts  = 5
+	recoveryCodeCount     = 10
+	recoveryCodeGroupSize = 5
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// normalizeRecoveryCode makes recovery codes match regardless of case, dashes and spaces.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// generateRecoveryCodes returns codes formatted for the user, like "k3q7m-x2p9a", and the hashes
+// they are stored under.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for range recoveryCodeCount {
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(buf))[:2*recoveryCodeGroupSize]
+		codes = append(codes, code[:recoveryCodeGroupSize]+"-"+code[recoveryCodeGroupSize:])
+		hashes = append(hashes, utils.HashToken(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// checkTwoFactorCode validates a TOTP code against the user's secret and records its time step,
+// so that each code is accepted once.
+func checkTwoFactorCode(twoFactor *models.TwoFactor, code string, db *sql.DB, cfg *config.Config) error {
+	secret, err := utils.Decrypt(twoFactor.Secret, cfg)
+	if err != nil {
+		return err
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now(), twoFactorSkew)
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	used, err := repository.UseTwoFactorStep(twoFactor.UserID, step, time.Now().In(utils.LOC), db)
+	if err != nil {
+		return err
+	}
+
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+// getEnabledTwoFactor loads the user's two-factor settings, failing when 2FA is not enabled.
+func getEnabledTwoFactor(userID uuid.UUID, db *sql.DB) (*models.TwoFactor, error) {
+	twoFactor, err := repository.GetTwoFactorByUserID(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if twoFactor == nil || !twoFactor.IsEnabled() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	return twoFactor, nil
+}
+
+// SetupTwoFactor starts enrollment with a new secret. Enrollment only takes effect once a code
+// from the authenticator app is confirmed with EnableTwoFactor.
+func SetupTwoFactor(userID uuid.UUID, db *sql.DB, cfg *config.Config) (*models.TwoFactorSetup, error) {
+	user, err := repository.GetUserByID(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := utils.Encrypt(secret, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := repository.SaveTwoFactorEnrollment(&models.TwoFactor{
+		UserID:    userID,
+		Secret:    encrypted,
+		CreatedAt: time.Now().In(utils.LOC),
+		UpdatedAt: time.Now().In(utils.LOC),
+	}, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if !saved {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	return &models.TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(twoFactorIssuer, user.Email, secret),
+	}, nil
+}
+
+// EnableTwoFactor confirms enrollment with a code from the authenticator app and returns the
+// recovery codes. They are only ever shown here.
+func EnableTwoFactor(userID uuid.UUID, code string, db *sql.DB, cfg *config.Config) ([]string, error) {
+	twoFactor, err := repository.GetTwoFactorByUserID(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if twoFactor == nil {
+		return nil, ErrTwoFactorNotSetUp
+	}
+
+	if twoFactor.IsEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := utils.Decrypt(twoFactor.Secret, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now(), twoFactorSkew)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = utils.DBTransaction(db, func(tx *sql.Tx) error {
+		enabled, err := repository.EnableTwoFactor(userID, step, time.Now().In(utils.LOC), tx)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			return ErrTwoFactorAlreadyEnabled
+		}
+
+		return repository.ReplaceRecoveryCodes(userID, hashes, time.Now().In(utils.LOC), tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Log the change
+	go CreateLog(userID, "Two-factor authentication enabled", db)
+
+	return codes, nil
+}
+
+func GetTwoFactorStatus(userID uuid.UUID, db *sql.DB) (*models.TwoFactorStatus, error) {
+	status := &models.TwoFactorStatus{}
+
+	twoFactor, err := repository.GetTwoFactorByUserID(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if twoFactor == nil || !twoFactor.IsEnabled() {
+		return status, nil
+	}
+
+	status.Enabled = true
+	status.EnabledAt = twoFactor.EnabledAt
+
+	status.RecoveryCodesRemaining, err = repository.CountUnusedRecoveryCodes(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// DisableTwoFactor turns 2FA off after the user re-enters their password.
+func DisableTwoFactor(userID uuid.UUID, password string, db *sql.DB) error {
+	user, err := repository.GetUserByID(userID, db)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return sql.ErrNoRows
+	}
+
+	if user.Password == "" {
+		return ErrPasswordNotSet
+	}
+
+	if !utils.CheckPasswordHash(password, user.Password) {
+		return ErrIncorrectPassword
+	}
+
+	if _, err := getEnabledTwoFactor(userID, db); err != nil {
+		return err
+	}
+
+	err = utils.DBTransaction(db, func(tx *sql.Tx) error {
+		return repository.DeleteTwoFactor(userID, tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Log the change
+	go CreateLog(userID, "Two-factor authentication disabled", db)
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes, used or not, after checking a current code.
+func RegenerateRecoveryCodes(userID uuid.UUID, code string, db *sql.DB, cfg *config.Config) ([]string, error) {
+	twoFactor, err := getEnabledTwoFactor(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkTwoFactorCode(twoFactor, code, db, cfg); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := utils.DBTransaction(db, func(tx *sql.Tx) error {
+		return repository.ReplaceRecoveryCodes(userID, hashes, time.Now().In(utils.LOC), tx)
+	}); err != nil {
+		return nil, err
+	}
+
+	// Log the change
+	go CreateLog(userID, "Two-factor recovery codes regenerated", db)
+
+	return codes, nil
+}
+
+// startLogin finishes a login whose first factor was checked. Users with 2FA get a challenge
+// token to exchange for a session with VerifyTwoFactorLogin, everyone else gets a session. Failed
+// login counters are only cleared once a session starts.
+func startLogin(user *models.User, userAgent, ipAddress string, db *sql.DB, cfg *config.Config) (*models.LoginResult, error) {
+	twoFactor, err := repository.GetTwoFactorByUserID(user.ID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if twoFactor == nil || !twoFactor.IsEnabled() {
+		clearLoginFailures(user.Email, db)
+
+		tokens, err := createSession(user.ID, userAgent, ipAddress, db, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &models.LoginResult{User: user, Tokens: tokens}, nil
+	}
+
+	challenge := &models.TwoFactorChallenge{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().In(utils.LOC).Add(challengeTTL),
+		CreatedAt: time.Now().In(utils.LOC),
+	}
+
+	if err := repository.CreateTwoFactorChallenge(challenge, db); err != nil {
+		return nil, err
+	}
+
+	token, err := utils.GenerateChallengeToken(user.ID.String(), challenge.ID.String(), challenge.ExpiresAt, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResult{User: user, ChallengeToken: token, ChallengeExpiresAt: challenge.ExpiresAt}, nil
+}
+
+// VerifyTwoFactorLogin completes a two-factor login with either a TOTP code or a recovery code
+// and starts the session. Each challenge allows a few attempts before the login has to restart.
+func VerifyTwoFactorLogin(challengeToken, code, recoveryCode, userAgent, ipAddress string, db *sql.DB, cfg *config.Config) (*models.LoginResult, error) {
+	tokenUserID, tokenChallengeID, err := utils.ParseChallengeToken(challengeToken, cfg)
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+
+	challengeID, err := uuid.Parse(tokenChallengeID)
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+
+	challenge, err := repository.AttemptTwoFactorChallenge(challengeID, time.Now().In(utils.LOC), maxChallengeAttempts, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if challenge == nil || challenge.UserID.String() != tokenUserID {
+		return nil, ErrInvalidChallenge
+	}
+
+	user, err := repository.GetUserByID(challenge.UserID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, ErrInvalidChallenge
+	}
+
+	if err := checkLoginAllowed(user.Email, ipAddress, db); err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := getEnabledTwoFactor(user.ID, db)
+	if errors.Is(err, ErrTwoFactorNotEnabled) {
+		return nil, ErrInvalidChallenge
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if recoveryCode != "" {
+		used, err := repository.UseRecoveryCode(user.ID, utils.HashToken(normalizeRecoveryCode(recoveryCode)), time.Now().In(utils.LOC), db)
+		if err != nil {
+			return nil, err
+		}
+		if !used {
+			err = ErrInvalidTwoFactorCode
+		} else {
+			// Log the recovery code use
+			go CreateLog(user.ID, "Logged in with a two-factor recovery code", db)
+		}
+	} else {
+		err = checkTwoFactorCode(twoFactor, code, db, cfg)
+	}
+
+	// Wrong codes count as failed logins, so guessing codes across many challenges gets locked
+	// out like guessing passwords.
+	if errors.Is(err, ErrInvalidTwoFactorCode) {
+		recordLoginFailure(user.Email, ipAddress, db, cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	completed, err := repository.CompleteTwoFactorChallenge(challenge.ID, time.Now().In(utils.LOC), db)
+	if err != nil {
+		return nil, err
+	}
+
+	if !completed {
+		return nil, ErrInvalidChallenge
+	}
+
+	clearLoginFailures(user.Email, db)
+
+	tokens, err := createSession(user.ID, userAgent, ipAddress, db, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResult{User: user, Tokens: tokens}, nil
+}
+
+// CleanupTwoFactorChallenges deletes login challenges that expired more than a day ago.
+func CleanupTwoFactorChallenges(db *sql.DB) {
+	deleted, err := repository.DeleteStaleTwoFactorChallenges(time.Now().In(utils.LOC).AddDate(0, 0, -1), db)
+	if err != nil {
+		log.Println("Error deleting stale two-factor challenges:", err)
+		return
+	}
+
+	log.Printf("Deleted %d stale two-factor challenges", deleted)
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+// ExportUserData collects everything stored about the user. Categories include the system
+// categories that their transactions can refer to.
+func ExportUserData(userID uuid.UUID, db *sql.DB) (*models.UserDataExport, error) {
+	user, err := repository.GetUserByID(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	export := &models.UserDataExport{
+		ExportedAt: time.Now().In(utils.LOC),
+		User:       user,
+	}
+
+	if export.Preferences, err = userPreferences(user, db); err != nil {
+		return nil, err
+	}
+	if export.Identities, err = repository.GetUserIdentitiesByUserID(userID, db); err != nil {
+		return nil, err
+	}
+	if export.Accounts, err = repository.GetAccountsByUserID(userID, db); err != nil {
+		return nil, err
+	}
+	if export.Categories, err = repository.GetCategoriesByUserID(userID, db); err != nil {
+		return nil, err
+	}
+	if export.Transactions, err = repository.GetTransactionsByUserID(userID, db); err != nil {
+		return nil, err
+	}
+	if export.Budgets, err = repository.GetBudgetsByUserID(userID, db); err != nil {
+		return nil, err
+	}
+	if export.RecurringTransactions, err = repository.GetRecurringTransactionsByUserID(userID, db); err != nil {
+		return nil, err
+	}
+	if export.ImportProfiles, err = repository.GetImportProfilesByUserID(userID, db); err != nil {
+		return nil, err
+	}
+	if export.TransactionRules, err = repository.GetTransactionRulesByUserID(userID, db); err != nil {
+		return nil, err
+	}
+	if export.Logs, err = repository.GetAllLogsByUserID(userID, db); err != nil {
+		return nil, err
+	}
+
+	// Log the export
+	go CreateLog(userID, "Personal data exported", db)
+
+	return export, nil
+}
+
+// WriteUserDataZip writes the export as a ZIP archive with one JSON file per kind of data.
+func WriteUserDataZip(export *models.UserDataExport, writer io.Writer) error {
+	archive := zip.NewWriter(writer)
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"user.json", export.User},
+		{"preferences.json", export.Preferences},
+		{"identities.json", export.Identities},
+		{"accounts.json", export.Accounts},
+		{"categories.json", export.Categories},
+		{"transactions.json", export.Transactions},
+		{"budgets.json", export.Budgets},
+		{"recurring_transactions.json", export.RecurringTransactions},
+		{"import_profiles.json", export.ImportProfiles},
+		{"transaction_rules.json", export.TransactionRules},
+		{"logs.json", export.Logs},
+	}
+
+	for _, file := range files {
+		entry, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(entry)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
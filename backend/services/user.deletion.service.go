@@ -0,0 +1,154 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/pkg/mailer"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+var (
+	ErrDeletionAlreadyScheduled = errors.New("account is already scheduled for deletion")
+	ErrDeletionNotScheduled     = errors.New("account is not scheduled for deletion")
+)
+
+// ScheduleUserDeletion confirms the user's password and schedules their account for deletion
+// once the grace period has passed. All of their sessions are signed out, and signing in again
+// before the deletion date allows them to restore the account. Without a grace period the account
+// is deleted right away and the returned time is nil.
+func ScheduleUserDeletion(userID uuid.UUID, password string, db *sql.DB, cfg *config.Config) (*time.Time, error) {
+	user, err := repository.GetUserByID(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	if user.Password == "" {
+		return nil, ErrPasswordNotSet
+	}
+
+	if !utils.CheckPasswordHash(password, user.Password) {
+		return nil, ErrIncorrectPassword
+	}
+
+	if user.DeletionScheduledAt != nil {
+		return nil, ErrDeletionAlreadyScheduled
+	}
+
+	if cfg.App.AccountDeletionGracePeriod <= 0 {
+		return nil, deleteUser(user, db, cfg)
+	}
+
+	now := time.Now().In(utils.LOC)
+	deletionAt := now.Add(cfg.App.AccountDeletionGracePeriod)
+
+	err = utils.DBTransaction(db, func(tx *sql.Tx) error {
+		if err := repository.SetUserDeletionScheduledAt(userID, &deletionAt, tx); err != nil {
+			return err
+		}
+
+		return repository.RevokeSessionsByUserID(userID, uuid.NullUUID{}, now, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sendMail(mailer.Message{
+		To:      user.Email,
+		Subject: "Your account will be deleted",
+		Body: fmt.Sprintf("Hi %s,\n\nAs requested, your Finance Tracker account and all of its data will be permanently deleted on %s.\n\nChanged your mind? Sign in before then and restore your account from your profile.\n",
+			user.Name, deletionAt.Format("2 January 2006 15:04 MST")),
+	}, cfg)
+
+	// Log the request
+	go CreateLog(userID, fmt.Sprintf("Account deletion scheduled for %s", deletionAt.Format("2006-01-02 15:04")), db)
+
+	return &deletionAt, nil
+}
+
+// CancelUserDeletion keeps an account that is scheduled for deletion.
+func CancelUserDeletion(userID uuid.UUID, db *sql.DB) error {
+	user, err := repository.GetUserByID(userID, db)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return sql.ErrNoRows
+	}
+
+	if user.DeletionScheduledAt == nil {
+		return ErrDeletionNotScheduled
+	}
+
+	if err := repository.SetUserDeletionScheduledAt(userID, nil, db); err != nil {
+		return err
+	}
+
+	// Log the restore
+	go CreateLog(userID, "Account deletion cancelled", db)
+
+	return nil
+}
+
+// deleteUser permanently removes the user and all of their data. Transactions, budgets and
+// recurring transactions reference categories with ON DELETE RESTRICT, so they are deleted before
+// the cascade from the users table removes the categories.
+func deleteUser(user *models.User, db *sql.DB, cfg *config.Config) error {
+	err := utils.DBTransaction(db, func(tx *sql.Tx) error {
+		if err := repository.DeleteTransactionsByUserID(user.ID, tx); err != nil {
+			return err
+		}
+
+		if err := repository.DeleteRecurringTransactionsByUserID(user.ID, tx); err != nil {
+			return err
+		}
+
+		if err := repository.DeleteBudgetsByUserID(user.ID, tx); err != nil {
+			return err
+		}
+
+		return repository.DeleteUser(user.ID, tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	clearLoginFailures(user.Email, db)
+
+	sendMail(mailer.Message{
+		To:      user.Email,
+		Subject: "Your account has been deleted",
+		Body:    fmt.Sprintf("Hi %s,\n\nYour Finance Tracker account and all of its data have been permanently deleted.\n", user.Name),
+	}, cfg)
+
+	log.Printf("Deleted user %s", user.ID)
+
+	return nil
+}
+
+// PurgeDeletedUsers deletes the accounts whose grace period has ended.
+func PurgeDeletedUsers(db *sql.DB, cfg *config.Config) {
+	users, err := repository.GetUsersDueForDeletion(time.Now().In(utils.LOC), db)
+	if err != nil {
+		log.Println("Error getting users due for deletion:", err)
+		return
+	}
+
+	for i := range users {
+		if err := deleteUser(&users[i], db, cfg); err != nil {
+			log.Printf("Error deleting user %s: %v", users[i].ID, err)
+		}
+	}
+}
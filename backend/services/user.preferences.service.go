@@ -0,0 +1,178 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+var (
+	ErrInvalidTimezone   = errors.New("timezone must be an IANA time zone such as Asia/Kolkata")
+	ErrInvalidLocale     = errors.New("locale must be a language tag such as en or en-IN")
+	ErrInvalidDateFormat = errors.New("date format must be one of YYYY-MM-DD, DD/MM/YYYY, MM/DD/YYYY, DD.MM.YYYY or DD-MM-YYYY")
+	ErrInvalidWeekStart  = errors.New("week start must be between 0 (Sunday) and 6 (Saturday)")
+	ErrNoDefaultAccount  = errors.New("account ID is required when no default account is set")
+)
+
+const (
+	defaultLocale    = "en-IN"
+	defaultWeekStart = int(time.Monday)
+)
+
+// isValidTimezone reports whether name is an IANA time zone. The server's local zone is not
+// accepted since it differs between deployments.
+func isValidTimezone(name string) bool {
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// userPreferences returns the user's preferences, with the server defaults for users that never
+// changed them.
+func userPreferences(user *models.User, db interfaces.SqlExecutor) (*models.UserPreferences, error) {
+	preferences, err := repository.GetUserPreferences(user.ID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if preferences == nil {
+		preferences = &models.UserPreferences{
+			Timezone:   utils.LOC.String(),
+			Locale:     defaultLocale,
+			DateFormat: models.DateFormatISO,
+			WeekStart:  defaultWeekStart,
+		}
+	}
+
+	preferences.BaseCurrency = user.BaseCurrency
+
+	return preferences, nil
+}
+
+func GetUserPreferences(userID uuid.UUID, db *sql.DB) (*models.UserPreferences, error) {
+	user, err := repository.GetUserByID(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	return userPreferences(user, db)
+}
+
+// UpdateUserPreferences validates and stores the changed preferences. The default account has to
+// be one of the user's accounts.
+func UpdateUserPreferences(userID uuid.UUID, update models.UserPreferencesUpdate, db *sql.DB) (*models.UserPreferences, error) {
+	user, err := repository.GetUserByID(userID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	preferences, err := userPreferences(user, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Timezone != nil {
+		if !isValidTimezone(*update.Timezone) {
+			return nil, ErrInvalidTimezone
+		}
+		preferences.Timezone = *update.Timezone
+	}
+
+	if update.BaseCurrency != nil {
+		if !update.BaseCurrency.IsValid() {
+			return nil, ErrInvalidCurrency
+		}
+		preferences.BaseCurrency = *update.BaseCurrency
+	}
+
+	if update.Locale != nil {
+		locale := strings.TrimSpace(*update.Locale)
+		if !models.IsValidLocale(locale) {
+			return nil, ErrInvalidLocale
+		}
+		preferences.Locale = locale
+	}
+
+	if update.DateFormat != nil {
+		if !update.DateFormat.IsValid() {
+			return nil, ErrInvalidDateFormat
+		}
+		preferences.DateFormat = *update.DateFormat
+	}
+
+	if update.WeekStart != nil {
+		if *update.WeekStart < 0 || *update.WeekStart > 6 {
+			return nil, ErrInvalidWeekStart
+		}
+		preferences.WeekStart = *update.WeekStart
+	}
+
+	if update.DefaultAccountID != nil {
+		if update.DefaultAccountID.Valid {
+			account, err := repository.GetAccountByID(update.DefaultAccountID.UUID, userID, db)
+			if err != nil {
+				return nil, err
+			}
+			if account == nil {
+				return nil, sql.ErrNoRows
+			}
+		}
+		preferences.DefaultAccountID = *update.DefaultAccountID
+	}
+
+	now := time.Now().In(utils.LOC)
+	preferences.UpdatedAt = &now
+
+	err = utils.DBTransaction(db, func(tx *sql.Tx) error {
+		if err := repository.UpsertUserPreferences(userID, preferences, tx); err != nil {
+			return err
+		}
+
+		if preferences.BaseCurrency == user.BaseCurrency {
+			return nil
+		}
+
+		user.BaseCurrency = preferences.BaseCurrency
+		return repository.UpdateUser(user, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Log the change
+	go CreateLog(userID, fmt.Sprintf("Preferences updated (timezone %s, base currency %s)", preferences.Timezone, preferences.BaseCurrency), db)
+
+	return preferences, nil
+}
+
+// GetDefaultAccountID returns the account that new transactions use when none is given.
+func GetDefaultAccountID(userID uuid.UUID, db *sql.DB) (uuid.UUID, error) {
+	preferences, err := repository.GetUserPreferences(userID, db)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if preferences == nil || !preferences.DefaultAccountID.Valid {
+		return uuid.Nil, ErrNoDefaultAccount
+	}
+
+	return preferences.DefaultAccountID.UUID, nil
+}
@@ -3,18 +3,27 @@ package services
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/pkg/mailer"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
 )
 
+var (
+	ErrInvalidName  = errors.New("name must be between 1 and 100 characters")
+	ErrInvalidEmail = errors.New("email address is invalid")
+	ErrEmailInUse   = errors.New("email address is already used by another account")
+)
+
 func CheckUserExistsByEmail(email string, db *sql.DB) (bool, error) {
 	existingUser, err := repository.GetUserByEmail(email, db)
-	if err != nil {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return false, err
 	}
 
@@ -25,114 +34,90 @@ func CheckUserExistsByEmail(email string, db *sql.DB) (bool, error) {
 	return false, nil
 }
 
-func Register(name, email, password string, db *sql.DB, cfg *config.Config) (*models.User, string, error) {
+// Register creates an email/password account and emails a verification link. A session is only
+// started right away when REQUIRE_EMAIL_VERIFICATION is off, otherwise the returned tokens are nil.
+func Register(name, email, password, userAgent, ipAddress string, db *sql.DB, cfg *config.Config) (*models.User, *models.AuthTokens, error) {
 	exists, err := CheckUserExistsByEmail(email, db)
 
 	if err != nil {
-		return nil, "", err
+		return nil, nil, err
 	}
 
 	if exists {
-		return nil, "", errors.New("user already exists")
+		return nil, nil, errors.New("user already exists")
 	}
 
 	hashedPassword, err := utils.HashPassword(password)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, err
 	}
 
 	user := &models.User{
-		ID:        uuid.New(),
-		Name:      name,
-		Email:     email,
-		Password:  hashedPassword,
-		Provider:  models.AuthProviderEmail,
-		CreatedAt: time.Now().In(utils.LOC),
+		ID:           uuid.New(),
+		Name:         name,
+		Email:        email,
+		Password:     hashedPassword,
+		Provider:     models.AuthProviderEmail,
+		BaseCurrency: models.DefaultCurrency,
+		CreatedAt:    time.Now().In(utils.LOC),
 	}
 
 	err = repository.CreateUser(user, db)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, err
 	}
 
 	// Log the registration
 	go CreateLog(user.ID, "User registered", db)
 
-	token, expiresAt, err := utils.GenerateToken(user.ID.String(), cfg)
-	if err != nil {
-		return nil, "", err
+	if err := SendVerificationEmail(user, db, cfg); err != nil {
+		return nil, nil, err
 	}
 
-	jwtToken := models.JwtToken{
-		ID:        uuid.New(),
-		UserID:    user.ID,
-		Token:     token,
-		ExpiresAt: expiresAt,
-		CreatedAt: time.Now().In(utils.LOC),
+	// Without a verified email the user has to follow the link before a session can start.
+	if cfg.App.RequireVerifiedEmail {
+		return user, nil, nil
 	}
 
-	repository.CreateJwtToken(db, &jwtToken)
-
-	// Log the registration
-	go CreateLog(user.ID, "JWT token created", db)
-
-	return user, token, nil
-}
-
-func Login(email, password string, db *sql.DB, cfg *config.Config) (*models.User, string, error) {
-	user, err := repository.GetUserByEmail(email, db)
-	if err != nil {
-		return nil, "", errors.New("invalid email or password")
-	}
-
-	if !utils.CheckPasswordHash(password, user.Password) {
-		return nil, "", errors.New("invalid email or password")
-	}
-
-	// Log the login
-	go CreateLog(user.ID, "User logged in", db)
-
-	jwtToken, err := repository.GetJwtTokenByUserID(db, user.ID)
+	tokens, err := createSession(user.ID, userAgent, ipAddress, db, cfg)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, err
 	}
 
-	if jwtToken != nil && jwtToken.ExpiresAt.After(time.Now().In(utils.LOC)) {
-		return user, jwtToken.Token, nil
-	}
-
-	if jwtToken != nil && jwtToken.ExpiresAt.Before(time.Now().In(utils.LOC)) {
-		err := repository.DeleteJwtTokenByUserID(db, user.ID)
-		if err != nil {
-			return nil, "", err
-		}
+	return user, tokens, nil
+}
 
-		// Log the deletion
-		go CreateLog(user.ID, "JWT token deleted", db)
+// Login checks the user's password. Users with two-factor authentication get a challenge to
+// complete instead of a session. Repeated failures for the email or IP are slowed down and
+// eventually locked out, see checkLoginAllowed.
+func Login(email, password, userAgent, ipAddress string, db *sql.DB, cfg *config.Config) (*models.LoginResult, error) {
+	if err := checkLoginAllowed(email, ipAddress, db); err != nil {
+		return nil, err
 	}
 
-	token, expiresAt, err := utils.GenerateToken(user.ID.String(), cfg)
+	user, err := repository.GetUserByEmail(email, db)
 	if err != nil {
-		return nil, "", err
+		recordLoginFailure(email, ipAddress, db, cfg)
+		return nil, errors.New("invalid email or password")
 	}
 
-	newJwtToken := models.JwtToken{
-		ID:        uuid.New(),
-		UserID:    user.ID,
-		Token:     token,
-		ExpiresAt: expiresAt,
-		CreatedAt: time.Now().In(utils.LOC),
+	if !utils.CheckPasswordHash(password, user.Password) {
+		recordLoginFailure(email, ipAddress, db, cfg)
+		return nil, errors.New("invalid email or password")
 	}
 
-	repository.CreateJwtToken(db, &newJwtToken)
+	if cfg.App.RequireVerifiedEmail && !user.IsEmailVerified() {
+		return nil, ErrEmailVerificationRequired
+	}
 
-	// Log the registration
-	go CreateLog(user.ID, "JWT token created", db)
+	// Log the login
+	go CreateLog(user.ID, "User logged in", db)
 
-	return user, token, nil
+	return startLogin(user, userAgent, ipAddress, db, cfg)
 }
 
-func ChangePassword(userID uuid.UUID, currentPassword, newPassword string, db *sql.DB) error {
+// ChangePassword sets a new password and signs out every other session of the user.
+func ChangePassword(userID uuid.UUID, sessionID uuid.UUID, currentPassword, newPassword string, db *sql.DB) error {
 	user, err := repository.GetUserByID(userID, db)
 	if err != nil {
 		return errors.New("user not found")
@@ -154,6 +139,10 @@ func ChangePassword(userID uuid.UUID, currentPassword, newPassword string, db *s
 		return err
 	}
 
+	if err := repository.RevokeSessionsByUserID(user.ID, uuid.NullUUID{UUID: sessionID, Valid: true}, time.Now().In(utils.LOC), db); err != nil {
+		return err
+	}
+
 	// Log the password change
 	go CreateLog(user.ID, "User changed password", db)
 
@@ -164,64 +153,136 @@ func GetProfile(userID uuid.UUID, db *sql.DB) (*models.User, error) {
 	return repository.GetUserByID(userID, db)
 }
 
-func GoogleLogin(email, fullName string, db *sql.DB, cfg *config.Config) (*models.User, string, error) {
-	user, err := repository.GetUserByEmail(email, db)
+// UpdateProfile changes the user's name and email address. Changing the email needs the password
+// and marks the new address unverified: a verification link is sent to it and the old address is
+// told about the change. Nil values are left as they are.
+func UpdateProfile(userID uuid.UUID, name *string, email *string, password string, db *sql.DB, cfg *config.Config) (*models.User, error) {
+	user, err := repository.GetUserByID(userID, db)
 	if err != nil {
-		// User does not exist, create a new user
-		user = &models.User{
-			ID:        uuid.New(),
-			Name:      fullName,
-			Email:     email,
-			Provider:  models.AuthProviderGoogle,
-			CreatedAt: time.Now().In(utils.LOC),
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	if name != nil {
+		trimmed := strings.TrimSpace(*name)
+		if trimmed == "" || len([]rune(trimmed)) > 100 {
+			return nil, ErrInvalidName
+		}
+		user.Name = trimmed
+	}
+
+	oldEmail := user.Email
+	emailChanged := email != nil && strings.TrimSpace(*email) != user.Email
+
+	if emailChanged {
+		if user.Password == "" {
+			return nil, ErrPasswordNotSet
 		}
 
-		if err := repository.CreateUser(user, db); err != nil {
-			return nil, "", err
+		if !utils.CheckPasswordHash(password, user.Password) {
+			return nil, ErrIncorrectPassword
+		}
+
+		newEmail := strings.TrimSpace(*email)
+		if !strings.Contains(newEmail, "@") {
+			return nil, ErrInvalidEmail
+		}
+
+		exists, err := CheckUserExistsByEmail(newEmail, db)
+		if err != nil {
+			return nil, err
 		}
-		// Log the registration
-		go CreateLog(user.ID, "User registered with Google", db)
+		if exists {
+			return nil, ErrEmailInUse
+		}
+
+		user.Email = newEmail
+		user.EmailVerifiedAt = nil
 	}
 
-	// Log the login
-	go CreateLog(user.ID, "User logged in with Google", db)
+	err = utils.DBTransaction(db, func(tx *sql.Tx) error {
+		if err := repository.UpdateUser(user, tx); err != nil {
+			return err
+		}
+
+		if !emailChanged {
+			return nil
+		}
+
+		if err := repository.UpdateUserEmail(user.ID, user.Email, tx); err != nil {
+			return err
+		}
+
+		// Reset links sent to the old address must not work for the new one.
+		return repository.InvalidateUserTokens(user.ID, models.UserTokenPasswordReset, time.Now().In(utils.LOC), tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if emailChanged {
+		if err := SendVerificationEmail(user, db, cfg); err != nil {
+			return nil, err
+		}
+
+		sendMail(mailer.Message{
+			To:      oldEmail,
+			Subject: "Your email address was changed",
+			Body: fmt.Sprintf("Hi %s,\n\nThe email address of your Finance Tracker account was changed to %s. If you did not make this change, reset your password and contact support.\n",
+				user.Name, user.Email),
+		}, cfg)
 
-	jwtToken, err := repository.GetJwtTokenByUserID(db, user.ID)
+		// Log the change
+		go CreateLog(user.ID, fmt.Sprintf("Email address changed from %s to %s", oldEmail, user.Email), db)
+	}
+
+	if name != nil {
+		// Log the change
+		go CreateLog(user.ID, "Name updated", db)
+	}
+
+	return user, nil
+}
+
+// GetBaseCurrency returns the currency the user's totals and reports are expressed in.
+func GetBaseCurrency(userID uuid.UUID, db *sql.DB) (models.Currency, error) {
+	user, err := repository.GetUserByID(userID, db)
 	if err != nil {
-		return nil, "", err
+		return "", err
 	}
 
-	if jwtToken != nil && jwtToken.ExpiresAt.After(time.Now().In(utils.LOC)) {
-		return user, jwtToken.Token, nil
+	if user == nil {
+		return "", sql.ErrNoRows
 	}
 
-	if jwtToken != nil && jwtToken.ExpiresAt.Before(time.Now().In(utils.LOC)) {
-		err := repository.DeleteJwtTokenByUserID(db, user.ID)
-		if err != nil {
-			return nil, "", err
-		}
+	return user.BaseCurrency, nil
+}
 
-		// Log the deletion
-		go CreateLog(user.ID, "JWT token deleted", db)
+func UpdateBaseCurrency(userID uuid.UUID, currency models.Currency, db *sql.DB) (*models.User, error) {
+	if !currency.IsValid() {
+		return nil, ErrInvalidCurrency
 	}
 
-	token, expiresAt, err := utils.GenerateToken(user.ID.String(), cfg)
+	user, err := repository.GetUserByID(userID, db)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	newJwtToken := models.JwtToken{
-		ID:        uuid.New(),
-		UserID:    user.ID,
-		Token:     token,
-		ExpiresAt: expiresAt,
-		CreatedAt: time.Now().In(utils.LOC),
+	if user == nil {
+		return nil, sql.ErrNoRows
 	}
 
-	repository.CreateJwtToken(db, &newJwtToken)
+	user.BaseCurrency = currency
 
-	// Log the registration
-	go CreateLog(user.ID, "JWT token created", db)
+	if err := repository.UpdateUser(user, db); err != nil {
+		return nil, err
+	}
+
+	// Log the change
+	go CreateLog(user.ID, fmt.Sprintf("Base currency changed to %s", currency), db)
 
-	return user, token, nil
+	return user, nil
 }
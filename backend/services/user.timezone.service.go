@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+var ErrInvalidDateFilter = errors.New("dates must be in YYYY-MM-DD or RFC 3339 form")
+
+// userLocation returns the time zone the user's calendar dates are resolved in. Users without
+// preferences, or whose preferences cannot be loaded, use the server's zone.
+func userLocation(userID uuid.UUID, db interfaces.SqlExecutor) *time.Location {
+	preferences, err := repository.GetUserPreferences(userID, db)
+	if err != nil {
+		log.Printf("Error getting preferences for user %s, using the server time zone: %v", userID, err)
+		return utils.LOC
+	}
+
+	if preferences == nil {
+		return utils.LOC
+	}
+
+	return preferences.Location()
+}
+
+// todayIn returns the current calendar date in loc.
+func todayIn(loc *time.Location) time.Time {
+	return dateOnly(time.Now().In(loc))
+}
+
+// UserToday returns the current calendar date in the user's time zone.
+func UserToday(userID uuid.UUID, db interfaces.SqlExecutor) time.Time {
+	return todayIn(userLocation(userID, db))
+}
+
+// parseDateFilter reads a date filter as a calendar date. Timestamps are converted into loc first
+// so that an instant close to midnight falls on the user's local date.
+func parseDateFilter(value string, loc *time.Location) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+
+	instant, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, ErrInvalidDateFilter
+	}
+
+	return dateOnly(instant.In(loc)), nil
+}
+
+// resolveDateFilters turns optional start and end date filters into YYYY-MM-DD dates in the user's
+// time zone. Empty filters stay empty.
+func resolveDateFilters(userID uuid.UUID, startDate string, endDate string, db interfaces.SqlExecutor) (string, string, error) {
+	if startDate == "" && endDate == "" {
+		return "", "", nil
+	}
+
+	loc := userLocation(userID, db)
+	dates := []string{startDate, endDate}
+
+	for i, value := range dates {
+		if value == "" {
+			continue
+		}
+
+		date, err := parseDateFilter(value, loc)
+		if err != nil {
+			return "", "", err
+		}
+		dates[i] = date.Format("2006-01-02")
+	}
+
+	return dates[0], dates[1], nil
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/interfaces"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/models"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/pkg/mailer"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/repository"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
+)
+
+var (
+	ErrInvalidUserToken          = errors.New("link is invalid or has expired")
+	ErrEmailVerificationRequired = errors.New("verify your email address before logging in")
+)
+
+const (
+	emailVerificationTTL = 48 * time.Hour
+	passwordResetTTL     = time.Hour
+)
+
+// issueUserToken creates a token for the purpose and invalidates any earlier unused one, so only
+// the newest link in the user's inbox works.
+func issueUserToken(userID uuid.UUID, purpose models.UserTokenPurpose, ttl time.Duration, db *sql.DB) (string, error) {
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().In(utils.LOC)
+
+	err = utils.DBTransaction(db, func(tx *sql.Tx) error {
+		if err := repository.InvalidateUserTokens(userID, purpose, now, tx); err != nil {
+			return err
+		}
+
+		return repository.CreateUserToken(&models.UserToken{
+			ID:        uuid.New(),
+			UserID:    userID,
+			Purpose:   purpose,
+			TokenHash: utils.HashToken(token),
+			ExpiresAt: now.Add(ttl),
+			CreatedAt: now,
+		}, tx)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// consumeUserToken redeems a token for the purpose. It fails with ErrInvalidUserToken when the
+// token is unknown, expired or already used.
+func consumeUserToken(token string, purpose models.UserTokenPurpose, db interfaces.SqlExecutor) (*models.UserToken, error) {
+	userToken, err := repository.ConsumeUserToken(utils.HashToken(token), purpose, time.Now().In(utils.LOC), db)
+	if err != nil {
+		return nil, err
+	}
+
+	if userToken == nil {
+		return nil, ErrInvalidUserToken
+	}
+
+	return userToken, nil
+}
+
+// SendVerificationEmail emails the user a link that verifies their address.
+func SendVerificationEmail(user *models.User, db *sql.DB, cfg *config.Config) error {
+	token, err := issueUserToken(user.ID, models.UserTokenEmailVerification, emailVerificationTTL, db)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/api/v1/auth/verify-email?token=%s", cfg.App.PublicURL, url.QueryEscape(token))
+
+	sendMail(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in %d hours.\n\n%s\n\nIf you did not create a Finance Tracker account you can ignore this email.\n",
+			user.Name, int(emailVerificationTTL.Hours()), link),
+	}, cfg)
+
+	return nil
+}
+
+// ResendVerificationEmail sends a new verification link. Unknown and already verified addresses
+// are ignored so that the endpoint does not reveal which emails are registered.
+func ResendVerificationEmail(email string, db *sql.DB, cfg *config.Config) error {
+	user, err := repository.GetUserByEmail(email, db)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	return SendVerificationEmail(user, db, cfg)
+}
+
+// VerifyEmail marks the email address of the token's user as verified.
+func VerifyEmail(token string, db *sql.DB) error {
+	var userID uuid.UUID
+
+	err := utils.DBTransaction(db, func(tx *sql.Tx) error {
+		userToken, err := consumeUserToken(token, models.UserTokenEmailVerification, tx)
+		if err != nil {
+			return err
+		}
+		userID = userToken.UserID
+
+		return repository.SetUserEmailVerified(userToken.UserID, time.Now().In(utils.LOC), tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Log the verification
+	go CreateLog(userID, "Email address verified", db)
+
+	return nil
+}
+
+// ForgotPassword emails a password reset link. Like ResendVerificationEmail it succeeds for
+// unknown addresses. Accounts created through Google can use it to set a first password.
+func ForgotPassword(email string, db *sql.DB, cfg *config.Config) error {
+	user, err := repository.GetUserByEmail(email, db)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := issueUserToken(user.ID, models.UserTokenPasswordReset, passwordResetTTL, db)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", cfg.App.ClientURL, url.QueryEscape(token))
+
+	sendMail(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one. It expires in %d minutes and can only be used once.\n\n%s\n\nIf you did not ask for a reset you can ignore this email, your password has not changed.\n",
+			user.Name, int(passwordResetTTL.Minutes()), link),
+	}, cfg)
+
+	// Log the request
+	go CreateLog(user.ID, "Password reset requested", db)
+
+	return nil
+}
+
+// ResetPassword sets a new password for the token's user, signs out all of their sessions and
+// lifts any login lockout. Receiving the email proves ownership of the address, so it is marked
+// verified as well.
+func ResetPassword(token string, newPassword string, db *sql.DB) error {
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	var userID uuid.UUID
+	var email string
+
+	err = utils.DBTransaction(db, func(tx *sql.Tx) error {
+		userToken, err := consumeUserToken(token, models.UserTokenPasswordReset, tx)
+		if err != nil {
+			return err
+		}
+		userID = userToken.UserID
+
+		user, err := repository.GetUserByID(userToken.UserID, tx)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return ErrInvalidUserToken
+		}
+
+		email = user.Email
+
+		user.Password = hashedPassword
+		if err := repository.UpdateUser(user, tx); err != nil {
+			return err
+		}
+
+		now := time.Now().In(utils.LOC)
+
+		if !user.IsEmailVerified() {
+			if err := repository.SetUserEmailVerified(user.ID, now, tx); err != nil {
+				return err
+			}
+		}
+
+		return repository.RevokeSessionsByUserID(user.ID, uuid.NullUUID{}, now, tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	// A reset proves the user owns the account, so it also lifts a lockout after failed logins.
+	clearLoginFailures(email, db)
+
+	// Log the reset
+	go CreateLog(userID, "Password reset", db)
+
+	return nil
+}
+
+// CleanupUserTokens deletes verification and reset tokens that expired more than a week ago.
+func CleanupUserTokens(db *sql.DB) {
+	deleted, err := repository.DeleteStaleUserTokens(time.Now().In(utils.LOC).AddDate(0, 0, -7), db)
+	if err != nil {
+		log.Println("Error deleting stale user tokens:", err)
+		return
+	}
+
+	log.Printf("Deleted %d stale user tokens", deleted)
+}
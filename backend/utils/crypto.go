@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+
+	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
+)
+
+var ErrInvalidCiphertext = errors.New("ciphertext is invalid")
+
+// encryptionKey derives the AES-256 key for secrets stored in the database from ENCRYPTION_KEY,
+// falling back to the JWT secret when it is not set.
+func encryptionKey(cfg *config.Config) []byte {
+	secret := cfg.Security.EncryptionKey
+	if secret == "" {
+		secret = cfg.JWT.JWTSecret
+	}
+
+	key := sha256.Sum256([]byte("encryption:" + secret))
+	return key[:]
+}
+
+func newGCM(cfg *config.Config) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(encryptionKey(cfg))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt seals a value with AES-GCM and returns the nonce and ciphertext in base64.
+func Encrypt(plaintext string, cfg *config.Config) (string, error) {
+	gcm, err := newGCM(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value sealed by Encrypt.
+func Decrypt(ciphertext string, cfg *config.Config) (string, error) {
+	gcm, err := newGCM(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", ErrInvalidCiphertext
+	}
+
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+
+	return string(plaintext), nil
+}
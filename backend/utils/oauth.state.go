@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
+)
+
+var ErrInvalidOAuthState = errors.New("oauth state is invalid or expired")
+
+// oauthStateMAC signs a state payload. The key is derived from the JWT secret with a fixed label
+// so that a state can never be mistaken for any other token signed by the app.
+func oauthStateMAC(payload string, cfg *config.Config) []byte {
+	key := hmac.New(sha256.New, []byte(cfg.JWT.JWTSecret))
+	key.Write([]byte("oauth-state"))
+
+	mac := hmac.New(sha256.New, key.Sum(nil))
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// SignOAuthState returns the state parameter for an OAuth redirect: the nonce and expiry followed
+// by an HMAC over both.
+func SignOAuthState(nonce string, expiresAt time.Time, cfg *config.Config) string {
+	payload := nonce + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(oauthStateMAC(payload, cfg))
+}
+
+// VerifyOAuthState checks the signature and expiry of a state parameter and returns its nonce.
+func VerifyOAuthState(state string, now time.Time, cfg *config.Config) (string, error) {
+	parts := strings.Split(state, ".")
+	if len(parts) != 3 {
+		return "", ErrInvalidOAuthState
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, oauthStateMAC(parts[0]+"."+parts[1], cfg)) {
+		return "", ErrInvalidOAuthState
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() >= expiresAt {
+		return "", ErrInvalidOAuthState
+	}
+
+	return parts[0], nil
+}
+
+// OAuthStateBinding returns the value of the cookie that ties a state to the browser that started
+// the flow. Only the hash of the nonce is handed out, the nonce itself travels in the state.
+func OAuthStateBinding(nonce string) string {
+	return HashToken("oauth-binding:" + nonce)
+}
+
+// VerifyOAuthStateBinding reports whether the cookie sent to the callback belongs to the nonce.
+func VerifyOAuthStateBinding(nonce string, binding string) bool {
+	return binding != "" && subtle.ConstantTimeCompare([]byte(OAuthStateBinding(nonce)), []byte(binding)) == 1
+}
@@ -115,6 +115,74 @@ func NotFound(c *fiber.Ctx, err error, message string) error {
 	})
 }
 
+// Forbidden sends a 403 Forbidden response.
+// It takes the Fiber context, an error, and a message as input.
+//
+// @param c *fiber.Ctx - The Fiber context.
+// @param err error - The error that occurred.
+// @param message string - A message to be included in the response.
+// @return error - An error if one occurred while sending the response.
+func Forbidden(c *fiber.Ctx, err error, message string) error {
+	// This checks if a custom message is provided.
+	if message == "" {
+		// If no message is provided, a default message is used.
+		message = "Forbidden"
+	}
+
+	var errMessage string
+
+	if err != nil {
+		errMessage = err.Error()
+	} else {
+		errMessage = ""
+	}
+
+	// c.Status() sets the HTTP status code of the response.
+	// c.JSON() sends a JSON response.
+	return c.Status(fiber.StatusForbidden).JSON(response{
+		// Success is set to false to indicate that the request was not successful.
+		Success: false,
+		// The message is included in the response.
+		Message: message,
+		// The error message is included in the response.
+		Error: errMessage,
+	})
+}
+
+// Conflict sends a 409 Conflict response.
+// It takes the Fiber context, an error, and a message as input.
+//
+// @param c *fiber.Ctx - The Fiber context.
+// @param err error - The error that occurred.
+// @param message string - A message to be included in the response.
+// @return error - An error if one occurred while sending the response.
+func Conflict(c *fiber.Ctx, err error, message string) error {
+	// This checks if a custom message is provided.
+	if message == "" {
+		// If no message is provided, a default message is used.
+		message = "Conflict"
+	}
+
+	var errMessage string
+
+	if err != nil {
+		errMessage = err.Error()
+	} else {
+		errMessage = ""
+	}
+
+	// c.Status() sets the HTTP status code of the response.
+	// c.JSON() sends a JSON response.
+	return c.Status(fiber.StatusConflict).JSON(response{
+		// Success is set to false to indicate that the request was not successful.
+		Success: false,
+		// The message is included in the response.
+		Message: message,
+		// The error message is included in the response.
+		Error: errMessage,
+	})
+}
+
 // BadResponse sends a 400 Bad Request response.
 // It takes the Fiber context and a message as input.
 //
@@ -5,12 +5,15 @@ import (
 	"time"
 )
 
+// LOC is the server's default time zone, used for timestamps and for users that did not choose
+// a time zone of their own.
 var LOC *time.Location
 
-func LoadTimezone() {
+func LoadTimezone(name string) {
 	var err error
-	LOC, err = time.LoadLocation("Asia/Kolkata")
+	LOC, err = time.LoadLocation(name)
 	if err != nil {
-		log.Printf("Failed to load timezone: %v", err)
+		log.Printf("Failed to load timezone %s, using UTC: %v", name, err)
+		LOC = time.UTC
 	}
 }
@@ -1,6 +1,11 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"time"
 
 	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
@@ -8,7 +13,17 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 )
 
-func GenerateToken(userID string, cfg *config.Config) (string, time.Time, error) {
+// Token types carried in the "typ" claim, so that a token issued for one purpose is never
+// accepted for another.
+const (
+	AccessTokenType             = "access"
+	TwoFactorChallengeTokenType = "2fa_challenge"
+)
+
+var ErrInvalidChallengeToken = errors.New("challenge token is invalid or expired")
+
+// GenerateToken signs a short-lived access token for a session.
+func GenerateToken(userID string, sessionID string, cfg *config.Config) (string, time.Time, error) {
 	secret := cfg.JWT.JWTSecret
 	expiresIn := cfg.JWT.JWTExpiresIn
 
@@ -19,8 +34,10 @@ func GenerateToken(userID string, cfg *config.Config) (string, time.Time, error)
 
 	expiresAt := time.Now().In(LOC).Add(expirationTime)
 	claims := jwt.MapClaims{
-		"user_id": userID,
-		"exp":     expiresAt.Unix(),
+		"typ":        AccessTokenType,
+		"user_id":    userID,
+		"session_id": sessionID,
+		"exp":        expiresAt.Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -32,3 +49,72 @@ func GenerateToken(userID string, cfg *config.Config) (string, time.Time, error)
 
 	return tokenString, expiresAt, nil
 }
+
+// GenerateOpaqueToken returns a random URL-safe token with 256 bits of entropy.
+func GenerateOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// GenerateRefreshToken returns a new refresh token and the time it expires.
+func GenerateRefreshToken(cfg *config.Config) (string, time.Time, error) {
+	expirationTime, err := time.ParseDuration(cfg.JWT.JWTRefreshExpiresIn)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	token, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, time.Now().In(LOC).Add(expirationTime), nil
+}
+
+// HashToken returns the hex encoded SHA-256 hash under which an opaque token is stored.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateChallengeToken signs the token a client exchanges, together with a two-factor code, for
+// a session once the password has been checked.
+func GenerateChallengeToken(userID string, challengeID string, expiresAt time.Time, cfg *config.Config) (string, error) {
+	claims := jwt.MapClaims{
+		"typ":          TwoFactorChallengeTokenType,
+		"user_id":      userID,
+		"challenge_id": challengeID,
+		"exp":          expiresAt.Unix(),
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.JWT.JWTSecret))
+}
+
+// ParseChallengeToken verifies a challenge token and returns the user and challenge it was issued for.
+func ParseChallengeToken(tokenString string, cfg *config.Config) (string, string, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidChallengeToken
+		}
+		return []byte(cfg.JWT.JWTSecret), nil
+	})
+	if err != nil {
+		return "", "", ErrInvalidChallengeToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid || claims["typ"] != TwoFactorChallengeTokenType {
+		return "", "", ErrInvalidChallengeToken
+	}
+
+	userID, _ := claims["user_id"].(string)
+	challengeID, _ := claims["challenge_id"].(string)
+	if userID == "" || challengeID == "" {
+		return "", "", ErrInvalidChallengeToken
+	}
+
+	return userID, challengeID, nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -10,6 +11,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/pkg/oauthstub"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/pkg/scheduler"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/routes"
 	"github.com/rahulcodepython/finance-tracker-backend/backend/utils"
@@ -18,13 +20,34 @@ import (
 func main() {
 	cfg := config.LoadConfig()
 
-	db := database.Connect(cfg)
+	utils.LoadTimezone(cfg.App.Timezone)
+
+	// `main migrate ...` manages the schema instead of starting the server.
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrateCommand(os.Args[2:], cfg)
+		return
+	}
 
-	utils.LoadTimezone()
+	db := database.Connect(cfg)
 
 	database.Migrate(db)
 
-	scheduler.StartScheduler(db)
+	scheduler.StartScheduler(db, cfg)
+
+	// The stub OAuth provider stands in for Google during local development.
+	if cfg.OAuth.StubAddress != "" {
+		go func() {
+			stub := oauthstub.New(oauthstub.Identity{
+				Subject:       "stub-user",
+				Email:         "stub.user@example.com",
+				EmailVerified: true,
+				Name:          "Stub User",
+			})
+			if err := http.ListenAndServe(cfg.OAuth.StubAddress, stub); err != nil {
+				log.Printf("OAuth stub error: %v", err)
+			}
+		}()
+	}
 
 	server := fiber.New(fiber.Config{
 		AppName:       "Finance Tracker",
@@ -43,7 +66,7 @@ func main() {
 
 	// router.Router() is called to set up all the application routes and middleware.
 	// It takes the Fiber server, configuration, and database connection as arguments.
-	routes.Setup(server)
+	routes.Setup(server, cfg)
 
 	// address is a string that represents the server address.
 	// It is constructed by combining the server host and port from the configuration.
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/rahulcodepython/finance-tracker-backend/backend/config"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/database"
+	"github.com/rahulcodepython/finance-tracker-backend/backend/pkg/migrate"
+)
+
+const migrateUsage = `Usage:
+  main migrate up              apply all pending migrations
+  main migrate down [N]        roll back the last N migrations (default 1)
+  main migrate status          list migrations and when they were applied
+  main migrate create <name>   add empty up and down files for a new migration`
+
+// runMigrateCommand handles the migrate subcommands instead of starting the server.
+func runMigrateCommand(args []string, cfg *config.Config) {
+	if len(args) == 0 {
+		migrateUsageExit()
+	}
+
+	switch args[0] {
+	case "create":
+		if len(args) != 2 {
+			migrateUsageExit()
+		}
+
+		migration, err := migrate.Create(database.MigrationsDir, args[1])
+		if err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+
+		fmt.Println("Created", migration.UpPath)
+		fmt.Println("Created", migration.DownPath)
+		return
+	case "up", "status":
+		if len(args) != 1 {
+			migrateUsageExit()
+		}
+	case "down":
+		if len(args) > 2 {
+			migrateUsageExit()
+		}
+	default:
+		migrateUsageExit()
+	}
+
+	db := database.Connect(cfg)
+	defer db.Close()
+
+	migrator := migrate.New(db, database.MigrationsDir)
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			fmt.Println("Applied", migration)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+	case "down":
+		n := 1
+		if len(args) == 2 {
+			var err error
+			if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+				migrateUsageExit()
+			}
+		}
+
+		reverted, err := migrator.Down(n)
+		for _, migration := range reverted {
+			fmt.Println("Rolled back", migration)
+		}
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("No applied migrations")
+		}
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			if status.Missing {
+				state += " (file missing)"
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, state)
+		}
+		w.Flush()
+	}
+}
+
+func migrateUsageExit() {
+	fmt.Fprintln(os.Stderr, migrateUsage)
+	os.Exit(2)
+}
@@ -0,0 +1,33 @@
+DROP INDEX IF EXISTS idx_accounts_user_id;
+DROP INDEX IF EXISTS idx_transactions_user_id_date;
+DROP INDEX IF EXISTS idx_transactions_destination_account_id;
+DROP INDEX IF EXISTS idx_transactions_budget_id_date;
+DROP TABLE IF EXISTS user_preferences;
+DROP TABLE IF EXISTS budget_alerts;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS exchange_rates;
+DROP TABLE IF EXISTS recurring_transaction_exceptions;
+DROP TABLE IF EXISTS recurring_transaction_runs;
+DROP TABLE IF EXISTS recurring_transactions;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS budgets;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS accounts;
+DROP TABLE IF EXISTS rate_limit_buckets;
+DROP TABLE IF EXISTS login_attempts;
+DROP TABLE IF EXISTS two_factor_challenges;
+DROP TABLE IF EXISTS two_factor_recovery_codes;
+DROP TABLE IF EXISTS user_two_factor;
+DROP TABLE IF EXISTS user_tokens;
+DROP TABLE IF EXISTS oauth_states;
+DROP TABLE IF EXISTS user_identities;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS logs;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS jwt_tokens;
+DROP TYPE IF EXISTS budget_period;
+DROP TYPE IF EXISTS recurring_frequency;
+DROP TYPE IF EXISTS transaction_type;
+DROP TYPE IF EXISTS account_type;
+DROP TYPE IF EXISTS auth_provider;
\ No newline at end of file
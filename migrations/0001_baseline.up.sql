@@ -0,0 +1,375 @@
+-- Baseline schema. Databases created before versioned migrations were built by re-running this
+-- script on every start, so it stays idempotent and brings any earlier schema up to date.
+-- New types get every value up front, older databases receive missing values further down.
+DO $$
+BEGIN
+    CREATE TYPE account_type AS ENUM ('checking', 'savings', 'credit_card', 'cash', 'investment', 'loan', 'upi');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+DO $$
+BEGIN
+    CREATE TYPE transaction_type AS ENUM ('income', 'expense', 'transfer');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+DO $$
+BEGIN
+    CREATE TYPE auth_provider AS ENUM ('email', 'google');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+DO $$
+BEGIN
+    CREATE TYPE recurring_frequency AS ENUM ('daily', 'weekly', 'biweekly', 'monthly', 'quarterly', 'yearly');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    email VARCHAR(255) UNIQUE NOT NULL,
+    password VARCHAR(255) NULL,
+    provider auth_provider NOT NULL DEFAULT 'email',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS accounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    type account_type NOT NULL,
+    balance NUMERIC(19, 4) NOT NULL DEFAULT 0.00,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS categories (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    type transaction_type NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS budgets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    amount NUMERIC(19, 4) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    category_id UUID REFERENCES categories(id) ON DELETE RESTRICT,
+    budget_id UUID REFERENCES budgets(id) ON DELETE SET NULL,
+    description VARCHAR(255) NOT NULL,
+    amount NUMERIC(19, 4) NOT NULL,
+    type transaction_type NOT NULL,
+    transaction_date DATE NOT NULL,
+    note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS recurring_transactions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    category_id UUID REFERENCES categories(id) ON DELETE RESTRICT,
+    budget_id UUID REFERENCES budgets(id) ON DELETE SET NULL,
+    description VARCHAR(255) NOT NULL,
+    amount NUMERIC(19, 4) NOT NULL,
+    type transaction_type NOT NULL,
+    note TEXT,
+    recurring_frequency recurring_frequency NOT NULL,
+    recurring_date INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id_date ON transactions (user_id, transaction_date DESC);
+CREATE INDEX IF NOT EXISTS idx_accounts_user_id ON accounts (user_id);
+
+CREATE TABLE IF NOT EXISTS logs (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    message TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Categories without a user_id are shared system defaults, the rest are per-user custom categories.
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS user_id UUID REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_name_type_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_system_name_type ON categories (name, type) WHERE user_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_user_id_name_type ON categories (user_id, name, type) WHERE user_id IS NOT NULL;
+
+INSERT INTO categories (name, type) VALUES
+    ('Salary', 'income'),
+    ('Business', 'income'),
+    ('Freelance', 'income'),
+    ('Investments', 'income'),
+    ('Interest', 'income'),
+    ('Gifts', 'income'),
+    ('Other Income', 'income'),
+    ('Food & Dining', 'expense'),
+    ('Groceries', 'expense'),
+    ('Rent', 'expense'),
+    ('Utilities', 'expense'),
+    ('Transportation', 'expense'),
+    ('Shopping', 'expense'),
+    ('Entertainment', 'expense'),
+    ('Healthcare', 'expense'),
+    ('Education', 'expense'),
+    ('Travel', 'expense'),
+    ('Insurance', 'expense'),
+    ('Bills & EMI', 'expense'),
+    ('Other Expense', 'expense')
+ON CONFLICT (name, type) WHERE user_id IS NULL DO NOTHING;
+
+-- Multi-currency support. Existing rows keep the previous implicit currency.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS base_currency CHAR(3) NOT NULL DEFAULT 'INR';
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'INR';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'INR';
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate NUMERIC(19, 8) NOT NULL CHECK (rate > 0),
+    rate_date DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (base_currency, quote_currency, rate_date)
+);
+
+-- Transfers move money between two of the user's accounts. They carry no category and are
+-- excluded from income and expense totals. The check compares the type as text since a value
+-- added in this transaction cannot be used as an enum until it commits.
+ALTER TYPE transaction_type ADD VALUE IF NOT EXISTS 'transfer';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS destination_account_id UUID REFERENCES accounts(id) ON DELETE CASCADE;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS destination_amount NUMERIC(19, 4);
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transfer_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transfer_check CHECK ((type::TEXT = 'transfer') = (destination_account_id IS NOT NULL AND destination_amount IS NOT NULL));
+CREATE INDEX IF NOT EXISTS idx_transactions_destination_account_id ON transactions (destination_account_id) WHERE destination_account_id IS NOT NULL;
+
+-- Period based budgets. The amount column holds the limit for each period and spending is derived
+-- from transactions. Budgets created before periods existed had every linked transaction deducted
+-- from amount, so the original limit is restored once while converting them to monthly budgets.
+DO $$
+BEGIN
+    CREATE TYPE budget_period AS ENUM ('weekly', 'monthly', 'quarterly', 'yearly', 'custom');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+ALTER TABLE budgets ADD COLUMN IF NOT EXISTS period budget_period;
+ALTER TABLE budgets ADD COLUMN IF NOT EXISTS start_date DATE;
+ALTER TABLE budgets ADD COLUMN IF NOT EXISTS end_date DATE;
+ALTER TABLE budgets ADD COLUMN IF NOT EXISTS category_id UUID REFERENCES categories(id) ON DELETE RESTRICT;
+ALTER TABLE budgets ADD COLUMN IF NOT EXISTS rollover BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE budgets b SET
+    amount = b.amount + COALESCE((SELECT SUM(t.amount) FROM transactions t WHERE t.budget_id = b.id), 0),
+    period = 'monthly',
+    start_date = b.created_at::DATE
+WHERE b.period IS NULL;
+ALTER TABLE budgets ALTER COLUMN period SET DEFAULT 'monthly';
+ALTER TABLE budgets ALTER COLUMN period SET NOT NULL;
+ALTER TABLE budgets ALTER COLUMN start_date SET DEFAULT CURRENT_DATE;
+ALTER TABLE budgets ALTER COLUMN start_date SET NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_transactions_budget_id_date ON transactions (budget_id, transaction_date) WHERE budget_id IS NOT NULL;
+
+-- Budget threshold alerts and the notification inbox.
+ALTER TABLE budgets ADD COLUMN IF NOT EXISTS alert_thresholds INTEGER[] NOT NULL DEFAULT '{50,80,100}';
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL,
+    reference_id UUID,
+    is_read BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    read_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id_created_at ON notifications (user_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS budget_alerts (
+    budget_id UUID NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+    period_start DATE NOT NULL,
+    threshold INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (budget_id, period_start, threshold)
+);
+
+-- Richer recurrence rules for recurring transactions.
+ALTER TYPE recurring_frequency ADD VALUE IF NOT EXISTS 'daily';
+ALTER TYPE recurring_frequency ADD VALUE IF NOT EXISTS 'weekly';
+ALTER TYPE recurring_frequency ADD VALUE IF NOT EXISTS 'biweekly';
+ALTER TYPE recurring_frequency ADD VALUE IF NOT EXISTS 'quarterly';
+ALTER TABLE recurring_transactions ADD COLUMN IF NOT EXISTS recurrence_interval INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE recurring_transactions ADD COLUMN IF NOT EXISTS weekday SMALLINT;
+ALTER TABLE recurring_transactions ADD COLUMN IF NOT EXISTS last_day_of_month BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE recurring_transactions ADD COLUMN IF NOT EXISTS start_date DATE;
+ALTER TABLE recurring_transactions ADD COLUMN IF NOT EXISTS end_date DATE;
+ALTER TABLE recurring_transactions ADD COLUMN IF NOT EXISTS max_occurrences INTEGER;
+UPDATE recurring_transactions SET start_date = created_at::DATE WHERE start_date IS NULL;
+ALTER TABLE recurring_transactions ALTER COLUMN start_date SET DEFAULT CURRENT_DATE;
+ALTER TABLE recurring_transactions ALTER COLUMN start_date SET NOT NULL;
+
+-- Idempotent recurring transaction processing. Existing rules were already handled by the old
+-- daily job, so they start from yesterday instead of posting their whole history again.
+ALTER TABLE recurring_transactions ADD COLUMN IF NOT EXISTS last_run_at DATE DEFAULT (CURRENT_DATE - 1);
+ALTER TABLE recurring_transactions ALTER COLUMN last_run_at DROP DEFAULT;
+
+CREATE TABLE IF NOT EXISTS recurring_transaction_runs (
+    recurring_transaction_id UUID NOT NULL REFERENCES recurring_transactions(id) ON DELETE CASCADE,
+    occurrence_date DATE NOT NULL,
+    occurrence_index INTEGER NOT NULL,
+    transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (recurring_transaction_id, occurrence_date)
+);
+
+-- Pause, skip and amount overrides for recurring transactions.
+ALTER TABLE recurring_transactions ADD COLUMN IF NOT EXISTS is_paused BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS recurring_transaction_exceptions (
+    recurring_transaction_id UUID NOT NULL REFERENCES recurring_transactions(id) ON DELETE CASCADE,
+    occurrence_date DATE NOT NULL,
+    skip BOOLEAN NOT NULL DEFAULT FALSE,
+    amount NUMERIC(19, 4),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (recurring_transaction_id, occurrence_date),
+    CONSTRAINT recurring_transaction_exceptions_action_check CHECK (skip OR amount IS NOT NULL)
+);
+
+-- Multi-device sessions with rotating refresh tokens replace the single jwt_tokens row per user.
+CREATE TABLE IF NOT EXISTS sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens (session_id);
+
+DROP TABLE IF EXISTS jwt_tokens;
+
+-- Google logins are linked through their stable subject instead of matching on email.
+CREATE TABLE IF NOT EXISTS user_identities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider auth_provider NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, subject),
+    UNIQUE (user_id, provider)
+);
+
+CREATE TABLE IF NOT EXISTS oauth_states (
+    nonce VARCHAR(64) PRIMARY KEY,
+    code_verifier VARCHAR(128) NOT NULL,
+    purpose VARCHAR(16) NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Email verification and password reset. Accounts that existed before verification was
+-- introduced are treated as verified when the column is added.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ DEFAULT NOW();
+ALTER TABLE users ALTER COLUMN email_verified_at DROP DEFAULT;
+
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(32) NOT NULL,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_id_purpose ON user_tokens (user_id, purpose);
+
+-- TOTP two-factor authentication.
+CREATE TABLE IF NOT EXISTS user_two_factor (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    enabled_at TIMESTAMPTZ,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS two_factor_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, code_hash)
+);
+
+CREATE TABLE IF NOT EXISTS two_factor_challenges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Failed login counters for brute-force protection, per email address and per client IP.
+CREATE TABLE IF NOT EXISTS login_attempts (
+    scope VARCHAR(16) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    blocked_until TIMESTAMPTZ,
+    PRIMARY KEY (scope, key)
+);
+
+-- Token buckets for the API rate limiter when RATE_LIMITER_STORE is postgres. The data is
+-- disposable, so the table skips the write-ahead log.
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_buckets (
+    key VARCHAR(255) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_expires_at ON rate_limit_buckets(expires_at);
+
+-- Set when a user asks to delete their account, the account is removed once this time passes.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMPTZ;
+
+-- Display settings and defaults. Users without a row use the server defaults, and the base
+-- currency stays on the users table.
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    timezone VARCHAR(64) NOT NULL,
+    locale VARCHAR(35) NOT NULL,
+    date_format VARCHAR(16) NOT NULL,
+    week_start SMALLINT NOT NULL CHECK (week_start BETWEEN 0 AND 6),
+    default_account_id UUID REFERENCES accounts(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,24 @@
+-- Saved column mappings for CSV transaction imports. Columns are named as in the file's header
+-- row and empty names are unused.
+CREATE TABLE import_profiles (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    delimiter VARCHAR(1) NOT NULL,
+    skip_rows INT NOT NULL DEFAULT 0 CHECK (skip_rows >= 0),
+    date_column VARCHAR(100) NOT NULL,
+    date_format VARCHAR(16) NOT NULL,
+    description_column VARCHAR(100) NOT NULL,
+    amount_column VARCHAR(100) NOT NULL DEFAULT '',
+    debit_column VARCHAR(100) NOT NULL DEFAULT '',
+    credit_column VARCHAR(100) NOT NULL DEFAULT '',
+    amount_sign VARCHAR(16) NOT NULL,
+    decimal_separator VARCHAR(1) NOT NULL,
+    note_column VARCHAR(100) NOT NULL DEFAULT '',
+    category_column VARCHAR(100) NOT NULL DEFAULT '',
+    income_category_id UUID REFERENCES categories(id) ON DELETE SET NULL,
+    expense_category_id UUID REFERENCES categories(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, name)
+);
@@ -0,0 +1,5 @@
+-- The bank's identifier of an imported transaction, the FITID of OFX files. Identifiers are only
+-- unique within an account, and importing the same one twice into an account is skipped.
+ALTER TABLE transactions ADD COLUMN external_id VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_transactions_account_id_external_id ON transactions(account_id, external_id) WHERE external_id IS NOT NULL;
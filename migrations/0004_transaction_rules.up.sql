@@ -0,0 +1,22 @@
+-- User-defined rules that fill in the category, budget and note of new and imported transactions.
+-- Empty conditions are not checked. Rules are tried by descending priority.
+CREATE TABLE transaction_rules (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    priority INT NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    description_contains VARCHAR(255) NOT NULL DEFAULT '',
+    description_pattern VARCHAR(255) NOT NULL DEFAULT '',
+    min_amount NUMERIC(19,4) CHECK (min_amount >= 0),
+    max_amount NUMERIC(19,4) CHECK (max_amount >= 0),
+    account_id UUID REFERENCES accounts(id) ON DELETE CASCADE,
+    note_contains VARCHAR(255) NOT NULL DEFAULT '',
+    category_id UUID REFERENCES categories(id) ON DELETE SET NULL,
+    budget_id UUID REFERENCES budgets(id) ON DELETE SET NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_transaction_rules_user_id_priority ON transaction_rules(user_id, priority DESC);
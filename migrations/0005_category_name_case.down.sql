@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_categories_system_name_type;
+DROP INDEX IF EXISTS idx_categories_user_id_name_type;
+CREATE UNIQUE INDEX idx_categories_system_name_type ON categories (name, type) WHERE user_id IS NULL;
+CREATE UNIQUE INDEX idx_categories_user_id_name_type ON categories (user_id, name, type) WHERE user_id IS NOT NULL;
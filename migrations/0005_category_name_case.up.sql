@@ -0,0 +1,16 @@
+-- Category names are unique per user and type regardless of case, the same way they are checked
+-- before a category is created or renamed. Names that already differ only in case keep the first
+-- one and number the others, e.g. "food (2)".
+WITH duplicates AS (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, LOWER(name), type ORDER BY name, id) AS position
+    FROM categories
+)
+UPDATE categories
+SET name = LEFT(categories.name, 90) || ' (' || duplicates.position || ')'
+FROM duplicates
+WHERE categories.id = duplicates.id AND duplicates.position > 1;
+
+DROP INDEX IF EXISTS idx_categories_system_name_type;
+DROP INDEX IF EXISTS idx_categories_user_id_name_type;
+CREATE UNIQUE INDEX idx_categories_system_name_type ON categories (LOWER(name), type) WHERE user_id IS NULL;
+CREATE UNIQUE INDEX idx_categories_user_id_name_type ON categories (user_id, LOWER(name), type) WHERE user_id IS NOT NULL;
@@ -1,13 +0,0 @@
-DROP INDEX IF EXISTS idx_accounts_user_id;
-DROP INDEX IF EXISTS idx_transactions_user_id_date;
-DROP TABLE IF EXISTS recurring_transactions;
-DROP TABLE IF EXISTS transactions;
-DROP TABLE IF EXISTS budgets;
-DROP TABLE IF EXISTS categories;
-DROP TABLE IF EXISTS accounts;
-DROP TABLE IF EXISTS users;
-DROP TABLE IF EXISTS jwt_tokens;
-DROP TYPE IF EXISTS recurring_frequency;
-DROP TYPE IF EXISTS transaction_type;
-DROP TYPE IF EXISTS account_type;
-DROP TYPE IF EXISTS auth_provider;
\ No newline at end of file
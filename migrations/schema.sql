@@ -1,90 +0,0 @@
-CREATE TYPE account_type AS ENUM ('checking', 'savings', 'credit_card', 'cash', 'investment', 'loan', 'upi');
-CREATE TYPE transaction_type AS ENUM ('income', 'expense');
-CREATE TYPE auth_provider AS ENUM ('email', 'google');
-CREATE TYPE recurring_frequency AS ENUM ('monthly', 'yearly');
-
-CREATE TABLE IF NOT EXISTS users (
-    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    name VARCHAR(100) NOT NULL,
-    email VARCHAR(255) UNIQUE NOT NULL,
-    password VARCHAR(255) NULL,
-    provider auth_provider NOT NULL DEFAULT 'email',
-    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
-);
-
-CREATE TABLE IF NOT EXISTS accounts (
-    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-    name VARCHAR(100) NOT NULL,
-    type account_type NOT NULL,
-    balance NUMERIC(19, 4) NOT NULL DEFAULT 0.00,
-    is_active BOOLEAN NOT NULL DEFAULT TRUE,
-    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
-);
-
-CREATE TABLE IF NOT EXISTS categories (
-    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    name VARCHAR(100) NOT NULL,
-    type transaction_type NOT NULL,
-    UNIQUE (name, type)
-);
-
-CREATE TABLE IF NOT EXISTS transactions (
-    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
-    category_id UUID REFERENCES categories(id) ON DELETE RESTRICT,
-    budget_id UUID REFERENCES budgets(id) ON DELETE SET NULL,
-    description VARCHAR(255) NOT NULL,
-    amount NUMERIC(19, 4) NOT NULL,
-    type transaction_type NOT NULL,
-    transaction_date DATE NOT NULL,
-    note TEXT,
-    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
-);
-
-CREATE TABLE IF NOT EXISTS recurring_transactions (
-    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
-    category_id UUID REFERENCES categories(id) ON DELETE RESTRICT,
-    budget_id UUID REFERENCES budgets(id) ON DELETE SET NULL,
-    description VARCHAR(255) NOT NULL,
-    amount NUMERIC(19, 4) NOT NULL,
-    type transaction_type NOT NULL,
-    note TEXT,
-    recurring_frequency recurring_frequency NOT NULL,
-    recurring_date INTEGER NOT NULL,
-    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
-);
-
-CREATE TABLE IF NOT EXISTS budgets (
-    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-    name VARCHAR(100) NOT NULL,
-    amount NUMERIC(19, 4) NOT NULL,
-    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
-);
-
-CREATE INDEX IF NOT EXISTS idx_transactions_user_id_date ON transactions (user_id, transaction_date DESC);
-CREATE INDEX IF NOT EXISTS idx_accounts_user_id ON accounts (user_id);
-
-CREATE TABLE IF NOT EXISTS jwt_tokens (
-    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-    token TEXT UNIQUE NOT NULL,
-    expires_at TIMESTAMPTZ NOT NULL,
-    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    UNIQUE (user_id)
-);
-
-CREATE TABLE IF NOT EXISTS logs (
-    id UUID PRIMARY KEY,
-    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-    message TEXT NOT NULL,
-    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
-);
\ No newline at end of file